@@ -0,0 +1,58 @@
+package gojsonp
+
+import (
+	"github.com/onerciller/gojsonp/parser"
+	"github.com/onerciller/gojsonp/token"
+	"io"
+)
+
+// Decoder reads a sequence of JSON values from an input stream.
+// Values may be concatenated, separated by whitespace or one per line (NDJSON).
+// Example:
+//
+//	decoder := NewDecoder(os.Stdin)
+//	for decoder.More() {
+//		value, err := decoder.Decode()
+//		...
+//	}
+type Decoder struct {
+	lexer   *token.Lexer
+	parser  *parser.Parser
+	options decodeOptions
+
+	// offset is the input offset just past the last decoded value
+	offset int
+}
+
+// NewDecoder creates a Decoder reading from r.
+// The Decoder reads r incrementally, so it may read data from r beyond the values requested.
+// The Limits apply to each value rather than the whole stream: MaxInputBytes and MaxTokens are counted again
+// from the end of each decoded value, so a long-running stream of small values is not cut off.
+func NewDecoder(r io.Reader, opts ...Option) *Decoder {
+	options := newDecodeOptions(opts)
+	lexer := token.NewLexer(r, options.token...)
+	return &Decoder{lexer: lexer, parser: parser.NewStreamParser(lexer, options.parser...), options: options}
+}
+
+// More reports whether another value follows in the input.
+// It returns false once Decode returned an error, as malformed input ends the sequence of values.
+func (d *Decoder) More() bool {
+	return d.parser.More()
+}
+
+// Decode reads the next value from the input and converts it to Go values, like Decode.
+// It returns io.EOF once the input holds no more values, and after an error the same error on every call.
+func (d *Decoder) Decode() (interface{}, error) {
+	node, err := d.parser.ParseNext()
+	if err != nil {
+		return nil, err
+	}
+	d.offset = node.End.Offset
+	d.lexer.ResetLimits()
+	return parser.NodeToValue(node, d.options.parser...), nil
+}
+
+// InputOffset returns the input offset just past the last decoded value.
+func (d *Decoder) InputOffset() int {
+	return d.offset
+}
@@ -0,0 +1,139 @@
+package gojsonp
+
+import (
+	"errors"
+	"github.com/onerciller/gojsonp/token"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// TestDecoder tests the Decoder for the different ways values can follow each other in a stream.
+func TestDecoder(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []interface{}
+		offsets []int
+	}{
+		{
+			name:    "NDJSON",
+			input:   "{\"a\": 1}\n{\"a\": 2}\n",
+			want:    []interface{}{map[string]interface{}{"a": float64(1)}, map[string]interface{}{"a": float64(2)}},
+			offsets: []int{8, 17},
+		},
+		{
+			name:    "Concatenated",
+			input:   `{}[]"s"`,
+			want:    []interface{}{map[string]interface{}{}, []interface{}{}, "s"},
+			offsets: []int{2, 4, 7},
+		},
+		{
+			name:    "Whitespace separated scalars",
+			input:   " 1 true\tnull\r\n-2.5 ",
+			want:    []interface{}{float64(1), true, nil, -2.5},
+			offsets: []int{2, 7, 12, 18},
+		},
+		{
+			name:  "Empty input",
+			input: "  \n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoder := NewDecoder(iotest.OneByteReader(strings.NewReader(tt.input)))
+			var got []interface{}
+			var offsets []int
+			for decoder.More() {
+				value, err := decoder.Decode()
+				if err != nil {
+					t.Fatalf("Decode() error = %v", err)
+				}
+				got = append(got, value)
+				offsets = append(offsets, decoder.InputOffset())
+			}
+			if _, err := decoder.Decode(); err != io.EOF {
+				t.Errorf("Decode() at end error = %v, want io.EOF", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Decode() got = %#v, want %#v", got, tt.want)
+			}
+			if !reflect.DeepEqual(offsets, tt.offsets) {
+				t.Errorf("InputOffset() got = %v, want %v", offsets, tt.offsets)
+			}
+		})
+	}
+}
+
+// TestDecoderSyntaxError tests that values before a malformed one are decoded and the error is then reported.
+func TestDecoderSyntaxError(t *testing.T) {
+	decoder := NewDecoder(strings.NewReader("{\"a\": 1}\n{\"a\" 2}\n{\"a\": 3}"))
+
+	if value, err := decoder.Decode(); err != nil || !reflect.DeepEqual(value, map[string]interface{}{"a": float64(1)}) {
+		t.Fatalf("Decode() got = %v, %v", value, err)
+	}
+	_, err := decoder.Decode()
+	var syntaxErr *SyntaxError
+	if !errors.As(err, &syntaxErr) || !errors.Is(err, token.ErrUnexpectedToken) || syntaxErr.Pos.Line != 2 {
+		t.Errorf("Decode() error = %v, want a syntax error on line 2", err)
+	}
+	if decoder.More() {
+		t.Errorf("More() = true after an error, want false")
+	}
+	if _, again := decoder.Decode(); again != err {
+		t.Errorf("Decode() after an error = %v, want %v again", again, err)
+	}
+}
+
+// TestDecoderLoopEnds tests that the loop of the Decoder example ends on malformed input,
+// whether the value is malformed or the input cannot be read.
+func TestDecoderLoopEnds(t *testing.T) {
+	inputs := []io.Reader{
+		strings.NewReader("{\"a\": 1}\n{\"a\" 2}\n{\"a\": 3}\n"),
+		strings.NewReader("[1, @]\n"),
+		io.MultiReader(strings.NewReader("1 [2,"), iotest.ErrReader(errors.New("connection reset"))),
+	}
+
+	for _, input := range inputs {
+		decoder := NewDecoder(input)
+		errs := 0
+		for calls := 0; decoder.More(); calls++ {
+			if calls > 10 {
+				t.Fatalf("More() keeps returning true after %d errors", errs)
+			}
+			if _, err := decoder.Decode(); err != nil {
+				errs++
+			}
+		}
+		if errs != 1 {
+			t.Errorf("Decode() returned %d errors, want 1", errs)
+		}
+	}
+}
+
+// TestDecoderLimits tests that MaxInputBytes and MaxTokens apply to each value of the stream.
+func TestDecoderLimits(t *testing.T) {
+	input := strings.Repeat("{\"a\": 1}\n", 100)
+	decoder := NewDecoder(strings.NewReader(input), Limits(token.Limits{MaxInputBytes: 9, MaxTokens: 5}))
+	count := 0
+	for decoder.More() {
+		if _, err := decoder.Decode(); err != nil {
+			t.Fatalf("Decode() error = %v", err)
+		}
+		count++
+	}
+	if count != 100 {
+		t.Errorf("Decode() got %d values, want 100", count)
+	}
+
+	decoder = NewDecoder(strings.NewReader("{\"a\": 1}\n{\"a\": [1]}\n"), Limits(token.Limits{MaxTokens: 5}))
+	if _, err := decoder.Decode(); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if _, err := decoder.Decode(); !errors.Is(err, token.ErrMaxTokens) {
+		t.Errorf("Decode() error = %v, want token.ErrMaxTokens", err)
+	}
+}
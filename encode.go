@@ -0,0 +1,505 @@
+package gojsonp
+
+import (
+	"fmt"
+	"github.com/onerciller/gojsonp/parser"
+	"github.com/onerciller/gojsonp/token"
+	"io"
+	"math"
+	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+	"unicode/utf8"
+)
+
+// maxEncodeDepth is the nesting depth after which Marshal assumes the value contains a cycle.
+const maxEncodeDepth = 1000
+
+// UnsupportedTypeError is returned by Marshal for Go types that have no JSON representation,
+// such as channels, functions and complex numbers.
+type UnsupportedTypeError struct {
+	Type reflect.Type
+}
+
+// Error method for UnsupportedTypeError to get a human-readable description of the error.
+func (e *UnsupportedTypeError) Error() string {
+	return "gojsonp: unsupported type: " + e.Type.String()
+}
+
+// UnsupportedValueError is returned by Marshal for values that have no JSON representation,
+// such as NaN, infinities and cyclic data structures.
+type UnsupportedValueError struct {
+	Value reflect.Value
+	Str   string
+}
+
+// Error method for UnsupportedValueError to get a human-readable description of the error.
+func (e *UnsupportedValueError) Error() string {
+	return "gojsonp: unsupported value: " + e.Str
+}
+
+// Marshal function to convert a Go value to JSON.
+// Structs are encoded as objects using their `json` tags, maps as objects with sorted keys,
+// slices and arrays as arrays, and nil pointers, maps, slices and interfaces as null.
+// A Number is written as its literal text, and *big.Int and *big.Float values keep all of their digits.
+// A *parser.AstNode is encoded as the document it represents and a *parser.OrderedMap keeps the order
+// of its keys, so documents decoded with OrderedObjects are written back in their original order.
+// Example: Marshal(map[string]int{"b": 2, "a": 1}) returns `{"a":1,"b":2}`.
+func Marshal(v interface{}) ([]byte, error) {
+	e := &encodeState{}
+	if err := e.encode(reflect.ValueOf(v)); err != nil {
+		return nil, err
+	}
+	return e.buf, nil
+}
+
+// MarshalIndent is like Marshal but puts every object member and array element on its own line,
+// starting with prefix and indented by one copy of indent per nesting level.
+// Example: MarshalIndent(v, "", "  ")
+func MarshalIndent(v interface{}, prefix, indent string) ([]byte, error) {
+	e := &encodeState{prefix: prefix, indent: indent}
+	if err := e.encode(reflect.ValueOf(v)); err != nil {
+		return nil, err
+	}
+	return e.buf, nil
+}
+
+// Encoder writes JSON values to an output stream, one per line.
+type Encoder struct {
+	w      io.Writer
+	prefix string
+	indent string
+}
+
+// NewEncoder creates an Encoder writing to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w}
+}
+
+// SetIndent makes the Encoder format values like MarshalIndent.
+func (enc *Encoder) SetIndent(prefix, indent string) {
+	enc.prefix, enc.indent = prefix, indent
+}
+
+// Encode writes the JSON encoding of v followed by a newline.
+// Nothing is written if v cannot be encoded.
+func (enc *Encoder) Encode(v interface{}) error {
+	e := &encodeState{prefix: enc.prefix, indent: enc.indent}
+	if err := e.encode(reflect.ValueOf(v)); err != nil {
+		return err
+	}
+	e.buf = append(e.buf, '\n')
+	_, err := enc.w.Write(e.buf)
+	return err
+}
+
+// encodeState holds the output of a single Marshal call.
+type encodeState struct {
+	buf    []byte
+	prefix string
+	indent string
+
+	// depth counts the open objects and arrays for indentation
+	depth int
+
+	// level counts nested values, including pointers and interfaces, to detect cycles
+	level int
+}
+
+// astNodeType is the type of *parser.AstNode, which is encoded as the document it represents.
+var astNodeType = reflect.TypeOf((*parser.AstNode)(nil))
+
+// orderedMapType is the type of *parser.OrderedMap, which is encoded with its keys in order.
+var orderedMapType = reflect.TypeOf((*parser.OrderedMap)(nil))
+
+// encode appends the JSON encoding of v.
+func (e *encodeState) encode(v reflect.Value) error {
+	if !v.IsValid() {
+		e.buf = append(e.buf, "null"...)
+		return nil
+	}
+	if v.Type() == astNodeType {
+		if v.IsNil() {
+			e.buf = append(e.buf, "null"...)
+			return nil
+		}
+		return e.encodeNode(v.Interface().(*parser.AstNode))
+	}
+	if v.Type() == orderedMapType {
+		if v.IsNil() {
+			e.buf = append(e.buf, "null"...)
+			return nil
+		}
+		return e.nested(v, func() error { return e.encodeOrderedMap(v.Interface().(*parser.OrderedMap)) })
+	}
+	switch v.Type() {
+	case numberType, bigIntType, bigFloatType:
+		return e.encodeNumber(v)
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		e.buf = strconv.AppendBool(e.buf, v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		e.buf = strconv.AppendInt(e.buf, v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		e.buf = strconv.AppendUint(e.buf, v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return e.encodeFloat(v)
+	case reflect.String:
+		e.buf = appendString(e.buf, v.String())
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			e.buf = append(e.buf, "null"...)
+			return nil
+		}
+		return e.nested(v, func() error { return e.encode(v.Elem()) })
+	case reflect.Struct:
+		return e.nested(v, func() error { return e.encodeStruct(v) })
+	case reflect.Map:
+		if v.IsNil() {
+			e.buf = append(e.buf, "null"...)
+			return nil
+		}
+		return e.nested(v, func() error { return e.encodeMap(v) })
+	case reflect.Slice:
+		if v.IsNil() {
+			e.buf = append(e.buf, "null"...)
+			return nil
+		}
+		return e.nested(v, func() error { return e.encodeArray(v) })
+	case reflect.Array:
+		return e.nested(v, func() error { return e.encodeArray(v) })
+	default:
+		return &UnsupportedTypeError{Type: v.Type()}
+	}
+	return nil
+}
+
+// nested runs encode one nesting level deeper, failing once the value is too deep to be anything but a cycle.
+func (e *encodeState) nested(v reflect.Value, encode func() error) error {
+	if e.level >= maxEncodeDepth {
+		return &UnsupportedValueError{Value: v, Str: fmt.Sprintf("encountered a cycle via %s", v.Type())}
+	}
+	e.level++
+	err := encode()
+	e.level--
+	return err
+}
+
+// encodeNumber appends a Number as written, and a big.Int or big.Float with all of its digits.
+func (e *encodeState) encodeNumber(v reflect.Value) error {
+	var literal string
+	switch number := v.Interface().(type) {
+	case Number:
+		literal = string(number)
+	case big.Int:
+		literal = number.String()
+	case big.Float:
+		literal = number.Text('g', -1)
+	}
+	if !token.IsNumber(literal) {
+		return &UnsupportedValueError{Value: v, Str: strconv.Quote(literal)}
+	}
+	e.buf = append(e.buf, literal...)
+	return nil
+}
+
+// encodeFloat appends a float in the shortest form that parses back to the same value.
+// Very large and very small numbers use an exponent, e.g. 1e+21 and 1e-7.
+func (e *encodeState) encodeFloat(v reflect.Value) error {
+	f := v.Float()
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return &UnsupportedValueError{Value: v, Str: strconv.FormatFloat(f, 'g', -1, 64)}
+	}
+	bits := 64
+	if v.Kind() == reflect.Float32 {
+		bits = 32
+	}
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		format = 'e'
+	}
+	e.buf = strconv.AppendFloat(e.buf, f, format, -1, bits)
+	if format == 'e' {
+		// shorten a two-digit negative exponent: 1e-07 becomes 1e-7
+		n := len(e.buf)
+		if n >= 4 && e.buf[n-4] == 'e' && e.buf[n-3] == '-' && e.buf[n-2] == '0' {
+			e.buf[n-2] = e.buf[n-1]
+			e.buf = e.buf[:n-1]
+		}
+	}
+	return nil
+}
+
+// encodeStruct appends a struct as an object, using the fields computed by cachedFields.
+func (e *encodeState) encodeStruct(v reflect.Value) error {
+	e.open('{')
+	first := true
+	for _, f := range cachedFields(v.Type()) {
+		fv, ok := fieldValue(v, f.index)
+		if !ok || (f.omitEmpty && isEmptyValue(fv)) {
+			continue
+		}
+		e.separator(first)
+		first = false
+		e.buf = appendString(e.buf, f.name)
+		e.colon()
+		if err := e.encodeField(fv, f.asString); err != nil {
+			return err
+		}
+	}
+	e.close('}', first)
+	return nil
+}
+
+// encodeField appends a struct field; with the "string" tag option the value is wrapped in a string.
+// Example: an int field tagged `json:"id,string"` is encoded as "42".
+func (e *encodeState) encodeField(v reflect.Value, asString bool) error {
+	if !asString {
+		return e.encode(v)
+	}
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			e.buf = append(e.buf, "null"...)
+			return nil
+		}
+		v = v.Elem()
+	}
+	inner := &encodeState{}
+	if err := inner.encode(v); err != nil {
+		return err
+	}
+	e.buf = appendString(e.buf, string(inner.buf))
+	return nil
+}
+
+// encodeMap appends a map as an object with its keys sorted.
+// Keys must be strings or integers; integers are written as decimal strings.
+func (e *encodeState) encodeMap(v reflect.Value) error {
+	type entry struct {
+		key   string
+		value reflect.Value
+	}
+	entries := make([]entry, 0, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		k := iter.Key()
+		var key string
+		switch k.Kind() {
+		case reflect.String:
+			key = k.String()
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			key = strconv.FormatInt(k.Int(), 10)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			key = strconv.FormatUint(k.Uint(), 10)
+		default:
+			return &UnsupportedTypeError{Type: v.Type()}
+		}
+		entries = append(entries, entry{key: key, value: iter.Value()})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].key < entries[j].key })
+
+	e.open('{')
+	for i, en := range entries {
+		e.separator(i == 0)
+		e.buf = appendString(e.buf, en.key)
+		e.colon()
+		if err := e.encode(en.value); err != nil {
+			return err
+		}
+	}
+	e.close('}', len(entries) == 0)
+	return nil
+}
+
+// encodeOrderedMap appends an OrderedMap as an object, keeping the order of its keys.
+func (e *encodeState) encodeOrderedMap(m *parser.OrderedMap) error {
+	e.open('{')
+	for i, key := range m.Keys() {
+		e.separator(i == 0)
+		e.buf = appendString(e.buf, key)
+		e.colon()
+		value, _ := m.Get(key)
+		if err := e.encode(reflect.ValueOf(value)); err != nil {
+			return err
+		}
+	}
+	e.close('}', m.Len() == 0)
+	return nil
+}
+
+// encodeArray appends a slice or an array.
+func (e *encodeState) encodeArray(v reflect.Value) error {
+	e.open('[')
+	for i := 0; i < v.Len(); i++ {
+		e.separator(i == 0)
+		if err := e.encode(v.Index(i)); err != nil {
+			return err
+		}
+	}
+	e.close(']', v.Len() == 0)
+	return nil
+}
+
+// encodeNode appends the document represented by an AST node, keeping the order of object members.
+func (e *encodeState) encodeNode(node *parser.AstNode) error {
+	switch node.Type {
+	case token.Object:
+		return e.nested(reflect.ValueOf(node), func() error {
+			members := node.Value.(*parser.Object).Members()
+			e.open('{')
+			for i, member := range members {
+				e.separator(i == 0)
+				e.buf = appendString(e.buf, member.Key)
+				e.colon()
+				if err := e.encodeNode(member.Value); err != nil {
+					return err
+				}
+			}
+			e.close('}', len(members) == 0)
+			return nil
+		})
+	case token.Array:
+		return e.nested(reflect.ValueOf(node), func() error {
+			elements := node.Value.([]*parser.AstNode)
+			e.open('[')
+			for i, element := range elements {
+				e.separator(i == 0)
+				if err := e.encodeNode(element); err != nil {
+					return err
+				}
+			}
+			e.close(']', len(elements) == 0)
+			return nil
+		})
+	default:
+		return e.encode(reflect.ValueOf(node.Value))
+	}
+}
+
+// open appends the opening brace or bracket of an object or array.
+func (e *encodeState) open(c byte) {
+	e.buf = append(e.buf, c)
+	e.depth++
+}
+
+// separator appends the comma before an object member or array element, and the line break when indenting.
+func (e *encodeState) separator(first bool) {
+	if !first {
+		e.buf = append(e.buf, ',')
+	}
+	if e.indent != "" || e.prefix != "" {
+		e.newline(e.depth)
+	}
+}
+
+// colon appends the separator between an object key and its value.
+func (e *encodeState) colon() {
+	e.buf = append(e.buf, ':')
+	if e.indent != "" || e.prefix != "" {
+		e.buf = append(e.buf, ' ')
+	}
+}
+
+// close appends the closing brace or bracket; empty objects and arrays stay on one line.
+func (e *encodeState) close(c byte, empty bool) {
+	e.depth--
+	if !empty && (e.indent != "" || e.prefix != "") {
+		e.newline(e.depth)
+	}
+	e.buf = append(e.buf, c)
+}
+
+// newline appends a line break, the prefix and depth copies of the indent.
+func (e *encodeState) newline(depth int) {
+	e.buf = append(e.buf, '\n')
+	e.buf = append(e.buf, e.prefix...)
+	for i := 0; i < depth; i++ {
+		e.buf = append(e.buf, e.indent...)
+	}
+}
+
+// fieldValue returns the struct field at index, reporting false if it is inside a nil embedded pointer.
+func fieldValue(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return v, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// isEmptyValue reports whether v is empty for the "omitempty" tag option:
+// false, 0, a nil pointer or interface, or an empty string, slice, array or map.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
+
+// appendString appends s as a quoted JSON string.
+// Quotes, backslashes and control characters are escaped, and invalid UTF-8 is replaced by U+FFFD.
+// Example: For `say "hi"` followed by a newline, it appends "say \"hi\"\n".
+func appendString(buf []byte, s string) []byte {
+	const hex = "0123456789abcdef"
+	buf = append(buf, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' {
+				i++
+				continue
+			}
+			buf = append(buf, s[start:i]...)
+			switch c {
+			case '"', '\\':
+				buf = append(buf, '\\', c)
+			case '\n':
+				buf = append(buf, '\\', 'n')
+			case '\r':
+				buf = append(buf, '\\', 'r')
+			case '\t':
+				buf = append(buf, '\\', 't')
+			case '\b':
+				buf = append(buf, '\\', 'b')
+			case '\f':
+				buf = append(buf, '\\', 'f')
+			default:
+				buf = append(buf, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xf])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			buf = append(buf, s[start:i]...)
+			buf = append(buf, "\ufffd"...)
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	buf = append(buf, s[start:]...)
+	return append(buf, '"')
+}
@@ -0,0 +1,208 @@
+package gojsonp
+
+import (
+	"bytes"
+	"errors"
+	"github.com/onerciller/gojsonp/parser"
+	"math"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type encodeInner struct {
+	City string `json:"city"`
+}
+
+type encodeUser struct {
+	encodeInner
+	Name    string            `json:"name"`
+	Age     int               `json:"age,omitempty"`
+	ID      int64             `json:"id,string"`
+	Email   *string           `json:"email"`
+	Tags    []string          `json:"tags,omitempty"`
+	Scores  map[string]uint   `json:"scores"`
+	Ratio   float32           `json:"ratio"`
+	Skipped bool              `json:"-"`
+	Extra   interface{}       `json:"extra,omitempty"`
+	ByID    map[int]string    `json:"by_id,omitempty"`
+	Nested  *encodeInner      `json:"nested,omitempty"`
+	Raw     map[string][]bool `json:"raw"`
+	private int
+}
+
+// TestMarshal tests the Marshal function for basic types, structs, maps and string escaping.
+func TestMarshal(t *testing.T) {
+	tests := []struct {
+		name  string
+		input interface{}
+		want  string
+	}{
+		{name: "Nil", input: nil, want: `null`},
+		{name: "Bool", input: true, want: `true`},
+		{name: "Int", input: -42, want: `-42`},
+		{name: "Uint", input: uint64(math.MaxUint64), want: `18446744073709551615`},
+		{name: "Float", input: 3.14, want: `3.14`},
+		{name: "Float32", input: float32(0.1), want: `0.1`},
+		{name: "Large float", input: 1e21, want: `1e+21`},
+		{name: "Small float", input: 1e-7, want: `1e-7`},
+		{name: "Integral float", input: 100.0, want: `100`},
+		{name: "Number", input: Number("1.50e+3"), want: `1.50e+3`},
+		{name: "Big int", input: new(big.Int).Lsh(big.NewInt(1), 70), want: `1180591620717411303424`},
+		{name: "Big float", input: big.NewFloat(0.25), want: `0.25`},
+		{name: "String escaping", input: "say \"hi\"\\\n\t\x01é😀", want: `"say \"hi\"\\\n\t\u0001é😀"`},
+		{name: "Invalid UTF-8", input: "a\xffb", want: "\"a�b\""},
+		{name: "Nil pointer", input: (*int)(nil), want: `null`},
+		{name: "Nil slice", input: []int(nil), want: `null`},
+		{name: "Empty slice", input: []int{}, want: `[]`},
+		{name: "Array", input: [3]int{1, 2, 3}, want: `[1,2,3]`},
+		{name: "Sorted map keys", input: map[string]int{"b": 2, "a": 1, "c": 3}, want: `{"a":1,"b":2,"c":3}`},
+		{name: "Interface slice", input: []interface{}{1, "a", nil, map[string]interface{}{}}, want: `[1,"a",null,{}]`},
+		{
+			name:  "Struct with tags",
+			input: encodeUser{encodeInner: encodeInner{City: "Oslo"}, Name: "John", ID: 7, Scores: map[string]uint{"x": 1}, Ratio: 0.5, Skipped: true, ByID: map[int]string{10: "a", 2: "b"}},
+			want:  `{"city":"Oslo","name":"John","id":"7","email":null,"scores":{"x":1},"ratio":0.5,"by_id":{"10":"a","2":"b"},"raw":null}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Marshal(tt.input)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Marshal() got = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestMarshalIndent tests the MarshalIndent function for nested values and empty containers.
+func TestMarshalIndent(t *testing.T) {
+	input := map[string]interface{}{
+		"a": []interface{}{1, map[string]interface{}{"b": true}},
+		"c": map[string]interface{}{},
+		"d": []int{},
+	}
+	want := `{
+>  "a": [
+>    1,
+>    {
+>      "b": true
+>    }
+>  ],
+>  "c": {},
+>  "d": []
+>}`
+	got, err := MarshalIndent(input, ">", "  ")
+	if err != nil {
+		t.Fatalf("MarshalIndent() error = %v", err)
+	}
+	if string(got) != want {
+		t.Errorf("MarshalIndent() got = %s, want %s", got, want)
+	}
+}
+
+// TestEncoder tests that the Encoder writes one value per line and nothing for values it cannot encode.
+func TestEncoder(t *testing.T) {
+	var buf bytes.Buffer
+	encoder := NewEncoder(&buf)
+	for _, v := range []interface{}{map[string]int{"a": 1}, "x", make(chan int), []int{1}} {
+		encoder.Encode(v)
+	}
+	encoder.SetIndent("", "\t")
+	if err := encoder.Encode([]int{2}); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	want := "{\"a\":1}\n\"x\"\n[1]\n[\n\t2\n]\n"
+	if buf.String() != want {
+		t.Errorf("Encode() wrote %q, want %q", buf.String(), want)
+	}
+}
+
+// TestMarshalRoundTrip tests that DecodeJson reads back exactly what Marshal wrote.
+func TestMarshalRoundTrip(t *testing.T) {
+	input := map[string]interface{}{
+		"string": "line\nbreak \"quoted\" \\   \x1f",
+		"number": 0.1 + 0.2,
+		"tiny":   5e-324,
+		"huge":   1.7976931348623157e308,
+		"int":    float64(-9007199254740991),
+		"bool":   false,
+		"null":   nil,
+		"array":  []interface{}{"a", float64(1), []interface{}{}, map[string]interface{}{"k": true}},
+		"object": map[string]interface{}{"nested": map[string]interface{}{"deep": "value"}},
+	}
+
+	data, err := Marshal(input)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	got, err := DecodeJson(data)
+	if err != nil {
+		t.Fatalf("DecodeJson(%s) error = %v", data, err)
+	}
+	if !reflect.DeepEqual(got, input) {
+		t.Errorf("DecodeJson(Marshal()) got = %#v, want %#v", got, input)
+	}
+
+	again, err := Marshal(got)
+	if err != nil || !bytes.Equal(again, data) {
+		t.Errorf("Marshal() is not stable: %s != %s", again, data)
+	}
+}
+
+// TestMarshalAstNode tests that AST nodes are encoded in source order.
+func TestMarshalAstNode(t *testing.T) {
+	input := `{"z": 1, "a": [true, null, {"m": "x", "b": 2.5}]}`
+	node, err := parser.ParseReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseReader() error = %v", err)
+	}
+	got, err := Marshal(node)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if want := `{"z":1,"a":[true,null,{"m":"x","b":2.5}]}`; string(got) != want {
+		t.Errorf("Marshal() got = %s, want %s", got, want)
+	}
+}
+
+// TestMarshalErrors tests the errors for values without a JSON representation.
+func TestMarshalErrors(t *testing.T) {
+	type cycle struct {
+		Next *cycle
+	}
+	loop := &cycle{}
+	loop.Next = loop
+
+	var typeErr *UnsupportedTypeError
+	var valueErr *UnsupportedValueError
+	tests := []struct {
+		name   string
+		input  interface{}
+		target interface{}
+	}{
+		{name: "NaN", input: math.NaN(), target: &valueErr},
+		{name: "Infinity", input: math.Inf(1), target: &valueErr},
+		{name: "Channel", input: make(chan int), target: &typeErr},
+		{name: "Function field", input: struct{ F func() }{}, target: &typeErr},
+		{name: "Complex", input: complex(1, 2), target: &typeErr},
+		{name: "Map with struct keys", input: map[struct{}]int{{}: 1}, target: &typeErr},
+		{name: "Cycle", input: loop, target: &valueErr},
+		{name: "Invalid Number", input: Number("1,5"), target: &valueErr},
+		{name: "Infinite big float", input: new(big.Float).SetInf(false), target: &valueErr},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Marshal(tt.input)
+			if !errors.As(err, tt.target) {
+				t.Errorf("Marshal() error = %v, want %T", err, tt.target)
+			}
+		})
+	}
+}
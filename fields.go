@@ -0,0 +1,179 @@
+package gojsonp
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// field describes a struct field as seen by Marshal and Unmarshal.
+type field struct {
+
+	// name is the JSON key, taken from the json tag or the Go field name
+	name string
+
+	// index is the path of field indices to the field, through embedded structs
+	index []int
+
+	// typ is the Go type of the field
+	typ reflect.Type
+
+	// tagged reports whether the name comes from a json tag
+	tagged bool
+
+	// omitEmpty and asString are the "omitempty" and "string" tag options
+	omitEmpty bool
+	asString  bool
+}
+
+// fieldCache maps a struct type to its []field.
+var fieldCache sync.Map
+
+// cachedFields returns the fields of struct type t, computing them on first use.
+func cachedFields(t reflect.Type) []field {
+	if fields, ok := fieldCache.Load(t); ok {
+		return fields.([]field)
+	}
+	fields, _ := fieldCache.LoadOrStore(t, typeFields(t))
+	return fields.([]field)
+}
+
+// typeFields collects the fields of struct type t, including those promoted from embedded structs.
+// It follows the Go visibility rules: a shallower field hides deeper ones with the same name,
+// and of several fields at the same depth only a single tagged one survives.
+// Example: for `type A struct { B; Name string `json:"name,omitempty"` }` it returns "name" and the fields of B.
+func typeFields(t reflect.Type) []field {
+	var fields []field
+
+	// walk embedded structs breadth first, so shallower fields come first
+	type level struct {
+		typ   reflect.Type
+		index []int
+	}
+	current := []level{{typ: t}}
+	visited := map[reflect.Type]bool{}
+	for len(current) > 0 {
+		var next []level
+		for _, l := range current {
+			if visited[l.typ] {
+				continue
+			}
+			visited[l.typ] = true
+
+			for i := 0; i < l.typ.NumField(); i++ {
+				sf := l.typ.Field(i)
+				tag := sf.Tag.Get("json")
+				if tag == "-" {
+					continue
+				}
+				name, options := parseTag(tag)
+				index := append(append([]int{}, l.index...), i)
+
+				fieldType := sf.Type
+				if fieldType.Kind() == reflect.Ptr {
+					fieldType = fieldType.Elem()
+				}
+				if sf.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+					next = append(next, level{typ: fieldType, index: index})
+					continue
+				}
+				if !sf.IsExported() {
+					continue
+				}
+
+				f := field{name: name, index: index, typ: sf.Type, tagged: name != ""}
+				if f.name == "" {
+					f.name = sf.Name
+				}
+				f.omitEmpty = options.contains("omitempty")
+				f.asString = options.contains("string") && isStringable(sf.Type)
+				fields = append(fields, f)
+			}
+		}
+		current = next
+	}
+
+	// keep only the dominant field for every name
+	sort.SliceStable(fields, func(i, j int) bool {
+		if fields[i].name != fields[j].name {
+			return fields[i].name < fields[j].name
+		}
+		if len(fields[i].index) != len(fields[j].index) {
+			return len(fields[i].index) < len(fields[j].index)
+		}
+		return fields[i].tagged && !fields[j].tagged
+	})
+	var result []field
+	for i := 0; i < len(fields); {
+		j := i + 1
+		for j < len(fields) && fields[j].name == fields[i].name {
+			j++
+		}
+		if dominant, ok := dominantField(fields[i:j]); ok {
+			result = append(result, dominant)
+		}
+		i = j
+	}
+
+	// restore the declaration order
+	sort.Slice(result, func(i, j int) bool {
+		return lessIndex(result[i].index, result[j].index)
+	})
+	return result
+}
+
+// dominantField picks the visible field out of fields with the same name, sorted by depth and tag.
+// It reports false when the name is ambiguous.
+func dominantField(fields []field) (field, bool) {
+	if len(fields) > 1 && len(fields[0].index) == len(fields[1].index) && fields[0].tagged == fields[1].tagged {
+		return field{}, false
+	}
+	return fields[0], true
+}
+
+// lessIndex compares two field index paths in declaration order.
+func lessIndex(a, b []int) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return len(a) < len(b)
+}
+
+// tagOptions is the comma-separated list of options following the name in a json tag.
+type tagOptions string
+
+// parseTag splits a json tag into its name and options.
+// Example: For "name,omitempty", it returns "name" and "omitempty".
+func parseTag(tag string) (string, tagOptions) {
+	name, options, _ := strings.Cut(tag, ",")
+	return name, tagOptions(options)
+}
+
+// contains reports whether the options include option.
+func (o tagOptions) contains(option string) bool {
+	for _, s := range strings.Split(string(o), ",") {
+		if s == option {
+			return true
+		}
+	}
+	return false
+}
+
+// isStringable reports whether the "string" tag option applies to type t:
+// strings, numbers and booleans, or pointers to them.
+func isStringable(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
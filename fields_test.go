@@ -0,0 +1,52 @@
+package gojsonp
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestTypeFields tests the visibility rules for fields promoted from embedded structs.
+func TestTypeFields(t *testing.T) {
+	type inner struct {
+		A string
+		B string `json:"b"`
+		C string
+	}
+	type other struct {
+		C string
+	}
+	type outer struct {
+		inner
+		*other
+		A     string `json:"A,omitempty"`
+		D     int    `json:"d,string"`
+		E     []int  `json:"e,string"`
+		Skip  string `json:"-"`
+		local string
+	}
+
+	var names []string
+	for _, f := range typeFields(reflect.TypeOf(outer{})) {
+		names = append(names, f.name)
+		switch f.name {
+		case "A":
+			if !f.omitEmpty || len(f.index) != 1 {
+				t.Errorf("Expected the shallow tagged A with omitempty, got %+v", f)
+			}
+		case "d":
+			if !f.asString {
+				t.Errorf("Expected d to use the string option, got %+v", f)
+			}
+		case "e":
+			if f.asString {
+				t.Errorf("Expected the string option to be ignored for slices, got %+v", f)
+			}
+		}
+	}
+
+	// C is ambiguous between inner and other, so it is dropped
+	expected := []string{"b", "A", "d", "e"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("Expected fields %v, got %v", expected, names)
+	}
+}
@@ -5,10 +5,40 @@ import (
 	"github.com/onerciller/gojsonp/token"
 )
 
+// SyntaxError describes malformed input; see token.SyntaxError.
+// Use errors.Is with the token.Err* kinds to tell the errors apart.
+type SyntaxError = token.SyntaxError
+
+// LimitError describes input that exceeds the Limits option; see token.LimitError.
+// Use errors.Is with the token.ErrMax* kinds to tell the limits apart.
+type LimitError = token.LimitError
+
+// Number is the literal text of a JSON number; see parser.Number.
+// Decode produces it with Numbers(parser.NumberString), Unmarshal stores numbers in Number fields as written
+// and Marshal writes it back unchanged.
+type Number = parser.Number
+
 // DecodeJson function to convert JSON string to map.
 // It uses the tokenizer to convert the JSON string into tokens.
 // It uses the parser to convert the tokens into AST nodes.
-func DecodeJson(data []byte) (map[string]interface{}, error) {
-	tokens := token.Tokenizer(data)
-	return parser.AstToMap(tokens)
+// Malformed input is reported as a *SyntaxError.
+func DecodeJson(data []byte, opts ...Option) (map[string]interface{}, error) {
+	options := newDecodeOptions(opts)
+	tokens, err := token.Tokenizer(data, options.token...)
+	if err != nil {
+		return nil, err
+	}
+	return parser.AstToMap(tokens, options.parser...)
+}
+
+// Decode function to convert any JSON value to Go values.
+// Unlike DecodeJson it accepts top-level arrays, strings, numbers, booleans and null.
+// Example: Decode([]byte(`[1, "a"]`)) returns []interface{}{float64(1), "a"}.
+func Decode(data []byte, opts ...Option) (interface{}, error) {
+	options := newDecodeOptions(opts)
+	tokens, err := token.Tokenizer(data, options.token...)
+	if err != nil {
+		return nil, err
+	}
+	return parser.AstToValue(tokens, options.parser...)
 }
@@ -0,0 +1,233 @@
+package gojsonp
+
+import (
+	"bytes"
+	"errors"
+	"github.com/onerciller/gojsonp/parser"
+	"github.com/onerciller/gojsonp/token"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+// TestDecodeJson tests the DecodeJson function for objects and malformed input.
+func TestDecodeJson(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    map[string]interface{}
+		wantErr error
+	}{
+		{
+			name:  "Nested object",
+			input: `{"name": "John", "tags": ["a", "b"], "address": {"zip": 12345}}`,
+			want: map[string]interface{}{
+				"name":    "John",
+				"tags":    []interface{}{"a", "b"},
+				"address": map[string]interface{}{"zip": float64(12345)},
+			},
+		},
+		{name: "Unclosed string", input: `{"name": "John`, wantErr: token.ErrUnclosedString},
+		{name: "Missing colon", input: `{"name" "John"}`, wantErr: token.ErrUnexpectedToken},
+		{name: "Trailing value", input: `{} {}`, wantErr: token.ErrUnexpectedToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeJson([]byte(tt.input))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("DecodeJson() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var syntaxErr *SyntaxError
+			if err != nil && !errors.As(err, &syntaxErr) {
+				t.Errorf("DecodeJson() error = %#v, want *SyntaxError", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DecodeJson() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestDecode tests the Decode function for top-level values of every type.
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  interface{}
+	}{
+		{name: "Array", input: `[1, "a", null]`, want: []interface{}{float64(1), "a", nil}},
+		{name: "String", input: `"hi"`, want: "hi"},
+		{name: "Number", input: `-1.5`, want: -1.5},
+		{name: "Boolean", input: `true`, want: true},
+		{name: "Null", input: `null`, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Decode([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Decode() got = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestDecodeOrderedObjects tests that documents decoded with OrderedObjects are written back in source order.
+func TestDecodeOrderedObjects(t *testing.T) {
+	input := `{"version":2,"name":"app","deps":{"zlib":"1.0","abc":"2.0"},"list":[{"b":1,"a":2}]}`
+
+	value, err := Decode([]byte(input), OrderedObjects())
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if _, ok := value.(*parser.OrderedMap); !ok {
+		t.Fatalf("Decode() got = %T, want *parser.OrderedMap", value)
+	}
+	output, err := Marshal(value)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if string(output) != input {
+		t.Errorf("Marshal(Decode()) = %s, want %s", output, input)
+	}
+
+	decoder := NewDecoder(strings.NewReader(input), OrderedObjects())
+	if value, err := decoder.Decode(); err != nil || !reflect.DeepEqual(value, mustDecode(t, input)) {
+		t.Errorf("Decoder.Decode() got = %v, %v", value, err)
+	}
+}
+
+// TestDecodeDuplicateKeys tests that the duplicate-key policy reaches the parser.
+func TestDecodeDuplicateKeys(t *testing.T) {
+	input := []byte(`{"id": 1, "id": 2}`)
+
+	value, err := Decode(input, DuplicateKeys(parser.DuplicateKeepFirst))
+	if err != nil || !reflect.DeepEqual(value, map[string]interface{}{"id": float64(1)}) {
+		t.Errorf("Decode() got = %v, %v", value, err)
+	}
+
+	_, err = Decode(input, DuplicateKeys(parser.DuplicateReject))
+	var syntaxErr *SyntaxError
+	if !errors.As(err, &syntaxErr) || !errors.Is(err, parser.ErrDuplicateKey) || syntaxErr.Pos.Column != 11 {
+		t.Errorf("Decode() error = %v, want duplicate key at 1:11", err)
+	}
+}
+
+// TestDecodeNumbers tests that the number mode reaches the parser.
+func TestDecodeNumbers(t *testing.T) {
+	value, err := Decode([]byte(`{"id": 9007199254740993, "price": 1.0}`), Numbers(parser.NumberString))
+	want := map[string]interface{}{"id": Number("9007199254740993"), "price": Number("1.0")}
+	if err != nil || !reflect.DeepEqual(value, want) {
+		t.Errorf("Decode() got = %v, %v, want %v", value, err, want)
+	}
+
+	_, err = Decode([]byte(`[1, 9223372036854775808]`), Numbers(parser.NumberInt64))
+	if !errors.Is(err, parser.ErrNumberRange) {
+		t.Errorf("Decode() error = %v, want parser.ErrNumberRange", err)
+	}
+}
+
+// TestDecodeJSON5 tests that the JSON5 option reaches both the tokenizer and the parser.
+func TestDecodeJSON5(t *testing.T) {
+	input := []byte("// config\n{port: 0x1F90, hosts: ['a', 'b',],}")
+	value, err := Decode(input, JSON5())
+	want := map[string]interface{}{"port": float64(8080), "hosts": []interface{}{"a", "b"}}
+	if err != nil || !reflect.DeepEqual(value, want) {
+		t.Errorf("Decode() got = %v, %v, want %v", value, err, want)
+	}
+
+	if _, err := Decode(input); !errors.Is(err, token.ErrInvalidCharacter) {
+		t.Errorf("Decode() error = %v, want token.ErrInvalidCharacter without JSON5", err)
+	}
+}
+
+// TestDecodeJSONC tests that the JSONC option accepts comments in otherwise strict JSON.
+func TestDecodeJSONC(t *testing.T) {
+	input := []byte("{\n  // indentation\n  \"tabSize\": 4, /* spaces */\n  \"insertSpaces\": true\n}")
+	value, err := Decode(input, JSONC())
+	want := map[string]interface{}{"tabSize": float64(4), "insertSpaces": true}
+	if err != nil || !reflect.DeepEqual(value, want) {
+		t.Errorf("Decode() got = %v, %v, want %v", value, err, want)
+	}
+
+	_, err = Decode([]byte(`{"a": 1 /* open`), JSONC())
+	if !errors.Is(err, token.ErrUnclosedComment) {
+		t.Errorf("Decode() error = %v, want token.ErrUnclosedComment", err)
+	}
+}
+
+// TestDecodeLimits tests that the limits reach both the tokenizer and the parser of Decode and Decoder.
+func TestDecodeLimits(t *testing.T) {
+	limits := Limits(token.Limits{MaxInputBytes: 64, MaxDepth: 2, MaxArrayLength: 3})
+
+	var limitErr *LimitError
+	_, err := DecodeJson([]byte(`{"tags": [`+strings.Repeat(`"tag", `, 10)+`"tag"]}`), limits)
+	if !errors.As(err, &limitErr) || !errors.Is(err, token.ErrMaxInputBytes) {
+		t.Errorf("DecodeJson() error = %v, want token.ErrMaxInputBytes", err)
+	}
+	_, err = Decode([]byte(`[1, 2, 3, 4]`), limits)
+	if !errors.As(err, &limitErr) || !errors.Is(err, token.ErrMaxArrayLength) {
+		t.Errorf("Decode() error = %v, want token.ErrMaxArrayLength", err)
+	}
+	if _, err := Decode([]byte(`{"a": [1, 2, 3]}`), limits); err != nil {
+		t.Errorf("Decode() error = %v", err)
+	}
+
+	decoder := NewDecoder(strings.NewReader(`[1] [[[2]]]`), limits)
+	if _, err := decoder.Decode(); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if _, err := decoder.Decode(); !errors.Is(err, token.ErrMaxDepth) {
+		t.Errorf("Decode() error = %v, want token.ErrMaxDepth", err)
+	}
+}
+
+// TestDecodeUTF8 tests the handling of byte order marks and invalid UTF-8 in Decode.
+func TestDecodeUTF8(t *testing.T) {
+	value, err := Decode([]byte("\xef\xbb\xbf{\"a\": \"b\"}"))
+	if err != nil || !reflect.DeepEqual(value, map[string]interface{}{"a": "b"}) {
+		t.Errorf("Decode() got = %v, %v, want the byte order mark to be skipped", value, err)
+	}
+
+	input := []byte("{\"name\": \"caf\xe9\"}")
+	if _, err := Decode(input); !errors.Is(err, token.ErrInvalidUTF8) {
+		t.Errorf("Decode() error = %v, want token.ErrInvalidUTF8", err)
+	}
+	value, err = Decode(input, ReplaceInvalidUTF8())
+	if err != nil || !reflect.DeepEqual(value, map[string]interface{}{"name": "caf\uFFFD"}) {
+		t.Errorf("Decode() got = %q, %v, want the invalid byte replaced", value, err)
+	}
+}
+
+// TestDecodeAnyEncoding tests that AnyEncoding decodes UTF-16 input in Decode and Decoder.
+func TestDecodeAnyEncoding(t *testing.T) {
+	var input []byte
+	for _, u := range utf16.Encode([]rune(`{"city": "Zürich"}`)) {
+		input = append(input, byte(u>>8), byte(u))
+	}
+	want := map[string]interface{}{"city": "Zürich"}
+
+	value, err := Decode(input, AnyEncoding())
+	if err != nil || !reflect.DeepEqual(value, want) {
+		t.Errorf("Decode() got = %v, %v, want %v", value, err, want)
+	}
+	value, err = NewDecoder(bytes.NewReader(input), AnyEncoding()).Decode()
+	if err != nil || !reflect.DeepEqual(value, want) {
+		t.Errorf("Decoder.Decode() got = %v, %v, want %v", value, err, want)
+	}
+}
+
+// mustDecode decodes input with OrderedObjects and fails the test on error.
+func mustDecode(t *testing.T, input string) interface{} {
+	t.Helper()
+	value, err := Decode([]byte(input), OrderedObjects())
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	return value
+}
@@ -0,0 +1,528 @@
+package jsonpath
+
+import (
+	"errors"
+	"github.com/onerciller/gojsonp/token"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// ErrSyntax is the kind of the SyntaxError reported for a malformed query.
+var ErrSyntax = errors.New("invalid JSONPath")
+
+// maxIndex is the largest array index or slice bound a query may contain, 2^53-1 as RFC 9535 requires.
+const maxIndex = 1<<53 - 1
+
+// compiler is a recursive-descent parser for the JSONPath grammar of RFC 9535.
+type compiler struct {
+	input string
+	pos   int
+
+	// filters counts the enclosing filter selectors; "@" is only valid inside one
+	filters int
+}
+
+// compile parses the whole input as a query starting with "$".
+func (c *compiler) compile() (*Path, error) {
+	if !c.consume("$") {
+		return nil, c.fail("$")
+	}
+	segments, err := c.segments()
+	if err != nil {
+		return nil, err
+	}
+	if c.pos < len(c.input) {
+		return nil, c.fail("")
+	}
+	return &Path{source: c.input, segments: segments}, nil
+}
+
+// segments parses the segments that follow "$" or "@".
+// Blanks may precede a segment, but are left unread if no segment follows.
+func (c *compiler) segments() ([]segment, error) {
+	var segments []segment
+	for {
+		start := c.pos
+		c.skipBlanks()
+		if !strings.HasPrefix(c.input[c.pos:], ".") && !strings.HasPrefix(c.input[c.pos:], "[") {
+			c.pos = start
+			return segments, nil
+		}
+		seg, err := c.segment()
+		if err != nil {
+			return nil, err
+		}
+		segments = append(segments, seg)
+	}
+}
+
+// segment parses one child or descendant segment: ".name", ".*", "[...]", "..name", "..*" or "..[...]".
+func (c *compiler) segment() (segment, error) {
+	if c.consume("..") {
+		seg := segment{descendant: true}
+		switch {
+		case c.peek() == '[':
+			selectors, err := c.bracketed()
+			if err != nil {
+				return segment{}, err
+			}
+			seg.selectors = selectors
+		case c.consume("*"):
+			seg.selectors = []selector{wildcardSelector{}}
+		default:
+			name, err := c.memberName()
+			if err != nil {
+				return segment{}, err
+			}
+			seg.selectors = []selector{nameSelector(name)}
+		}
+		return seg, nil
+	}
+
+	if c.consume(".") {
+		if c.consume("*") {
+			return segment{selectors: []selector{wildcardSelector{}}}, nil
+		}
+		name, err := c.memberName()
+		if err != nil {
+			return segment{}, err
+		}
+		return segment{selectors: []selector{nameSelector(name)}}, nil
+	}
+
+	selectors, err := c.bracketed()
+	if err != nil {
+		return segment{}, err
+	}
+	return segment{selectors: selectors}, nil
+}
+
+// bracketed parses a bracketed selection such as "['a', 0, 1:3, *, ?@.b]".
+func (c *compiler) bracketed() ([]selector, error) {
+	if !c.consume("[") {
+		return nil, c.fail("[")
+	}
+	var selectors []selector
+	for {
+		c.skipBlanks()
+		sel, err := c.selector()
+		if err != nil {
+			return nil, err
+		}
+		selectors = append(selectors, sel)
+		c.skipBlanks()
+		if c.consume("]") {
+			return selectors, nil
+		}
+		if !c.consume(",") {
+			return nil, c.fail(", or ]")
+		}
+	}
+}
+
+// selector parses a single selector of a bracketed selection.
+func (c *compiler) selector() (selector, error) {
+	switch ch := c.peek(); {
+	case ch == '\'' || ch == '"':
+		name, err := c.stringLiteral()
+		if err != nil {
+			return nil, err
+		}
+		return nameSelector(name), nil
+	case ch == '*':
+		c.pos++
+		return wildcardSelector{}, nil
+	case ch == '?':
+		c.pos++
+		c.skipBlanks()
+		c.filters++
+		expr, err := c.logicalOr()
+		c.filters--
+		if err != nil {
+			return nil, err
+		}
+		return filterSelector{expr: expr}, nil
+	case ch == ':' || ch == '-' || isDigit(ch):
+		return c.indexOrSlice()
+	default:
+		return nil, c.fail("selector")
+	}
+}
+
+// indexOrSlice parses an index such as "-1" or a slice such as "1:5:2", where every part is optional.
+func (c *compiler) indexOrSlice() (selector, error) {
+	var bounds [3]*int
+	part := 0
+	for {
+		c.skipBlanks()
+		if ch := c.peek(); ch == '-' || isDigit(ch) {
+			n, err := c.integer()
+			if err != nil {
+				return nil, err
+			}
+			bounds[part] = &n
+			c.skipBlanks()
+		}
+		if part == 2 || !c.consume(":") {
+			break
+		}
+		part++
+	}
+
+	if part == 0 {
+		if bounds[0] == nil {
+			return nil, c.fail("selector")
+		}
+		return indexSelector(*bounds[0]), nil
+	}
+	step := 1
+	if bounds[2] != nil {
+		step = *bounds[2]
+	}
+	return sliceSelector{start: bounds[0], end: bounds[1], step: step}, nil
+}
+
+// integer parses an integer without leading zeros in the range of maxIndex; "-0" is not allowed.
+func (c *compiler) integer() (int, error) {
+	start := c.pos
+	c.consume("-")
+	digits := c.pos
+	for isDigit(c.peek()) {
+		c.pos++
+	}
+	text := c.input[start:c.pos]
+	if c.pos == digits || (c.input[digits] == '0' && (c.pos-digits > 1 || digits > start)) {
+		return 0, c.failAt(start, "integer")
+	}
+	n, err := strconv.Atoi(text)
+	if err != nil || n > maxIndex || n < -maxIndex {
+		return 0, c.failAt(start, "integer")
+	}
+	return n, nil
+}
+
+// memberName parses the name of a shorthand such as ".name": a letter, '_' or non-ASCII character,
+// followed by any of those or digits.
+func (c *compiler) memberName() (string, error) {
+	start := c.pos
+	for c.pos < len(c.input) {
+		r, size := utf8.DecodeRuneInString(c.input[c.pos:])
+		if !isNameCharacter(r) || (c.pos == start && isDigit(c.input[c.pos])) {
+			break
+		}
+		c.pos += size
+	}
+	if c.pos == start {
+		return "", c.fail("member name")
+	}
+	return c.input[start:c.pos], nil
+}
+
+// stringLiteral parses a string in single or double quotes, decoding its escape sequences.
+// Example: 'it\'s' and "it's" both return it's.
+func (c *compiler) stringLiteral() (string, error) {
+	quote := c.input[c.pos]
+	c.pos++
+	var sb strings.Builder
+	for {
+		if c.pos >= len(c.input) {
+			return "", c.fail(string(quote))
+		}
+		ch := c.input[c.pos]
+		switch {
+		case ch == quote:
+			c.pos++
+			return sb.String(), nil
+		case ch < 0x20:
+			return "", c.fail("")
+		case ch != '\\':
+			sb.WriteByte(ch)
+			c.pos++
+			continue
+		}
+
+		// escape sequence: '\' followed by one of bfnrt/\ or u, or the quote that delimits the string
+		escape := c.pos
+		c.pos++
+		switch c.peek() {
+		case 'b':
+			sb.WriteByte('\b')
+		case 'f':
+			sb.WriteByte('\f')
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		case 't':
+			sb.WriteByte('\t')
+		case '/', '\\', quote:
+			sb.WriteByte(c.input[c.pos])
+		case 'u':
+			r, ok := c.unicodeEscape()
+			if !ok {
+				return "", c.failAt(escape, "")
+			}
+			sb.WriteRune(r)
+			continue
+		default:
+			return "", c.failAt(escape, "")
+		}
+		c.pos++
+	}
+}
+
+// unicodeEscape parses the hex digits of a \u escape at c.pos, which points at the 'u',
+// combining a surrogate pair into one code point. Lone surrogates are rejected.
+func (c *compiler) unicodeEscape() (rune, bool) {
+	r, ok := c.hex4(c.pos + 1)
+	if !ok {
+		return 0, false
+	}
+	c.pos += 5
+	if !utf16.IsSurrogate(r) {
+		return r, true
+	}
+	if r >= 0xDC00 || !strings.HasPrefix(c.input[c.pos:], `\u`) {
+		return 0, false
+	}
+	low, ok := c.hex4(c.pos + 2)
+	if !ok || low < 0xDC00 || low > 0xDFFF {
+		return 0, false
+	}
+	c.pos += 6
+	return utf16.DecodeRune(r, low), true
+}
+
+// hex4 parses four hex digits at index.
+func (c *compiler) hex4(index int) (rune, bool) {
+	if index+4 > len(c.input) {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(c.input[index:index+4], 16, 32)
+	if err != nil {
+		return 0, false
+	}
+	return rune(n), true
+}
+
+// logicalOr parses operands joined by "||".
+func (c *compiler) logicalOr() (expression, error) {
+	var operands orExpression
+	for {
+		operand, err := c.logicalAnd()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, operand)
+		c.skipBlanks()
+		if !c.consume("||") {
+			break
+		}
+		c.skipBlanks()
+	}
+	if len(operands) == 1 {
+		return operands[0], nil
+	}
+	return operands, nil
+}
+
+// logicalAnd parses operands joined by "&&".
+func (c *compiler) logicalAnd() (expression, error) {
+	var operands andExpression
+	for {
+		operand, err := c.basic()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, operand)
+		c.skipBlanks()
+		if !c.consume("&&") {
+			break
+		}
+		c.skipBlanks()
+	}
+	if len(operands) == 1 {
+		return operands[0], nil
+	}
+	return operands, nil
+}
+
+// basic parses a parenthesized expression, an existence test or a comparison, each optionally negated with "!".
+// A negated operand must be parenthesized or a query, as in "!(@.a == 1)" or "!@.a".
+func (c *compiler) basic() (expression, error) {
+	if c.consume("!") {
+		c.skipBlanks()
+		if c.peek() == '(' {
+			operand, err := c.basic()
+			if err != nil {
+				return nil, err
+			}
+			return notExpression{operand: operand}, nil
+		}
+		start := c.pos
+		operand, err := c.comparable()
+		if err != nil {
+			return nil, err
+		}
+		query, ok := operand.(*filterQuery)
+		if !ok {
+			return nil, c.failAt(start, "( or query")
+		}
+		return notExpression{operand: existenceTest{query: query}}, nil
+	}
+
+	if c.consume("(") {
+		c.skipBlanks()
+		expr, err := c.logicalOr()
+		if err != nil {
+			return nil, err
+		}
+		c.skipBlanks()
+		if !c.consume(")") {
+			return nil, c.fail(")")
+		}
+		return expr, nil
+	}
+
+	start := c.pos
+	left, err := c.comparable()
+	if err != nil {
+		return nil, err
+	}
+	c.skipBlanks()
+	op := c.comparisonOperator()
+	if op == "" {
+		query, ok := left.(*filterQuery)
+		if !ok {
+			return nil, c.failAt(start, "query")
+		}
+		return existenceTest{query: query}, nil
+	}
+	if err := c.checkSingular(left, start); err != nil {
+		return nil, err
+	}
+
+	c.skipBlanks()
+	start = c.pos
+	right, err := c.comparable()
+	if err != nil {
+		return nil, err
+	}
+	if err := c.checkSingular(right, start); err != nil {
+		return nil, err
+	}
+	return comparison{op: op, left: left, right: right}, nil
+}
+
+// comparisonOperator consumes one of ==, !=, <=, >=, < and >, or returns "" if none follows.
+func (c *compiler) comparisonOperator() string {
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if c.consume(op) {
+			return op
+		}
+	}
+	return ""
+}
+
+// checkSingular fails if a compared query may select more than one node, such as "@.*".
+func (c *compiler) checkSingular(operand comparable, start int) error {
+	if query, ok := operand.(*filterQuery); ok && !query.singular() {
+		return c.failAt(start, "singular query")
+	}
+	return nil
+}
+
+// comparable parses a literal or a query starting with "@" or "$".
+func (c *compiler) comparable() (comparable, error) {
+	switch ch := c.peek(); {
+	case ch == '@' || ch == '$':
+		if ch == '@' && c.filters == 0 {
+			return nil, c.fail("")
+		}
+		c.pos++
+		segments, err := c.segments()
+		if err != nil {
+			return nil, err
+		}
+		return &filterQuery{relative: ch == '@', segments: segments}, nil
+	case ch == '\'' || ch == '"':
+		s, err := c.stringLiteral()
+		if err != nil {
+			return nil, err
+		}
+		return literal{node: literalString(s)}, nil
+	case ch == '-' || isDigit(ch):
+		start := c.pos
+		for c.pos < len(c.input) && strings.IndexByte("+-.0123456789eE", c.input[c.pos]) >= 0 {
+			c.pos++
+		}
+		if !token.IsNumber(c.input[start:c.pos]) {
+			return nil, c.failAt(start, "number")
+		}
+		return literal{node: literalNumber(c.input[start:c.pos])}, nil
+	}
+
+	for _, word := range []string{"true", "false", "null"} {
+		if strings.HasPrefix(c.input[c.pos:], word) {
+			r, _ := utf8.DecodeRuneInString(c.input[c.pos+len(word):])
+			if !isNameCharacter(r) {
+				c.pos += len(word)
+				return literal{node: literalKeyword(word)}, nil
+			}
+		}
+	}
+	return nil, c.fail("literal or query")
+}
+
+// peek returns the current byte, or 0 at the end of the input.
+func (c *compiler) peek() byte {
+	if c.pos >= len(c.input) {
+		return 0
+	}
+	return c.input[c.pos]
+}
+
+// consume skips s if the input continues with it and reports whether it did.
+func (c *compiler) consume(s string) bool {
+	if strings.HasPrefix(c.input[c.pos:], s) {
+		c.pos += len(s)
+		return true
+	}
+	return false
+}
+
+// skipBlanks skips spaces, tabs and line breaks.
+func (c *compiler) skipBlanks() {
+	for c.pos < len(c.input) && strings.IndexByte(" \t\n\r", c.input[c.pos]) >= 0 {
+		c.pos++
+	}
+}
+
+// fail creates a SyntaxError at the current position.
+func (c *compiler) fail(expected string) error {
+	return c.failAt(c.pos, expected)
+}
+
+// failAt creates a SyntaxError for the character at index; queries are a single line, so the column is index+1.
+func (c *compiler) failAt(index int, expected string) error {
+	found := ""
+	if index < len(c.input) {
+		r, _ := utf8.DecodeRuneInString(c.input[index:])
+		found = string(r)
+	}
+	pos := token.Position{Offset: index, Line: 1, Column: index + 1}
+	return token.NewSyntaxError(ErrSyntax, pos, found, expected)
+}
+
+// isDigit checks if a byte is a digit (0-9).
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// isNameCharacter checks if r may appear in a member name shorthand: letters, digits, '_' and non-ASCII characters.
+func isNameCharacter(r rune) bool {
+	return r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') ||
+		(r >= 0x80 && r != utf8.RuneError)
+}
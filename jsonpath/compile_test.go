@@ -0,0 +1,79 @@
+package jsonpath
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// TestCompileErrors tests that malformed queries are reported at the offending character.
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{query: ``, want: `1:1: invalid JSONPath, expected $`},
+		{query: `store`, want: `1:1: invalid JSONPath "s", expected $`},
+		{query: `$.`, want: `1:3: invalid JSONPath, expected member name`},
+		{query: `$.1a`, want: `1:3: invalid JSONPath "1", expected member name`},
+		{query: `$ `, want: `1:2: invalid JSONPath " "`},
+		{query: `$[`, want: `1:3: invalid JSONPath, expected selector`},
+		{query: `$[0`, want: `1:4: invalid JSONPath, expected , or ]`},
+		{query: `$[01]`, want: `1:3: invalid JSONPath "0", expected integer`},
+		{query: `$[-0]`, want: `1:3: invalid JSONPath "-", expected integer`},
+		{query: `$[9007199254740992]`, want: `1:3: invalid JSONPath "9", expected integer`},
+		{query: `$['a`, want: `1:5: invalid JSONPath, expected '`},
+		{query: `$['a\x']`, want: `1:5: invalid JSONPath "\\"`},
+		{query: `$["\uD800"]`, want: `1:4: invalid JSONPath "\\"`},
+		{query: `$[?@.a == ]`, want: `1:11: invalid JSONPath "]", expected literal or query`},
+		{query: `$[?@.* == 1]`, want: `1:4: invalid JSONPath "@", expected singular query`},
+		{query: `$[?1 == @..a]`, want: `1:9: invalid JSONPath "@", expected singular query`},
+		{query: `$[?true]`, want: `1:4: invalid JSONPath "t", expected query`},
+		{query: `$[?!@.a == 1]`, want: `1:9: invalid JSONPath "=", expected , or ]`},
+		{query: `$[?(@.a]`, want: `1:8: invalid JSONPath "]", expected )`},
+		{query: `$[?length(@) > 1]`, want: `1:4: invalid JSONPath "l", expected literal or query`},
+		{query: `$[?@.a == 01]`, want: `1:11: invalid JSONPath "0", expected number`},
+		{query: `$.a[?@ == truex]`, want: `1:11: invalid JSONPath "t", expected literal or query`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			_, err := Compile(tt.query)
+			if !errors.Is(err, ErrSyntax) {
+				t.Fatalf("Compile() error = %v, want ErrSyntax", err)
+			}
+			if err.Error() != tt.want {
+				t.Errorf("Compile() error = %s, want %s", err.Error(), tt.want)
+			}
+		})
+	}
+}
+
+// TestCompileStringLiterals tests the escapes of quoted member names.
+func TestCompileStringLiterals(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{query: `$['it\'s']`, want: "it's"},
+		{query: `$["say \"hi\""]`, want: `say "hi"`},
+		{query: `$['a"b']`, want: `a"b`},
+		{query: `$['\b\f\n\r\t\/\\']`, want: "\b\f\n\r\t/\\"},
+		{query: `$['é😀']`, want: "é😀"},
+		{query: `$['\u263A']`, want: "☺"},
+		{query: `$['\uD83D\uDE00']`, want: "😀"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			path, err := Compile(tt.query)
+			if err != nil {
+				t.Fatalf("Compile() error = %v", err)
+			}
+			want := []segment{{selectors: []selector{nameSelector(tt.want)}}}
+			if !reflect.DeepEqual(path.segments, want) {
+				t.Errorf("Compile() got = %#v, want %#v", path.segments, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package jsonpath
+
+import (
+	"github.com/onerciller/gojsonp/parser"
+	"github.com/onerciller/gojsonp/token"
+	"strconv"
+)
+
+// expression is a logical expression of a filter selector, evaluated with "@" bound to current.
+type expression interface {
+	test(current, root *parser.AstNode) bool
+}
+
+// orExpression holds if any of its operands holds, as in "@.a || @.b".
+type orExpression []expression
+
+func (e orExpression) test(current, root *parser.AstNode) bool {
+	for _, operand := range e {
+		if operand.test(current, root) {
+			return true
+		}
+	}
+	return false
+}
+
+// andExpression holds if all of its operands hold, as in "@.a && @.b".
+type andExpression []expression
+
+func (e andExpression) test(current, root *parser.AstNode) bool {
+	for _, operand := range e {
+		if !operand.test(current, root) {
+			return false
+		}
+	}
+	return true
+}
+
+// notExpression negates its operand, as in "!@.a" or "!(@.a > 1)".
+type notExpression struct {
+	operand expression
+}
+
+func (e notExpression) test(current, root *parser.AstNode) bool {
+	return !e.operand.test(current, root)
+}
+
+// existenceTest holds if the query selects at least one node, as in "@.isbn".
+type existenceTest struct {
+	query *filterQuery
+}
+
+func (e existenceTest) test(current, root *parser.AstNode) bool {
+	return len(e.query.nodes(current, root)) > 0
+}
+
+// comparison compares two values with one of ==, !=, <, <=, > and >=, as in "@.price < 10".
+type comparison struct {
+	op          string
+	left, right comparable
+}
+
+func (e comparison) test(current, root *parser.AstNode) bool {
+	left := e.left.value(current, root)
+	right := e.right.value(current, root)
+	switch e.op {
+	case "==":
+		return equal(left, right)
+	case "!=":
+		return !equal(left, right)
+	case "<":
+		return less(left, right)
+	case "<=":
+		return less(left, right) || equal(left, right)
+	case ">":
+		return less(right, left)
+	default: // ">="
+		return less(right, left) || equal(left, right)
+	}
+}
+
+// comparable is an operand of a comparison: a literal or a singular query.
+type comparable interface {
+
+	// value returns the operand as a node, or nil if a query selects nothing
+	value(current, root *parser.AstNode) *parser.AstNode
+}
+
+// literal is a string, number, true, false or null written in the query.
+type literal struct {
+	node *parser.AstNode
+}
+
+func (l literal) value(current, root *parser.AstNode) *parser.AstNode {
+	return l.node
+}
+
+// filterQuery is a query relative to the current node ("@") or to the document root ("$") inside a filter.
+type filterQuery struct {
+	relative bool
+	segments []segment
+}
+
+// nodes returns the nodes selected by the query.
+func (q *filterQuery) nodes(current, root *parser.AstNode) []Match {
+	start := root
+	if q.relative {
+		start = current
+	}
+	return evaluate(q.segments, Match{Node: start}, root)
+}
+
+// value returns the node a singular query selects, or nil if it selects nothing.
+func (q *filterQuery) value(current, root *parser.AstNode) *parser.AstNode {
+	matches := q.nodes(current, root)
+	if len(matches) == 0 {
+		return nil
+	}
+	return matches[0].Node
+}
+
+// singular reports whether the query selects at most one node, i.e. it only has name and index selectors.
+// Only singular queries may be compared.
+func (q *filterQuery) singular() bool {
+	for _, seg := range q.segments {
+		if seg.descendant || len(seg.selectors) != 1 {
+			return false
+		}
+		switch seg.selectors[0].(type) {
+		case nameSelector, indexSelector:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// equal compares two values as RFC 9535 defines for "==", see parser.Equal. Two missing values are equal.
+func equal(a, b *parser.AstNode) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return parser.Equal(a, b)
+}
+
+// less orders numbers by value and strings by their Unicode code points; any other values are not ordered.
+func less(a, b *parser.AstNode) bool {
+	if a == nil || b == nil || a.Type != b.Type {
+		return false
+	}
+	switch a.Type {
+	case token.Number:
+		cmp, ok := parser.CompareNumbers(a, b)
+		return ok && cmp < 0
+	case token.String:
+		// UTF-8 preserves the order of code points, so comparing bytes is enough
+		return a.Value.(string) < b.Value.(string)
+	}
+	return false
+}
+
+// literalNumber creates the node for a number literal of a query; it keeps the literal text, so it compares exactly.
+func literalNumber(text string) *parser.AstNode {
+	return &parser.AstNode{Type: token.Number, Value: parser.Number(text)}
+}
+
+// literalString creates the node for a string literal of a query.
+func literalString(s string) *parser.AstNode {
+	return &parser.AstNode{Type: token.String, Value: s}
+}
+
+// literalKeyword creates the node for true, false or null.
+func literalKeyword(word string) *parser.AstNode {
+	if word == "null" {
+		return &parser.AstNode{Type: token.Null}
+	}
+	b, _ := strconv.ParseBool(word)
+	return &parser.AstNode{Type: token.Boolean, Value: b}
+}
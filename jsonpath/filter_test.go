@@ -0,0 +1,157 @@
+package jsonpath
+
+import (
+	"github.com/onerciller/gojsonp/parser"
+	"github.com/onerciller/gojsonp/token"
+	"reflect"
+	"testing"
+)
+
+// TestFilters tests filter expressions, following the examples of RFC 9535, section 2.3.5.3.
+func TestFilters(t *testing.T) {
+	root := parse(t, `{
+  "a": [3, 5, 1, 2, 4, 6,
+        {"b": "j"},
+        {"b": "k"},
+        {"b": {}},
+        {"b": "kilo"}
+       ],
+  "o": {"p": 1, "q": 2, "r": 3, "s": 5, "t": {"u": 6}},
+  "e": "f"
+}`)
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{query: `$.a[?@.b == 'kilo']`, want: []string{"$['a'][9]"}},
+		{query: `$.a[?(@.b == 'kilo')]`, want: []string{"$['a'][9]"}},
+		{query: `$.a[?@>3.5]`, want: []string{"$['a'][1]", "$['a'][4]", "$['a'][5]"}},
+		{query: `$.a[?@.b]`, want: []string{"$['a'][6]", "$['a'][7]", "$['a'][8]", "$['a'][9]"}},
+		{query: `$[?@.*]`, want: []string{"$['a']", "$['o']"}},
+		{query: `$[?@[?@.b]]`, want: []string{"$['a']"}},
+		{query: `$.o[?@<3, ?@<3]`, want: []string{"$['o']['p']", "$['o']['q']", "$['o']['p']", "$['o']['q']"}},
+		{query: `$.a[?@<2 || @.b == "k"]`, want: []string{"$['a'][2]", "$['a'][7]"}},
+		{query: `$.a[?@.b > 'j' && @.b < 'kilo']`, want: []string{"$['a'][7]"}},
+		{query: `$.o[?@>1 && @<4]`, want: []string{"$['o']['q']", "$['o']['r']"}},
+		{query: `$.o[?@.u || @.x]`, want: []string{"$['o']['t']"}},
+		{query: `$.a[?@.b == $.x]`, want: []string{"$['a'][0]", "$['a'][1]", "$['a'][2]", "$['a'][3]", "$['a'][4]", "$['a'][5]"}},
+		{query: `$.a[?@ == @]`, want: []string{
+			"$['a'][0]", "$['a'][1]", "$['a'][2]", "$['a'][3]", "$['a'][4]",
+			"$['a'][5]", "$['a'][6]", "$['a'][7]", "$['a'][8]", "$['a'][9]",
+		}},
+		{query: `$.a[?!@.b]`, want: []string{"$['a'][0]", "$['a'][1]", "$['a'][2]", "$['a'][3]", "$['a'][4]", "$['a'][5]"}},
+		{query: `$.a[?!(@ <= 4 || @.b)]`, want: []string{"$['a'][1]", "$['a'][5]"}},
+		{query: `$[?@ == $.e]`, want: []string{"$['e']"}},
+		{query: `$.o.t[?@ == 6.0]`, want: []string{"$['o']['t']['u']"}},
+		{query: `$.a[?@.b != 'j']`, want: []string{
+			"$['a'][0]", "$['a'][1]", "$['a'][2]", "$['a'][3]", "$['a'][4]",
+			"$['a'][5]", "$['a'][7]", "$['a'][8]", "$['a'][9]",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			matches, err := Query(root, tt.query)
+			if err != nil {
+				t.Fatalf("Query() error = %v", err)
+			}
+			if got := paths(matches); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Query() got = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestComparisons tests the comparison rules for values of different types.
+func TestComparisons(t *testing.T) {
+	root := parse(t, `[{"v": 1}, {"v": "1"}, {"v": true}, {"v": null}, {"v": [1, {"a": 2}]}, {"v": {"a": [2], "b": 1}}, {}]`)
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{query: `$[?@.v == 1]`, want: []string{"$[0]"}},
+		{query: `$[?@.v == 1e0]`, want: []string{"$[0]"}},
+		{query: `$[?@.v == '1']`, want: []string{"$[1]"}},
+		{query: `$[?@.v == true]`, want: []string{"$[2]"}},
+		{query: `$[?@.v == null]`, want: []string{"$[3]"}},
+		{query: `$[?@.v == $[4].v]`, want: []string{"$[4]"}},
+		{query: `$[?@.v == $[5].v]`, want: []string{"$[5]"}},
+		{query: `$[?@.v <= true]`, want: []string{"$[2]"}},
+		{query: `$[?@.v < true]`, want: nil},
+		{query: `$[?@.v >= 1]`, want: []string{"$[0]"}},
+		{query: `$[?@.v == @.missing]`, want: []string{"$[6]"}},
+		{query: `$[?@.v != null]`, want: []string{"$[0]", "$[1]", "$[2]", "$[4]", "$[5]", "$[6]"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if got := paths(MustCompile(tt.query).Query(root)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Query() got = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestComparisonsNumberModes tests that numbers compare by value in every NumberMode.
+func TestComparisonsNumberModes(t *testing.T) {
+	input := `[0.1, 9007199254740993, 123456789012345678901234567890, 2.50]`
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{query: `$[?@ == 0.1]`, want: []string{"$[0]"}},
+		{query: `$[?@ == 2.5]`, want: []string{"$[3]"}},
+		{query: `$[?@ > 1e29]`, want: []string{"$[2]"}},
+	}
+
+	// NumberInt64 is left out, it rejects the third number
+	modes := []parser.NumberMode{parser.NumberFloat64, parser.NumberString, parser.NumberBig}
+	for _, mode := range modes {
+		root := parse(t, input, parser.Numbers(mode))
+		for _, tt := range tests {
+			if got := paths(MustCompile(tt.query).Query(root)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mode %d: Query(%s) got = %q, want %q", mode, tt.query, got, tt.want)
+			}
+		}
+	}
+
+	root := parse(t, input, parser.Numbers(parser.NumberString))
+	if got := paths(MustCompile(`$[?@ == 9007199254740993]`).Query(root)); !reflect.DeepEqual(got, []string{"$[1]"}) {
+		t.Errorf("Query() got = %q, want exact integer comparison", got)
+	}
+	if got := paths(MustCompile(`$[?@ == 9007199254740992]`).Query(root)); got != nil {
+		t.Errorf("Query() got = %q, want exact integer comparison", got)
+	}
+}
+
+// TestComparisonsNonFinite tests that the JSON5 values NaN and Infinity compare without panicking:
+// NaN equals nothing and is not ordered, infinities compare by value.
+func TestComparisonsNonFinite(t *testing.T) {
+	tokens, err := token.Tokenizer([]byte(`[NaN, 1, Infinity, -Infinity]`), token.JSON5())
+	if err != nil {
+		t.Fatalf("Tokenizer() error = %v", err)
+	}
+	root, err := parser.Parse(tokens, parser.JSON5())
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{query: `$[?@ == 1]`, want: []string{"$[1]"}},
+		{query: `$[?@ != 1]`, want: []string{"$[0]", "$[2]", "$[3]"}},
+		{query: `$[?@ > 1]`, want: []string{"$[2]"}},
+		{query: `$[?@ < 1]`, want: []string{"$[3]"}},
+		{query: `$[?@ == $[2]]`, want: []string{"$[2]"}},
+		{query: `$[?@ == $[0]]`, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if got := paths(MustCompile(tt.query).Query(root)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Query() got = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,286 @@
+// Package jsonpath implements JSONPath queries (RFC 9535) over parser.AstNode trees.
+// A query such as "$.items[?@.price < 10].name" selects nodes by member names, array indexes,
+// wildcards, slices, recursive descent and filter expressions, and reports each match with its
+// normalized path, e.g. "$['items'][0]['name']". Function extensions such as length() are not supported.
+package jsonpath
+
+import (
+	"github.com/onerciller/gojsonp/parser"
+	"strconv"
+	"strings"
+)
+
+// Path is a compiled JSONPath query; it is safe for concurrent use.
+type Path struct {
+	source   string
+	segments []segment
+}
+
+// Match is a node selected by a query.
+type Match struct {
+
+	// Node is the selected node of the queried document
+	Node *parser.AstNode
+
+	// Path is the normalized path of the node, e.g. "$['store']['book'][0]"
+	Path string
+}
+
+// Compile parses a JSONPath query.
+// Malformed queries are reported as a *token.SyntaxError of kind ErrSyntax, positioned at the offending character.
+// Example: Compile("$.store.book[*].author")
+func Compile(query string) (*Path, error) {
+	c := &compiler{input: query}
+	return c.compile()
+}
+
+// MustCompile is like Compile but panics if the query is malformed.
+// It simplifies the initialization of queries written in the source code.
+func MustCompile(query string) *Path {
+	p, err := Compile(query)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// Query compiles query and runs it against root.
+// Example: Query(root, "$.items[*].price")
+func Query(root *parser.AstNode, query string) ([]Match, error) {
+	p, err := Compile(query)
+	if err != nil {
+		return nil, err
+	}
+	return p.Query(root), nil
+}
+
+// String returns the query the Path was compiled from.
+func (p *Path) String() string {
+	return p.source
+}
+
+// Query runs the query against the document root and returns the matches in document order.
+// A node may appear more than once, e.g. for "$[0, 0]".
+func (p *Path) Query(root *parser.AstNode) []Match {
+	if root == nil {
+		return nil
+	}
+	return evaluate(p.segments, Match{Node: root, Path: "$"}, root)
+}
+
+// Nodes runs the query against the document root and returns only the selected nodes.
+func (p *Path) Nodes(root *parser.AstNode) []*parser.AstNode {
+	matches := p.Query(root)
+	nodes := make([]*parser.AstNode, len(matches))
+	for i, match := range matches {
+		nodes[i] = match.Node
+	}
+	return nodes
+}
+
+// evaluate applies segments in turn, starting from the single node start.
+// root is the document that "$" refers to inside filter expressions.
+func evaluate(segments []segment, start Match, root *parser.AstNode) []Match {
+	matches := []Match{start}
+	for _, seg := range segments {
+		var next []Match
+		for _, match := range matches {
+			next = seg.apply(next, match, root)
+		}
+		matches = next
+	}
+	return matches
+}
+
+// segment is a child segment such as ".name" or "[0, 1]", or a descendant segment such as "..name".
+type segment struct {
+	descendant bool
+	selectors  []selector
+}
+
+// apply appends the nodes the segment selects from match to result.
+func (s segment) apply(result []Match, match Match, root *parser.AstNode) []Match {
+	for _, sel := range s.selectors {
+		result = sel.apply(result, match, root)
+	}
+	if s.descendant {
+		eachChild(match, func(child Match) {
+			result = s.apply(result, child, root)
+		})
+	}
+	return result
+}
+
+// selector picks children of a node.
+type selector interface {
+	apply(result []Match, match Match, root *parser.AstNode) []Match
+}
+
+// nameSelector selects the member with the given name, as in "['name']" or ".name".
+type nameSelector string
+
+func (s nameSelector) apply(result []Match, match Match, root *parser.AstNode) []Match {
+	if object, ok := match.Node.Value.(*parser.Object); ok {
+		if child, ok := object.Get(string(s)); ok {
+			result = append(result, Match{Node: child, Path: match.Path + normalizedName(string(s))})
+		}
+	}
+	return result
+}
+
+// wildcardSelector selects all members of an object or elements of an array, as in "[*]" or ".*".
+type wildcardSelector struct{}
+
+func (wildcardSelector) apply(result []Match, match Match, root *parser.AstNode) []Match {
+	eachChild(match, func(child Match) {
+		result = append(result, child)
+	})
+	return result
+}
+
+// indexSelector selects an array element; negative indexes count from the end, as in "[-1]".
+type indexSelector int
+
+func (s indexSelector) apply(result []Match, match Match, root *parser.AstNode) []Match {
+	elements, ok := match.Node.Value.([]*parser.AstNode)
+	if !ok {
+		return result
+	}
+	index := int(s)
+	if index < 0 {
+		index += len(elements)
+	}
+	if index >= 0 && index < len(elements) {
+		result = append(result, Match{Node: elements[index], Path: match.Path + normalizedIndex(index)})
+	}
+	return result
+}
+
+// sliceSelector selects array elements from start up to but not including end, as in "[1:5:2]".
+// Missing bounds are nil and take defaults that depend on the direction of step.
+type sliceSelector struct {
+	start, end *int
+	step       int
+}
+
+func (s sliceSelector) apply(result []Match, match Match, root *parser.AstNode) []Match {
+	elements, ok := match.Node.Value.([]*parser.AstNode)
+	if !ok || s.step == 0 {
+		return result
+	}
+	n := len(elements)
+	lower, upper := s.bounds(n)
+	if s.step > 0 {
+		for i := lower; i < upper; i += s.step {
+			result = append(result, Match{Node: elements[i], Path: match.Path + normalizedIndex(i)})
+		}
+	} else {
+		for i := upper; lower < i; i += s.step {
+			result = append(result, Match{Node: elements[i], Path: match.Path + normalizedIndex(i)})
+		}
+	}
+	return result
+}
+
+// bounds computes the range of indexes a slice visits in an array of length n, following RFC 9535, section 2.3.4.2.2.
+// For a positive step the range is [lower, upper), for a negative step it is (lower, upper].
+func (s sliceSelector) bounds(n int) (lower, upper int) {
+	normalize := func(i int) int {
+		if i >= 0 {
+			return i
+		}
+		return n + i
+	}
+	clamp := func(i, low, high int) int {
+		if i < low {
+			return low
+		}
+		if i > high {
+			return high
+		}
+		return i
+	}
+
+	start, end := 0, n
+	if s.step < 0 {
+		start, end = n-1, -n-1
+	}
+	if s.start != nil {
+		start = normalize(*s.start)
+	}
+	if s.end != nil {
+		end = normalize(*s.end)
+	}
+	if s.step > 0 {
+		return clamp(start, 0, n), clamp(end, 0, n)
+	}
+	return clamp(end, -1, n-1), clamp(start, -1, n-1)
+}
+
+// filterSelector selects the children for which a logical expression holds, as in "[?@.price < 10]".
+type filterSelector struct {
+	expr expression
+}
+
+func (s filterSelector) apply(result []Match, match Match, root *parser.AstNode) []Match {
+	eachChild(match, func(child Match) {
+		if s.expr.test(child.Node, root) {
+			result = append(result, child)
+		}
+	})
+	return result
+}
+
+// eachChild calls fn for the members of an object or the elements of an array, in order.
+func eachChild(match Match, fn func(child Match)) {
+	switch value := match.Node.Value.(type) {
+	case *parser.Object:
+		for _, member := range value.Members() {
+			fn(Match{Node: member.Value, Path: match.Path + normalizedName(member.Key)})
+		}
+	case []*parser.AstNode:
+		for i, element := range value {
+			fn(Match{Node: element, Path: match.Path + normalizedIndex(i)})
+		}
+	}
+}
+
+// normalizedName returns the normalized path segment for a member name, following RFC 9535, section 2.7.
+// Example: normalizedName("it's") returns `['it\'s']`.
+func normalizedName(name string) string {
+	var sb strings.Builder
+	sb.WriteString("['")
+	for _, r := range name {
+		switch r {
+		case '\'':
+			sb.WriteString(`\'`)
+		case '\\':
+			sb.WriteString(`\\`)
+		case '\b':
+			sb.WriteString(`\b`)
+		case '\f':
+			sb.WriteString(`\f`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\t':
+			sb.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				sb.WriteString(`\u00`)
+				sb.WriteByte("0123456789abcdef"[r>>4])
+				sb.WriteByte("0123456789abcdef"[r&0xf])
+				continue
+			}
+			sb.WriteRune(r)
+		}
+	}
+	sb.WriteString("']")
+	return sb.String()
+}
+
+// normalizedIndex returns the normalized path segment for an array index, e.g. "[2]".
+func normalizedIndex(i int) string {
+	return "[" + strconv.Itoa(i) + "]"
+}
@@ -0,0 +1,199 @@
+package jsonpath
+
+import (
+	"github.com/onerciller/gojsonp/parser"
+	"github.com/onerciller/gojsonp/token"
+	"reflect"
+	"testing"
+)
+
+// bookstore is the example document of RFC 9535, section 1.5.
+const bookstore = `{ "store": {
+    "book": [
+      { "category": "reference",
+        "author": "Nigel Rees",
+        "title": "Sayings of the Century",
+        "price": 8.95
+      },
+      { "category": "fiction",
+        "author": "Evelyn Waugh",
+        "title": "Sword of Honour",
+        "price": 12.99
+      },
+      { "category": "fiction",
+        "author": "Herman Melville",
+        "title": "Moby Dick",
+        "isbn": "0-553-21311-3",
+        "price": 8.99
+      },
+      { "category": "fiction",
+        "author": "J. R. R. Tolkien",
+        "title": "The Lord of the Rings",
+        "isbn": "0-395-19395-8",
+        "price": 22.99
+      }
+    ],
+    "bicycle": {
+      "color": "red",
+      "price": 399
+    }
+  }
+}`
+
+// TestQueryBookstore tests the example queries of RFC 9535, section 1.5, by their normalized paths.
+func TestQueryBookstore(t *testing.T) {
+	root := parse(t, bookstore)
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{query: `$.store.book[*].author`, want: []string{
+			"$['store']['book'][0]['author']", "$['store']['book'][1]['author']",
+			"$['store']['book'][2]['author']", "$['store']['book'][3]['author']",
+		}},
+		{query: `$..author`, want: []string{
+			"$['store']['book'][0]['author']", "$['store']['book'][1]['author']",
+			"$['store']['book'][2]['author']", "$['store']['book'][3]['author']",
+		}},
+		{query: `$.store.*`, want: []string{"$['store']['book']", "$['store']['bicycle']"}},
+		{query: `$.store..price`, want: []string{
+			"$['store']['book'][0]['price']", "$['store']['book'][1]['price']",
+			"$['store']['book'][2]['price']", "$['store']['book'][3]['price']",
+			"$['store']['bicycle']['price']",
+		}},
+		{query: `$..book[2]`, want: []string{"$['store']['book'][2]"}},
+		{query: `$..book[-1]`, want: []string{"$['store']['book'][3]"}},
+		{query: `$..book[0,1]`, want: []string{"$['store']['book'][0]", "$['store']['book'][1]"}},
+		{query: `$..book[:2]`, want: []string{"$['store']['book'][0]", "$['store']['book'][1]"}},
+		{query: `$..book[?@.isbn]`, want: []string{"$['store']['book'][2]", "$['store']['book'][3]"}},
+		{query: `$..book[?@.price<10]`, want: []string{"$['store']['book'][0]", "$['store']['book'][2]"}},
+		{query: `$["store"]['bicycle'].color`, want: []string{"$['store']['bicycle']['color']"}},
+		{query: `$.store.missing`, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			matches, err := Query(root, tt.query)
+			if err != nil {
+				t.Fatalf("Query() error = %v", err)
+			}
+			if got := paths(matches); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Query() got = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestQueryNodes tests that matches hold the nodes of the queried document.
+func TestQueryNodes(t *testing.T) {
+	root := parse(t, bookstore)
+	nodes := MustCompile(`$..book[?@.price > 20].title`).Nodes(root)
+	if len(nodes) != 1 || nodes[0].Value != "The Lord of the Rings" {
+		t.Fatalf("Nodes() got = %v", nodes)
+	}
+	if nodes[0].Start.Line != 21 {
+		t.Errorf("Nodes() got a node at line %d, want 21", nodes[0].Start.Line)
+	}
+
+	all := MustCompile(`$..*`).Query(root)
+	if len(all) != 27 {
+		t.Errorf("Query($..*) got %d nodes, want 27", len(all))
+	}
+}
+
+// TestQuerySlices tests array slices with positive, negative and default bounds and steps.
+func TestQuerySlices(t *testing.T) {
+	root := parse(t, `["a", "b", "c", "d", "e", "f", "g"]`)
+	tests := []struct {
+		query string
+		want  []interface{}
+	}{
+		{query: `$[1:3]`, want: []interface{}{"b", "c"}},
+		{query: `$[5:]`, want: []interface{}{"f", "g"}},
+		{query: `$[1:5:2]`, want: []interface{}{"b", "d"}},
+		{query: `$[5:1:-2]`, want: []interface{}{"f", "d"}},
+		{query: `$[::-1]`, want: []interface{}{"g", "f", "e", "d", "c", "b", "a"}},
+		{query: `$[-2:]`, want: []interface{}{"f", "g"}},
+		{query: `$[:-5]`, want: []interface{}{"a", "b"}},
+		{query: `$[-100:100:3]`, want: []interface{}{"a", "d", "g"}},
+		{query: `$[::0]`, want: nil},
+		{query: `$[3:1]`, want: nil},
+		{query: `$[ 0 , -1 ]`, want: []interface{}{"a", "g"}},
+		{query: `$[7]`, want: nil},
+		{query: `$[-8]`, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			var got []interface{}
+			for _, node := range MustCompile(tt.query).Nodes(root) {
+				got = append(got, node.Value)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Nodes() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestQueryDescendants tests the order of descendant segments and that duplicates are kept.
+func TestQueryDescendants(t *testing.T) {
+	root := parse(t, `{"o": {"j": 1, "k": 2}, "a": [5, 3, [{"j": 4}, {"k": 6}]]}`)
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{query: `$..j`, want: []string{"$['o']['j']", "$['a'][2][0]['j']"}},
+		{query: `$..[0]`, want: []string{"$['a'][0]", "$['a'][2][0]"}},
+		{query: `$.a[0, 0]`, want: []string{"$['a'][0]", "$['a'][0]"}},
+		{query: `$..[?@ == 3]`, want: []string{"$['a'][1]"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if got := paths(MustCompile(tt.query).Query(root)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Query() got = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestNormalizedName tests the escaping of member names in normalized paths.
+func TestNormalizedName(t *testing.T) {
+	tests := map[string]string{
+		"a":         `['a']`,
+		"it's":      `['it\'s']`,
+		`a\b`:       `['a\\b']`,
+		"tab\tnl\n": `['tab\tnl\n']`,
+		"\x01":      `['\u0001']`,
+		"é\"":       `['é"']`,
+	}
+	for name, want := range tests {
+		if got := normalizedName(name); got != want {
+			t.Errorf("normalizedName(%q) = %s, want %s", name, got, want)
+		}
+	}
+}
+
+// paths returns the normalized paths of matches.
+func paths(matches []Match) []string {
+	var result []string
+	for _, match := range matches {
+		result = append(result, match.Path)
+	}
+	return result
+}
+
+// parse tokenizes and parses input and fails the test on error.
+func parse(t *testing.T, input string, opts ...parser.Option) *parser.AstNode {
+	t.Helper()
+	tokens, err := token.Tokenizer([]byte(input))
+	if err != nil {
+		t.Fatalf("Tokenizer() error = %v", err)
+	}
+	root, err := parser.Parse(tokens, opts...)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	return root
+}
@@ -0,0 +1,96 @@
+package gojsonp
+
+import (
+	"github.com/onerciller/gojsonp/parser"
+	"github.com/onerciller/gojsonp/token"
+)
+
+// Option configures Decode, DecodeJson and Decoder.
+// Example: Decode(data, OrderedObjects())
+type Option func(*decodeOptions)
+
+// decodeOptions collects the options for the packages doing the work.
+type decodeOptions struct {
+	token  []token.Option
+	parser []parser.Option
+}
+
+// newDecodeOptions applies opts.
+func newDecodeOptions(opts []Option) decodeOptions {
+	var options decodeOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// OrderedObjects makes objects decode to *parser.OrderedMap, keeping their keys in source order.
+// DecodeJson still returns the top-level object as a map; use Decode to keep its order too.
+func OrderedObjects() Option {
+	return func(o *decodeOptions) {
+		o.parser = append(o.parser, parser.OrderedObjects())
+	}
+}
+
+// DuplicateKeys sets the policy for keys that repeat within an object, e.g. parser.DuplicateReject.
+// The default keeps the last value.
+func DuplicateKeys(policy parser.DuplicatePolicy) Option {
+	return func(o *decodeOptions) {
+		o.parser = append(o.parser, parser.DuplicateKeys(policy))
+	}
+}
+
+// Numbers sets the Go representation of numbers, e.g. parser.NumberString to keep their literal text.
+// The default decodes numbers as float64.
+func Numbers(mode parser.NumberMode) Option {
+	return func(o *decodeOptions) {
+		o.parser = append(o.parser, parser.Numbers(mode))
+	}
+}
+
+// JSON5 accepts the relaxed JSON5 syntax, e.g. for configuration files edited by hand:
+// comments, trailing commas, unquoted keys, single-quoted and multi-line strings, hexadecimal numbers,
+// Infinity and NaN. Strict JSON is the default.
+// Example: Decode([]byte(`{name: 'gojsonp', tags: ['json5',],}`), JSON5())
+func JSON5() Option {
+	return func(o *decodeOptions) {
+		o.token = append(o.token, token.JSON5())
+		o.parser = append(o.parser, parser.JSON5())
+	}
+}
+
+// JSONC accepts "//" and "/* */" comments in otherwise strict JSON, as in VS Code settings files.
+// Example: Decode([]byte("{\"tabSize\": 4 // spaces\n}"), JSONC())
+func JSONC() Option {
+	return func(o *decodeOptions) {
+		o.token = append(o.token, token.JSONC())
+	}
+}
+
+// AnyEncoding accepts input encoded in UTF-16 or UTF-32 as well as UTF-8, as sent by some Windows programs;
+// see token.AnyEncoding. Use token.DetectEncoding to find out which encoding the input has.
+// Example: Decode(data, AnyEncoding())
+func AnyEncoding() Option {
+	return func(o *decodeOptions) {
+		o.token = append(o.token, token.AnyEncoding())
+	}
+}
+
+// ReplaceInvalidUTF8 replaces invalid UTF-8 in strings with U+FFFD instead of reporting token.ErrInvalidUTF8.
+// Example: Decode(data, ReplaceInvalidUTF8())
+func ReplaceInvalidUTF8() Option {
+	return func(o *decodeOptions) {
+		o.token = append(o.token, token.ReplaceInvalidUTF8())
+	}
+}
+
+// Limits bounds the resources spent on untrusted input, such as a request body; see token.Limits.
+// Input exceeding a limit is reported as a *LimitError: errors.Is(err, token.ErrMaxInputBytes) suits
+// 413 Payload Too Large, while the other kinds and a *SyntaxError suit 400 Bad Request.
+// Example: Decode(body, Limits(token.Limits{MaxDepth: 64, MaxInputBytes: 1 << 20}))
+func Limits(limits token.Limits) Option {
+	return func(o *decodeOptions) {
+		o.token = append(o.token, token.WithLimits(limits))
+		o.parser = append(o.parser, parser.Limits(limits))
+	}
+}
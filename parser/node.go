@@ -0,0 +1,177 @@
+package parser
+
+import (
+	"github.com/onerciller/gojsonp/token"
+	"math"
+	"math/big"
+	"strings"
+)
+
+// Clone returns a deep copy of the node, so the copy can be modified without affecting n.
+// Positions are copied as well.
+func (n *AstNode) Clone() *AstNode {
+	if n == nil {
+		return nil
+	}
+	clone := *n
+	switch value := n.Value.(type) {
+	case *Object:
+		members := make([]*Member, value.Len())
+		for i, member := range value.Members() {
+			copied := *member
+			copied.Value = member.Value.Clone()
+			members[i] = &copied
+		}
+		clone.Value = NewObject(members)
+	case []*AstNode:
+		elements := make([]*AstNode, len(value))
+		for i, element := range value {
+			elements[i] = element.Clone()
+		}
+		clone.Value = elements
+	}
+	return &clone
+}
+
+// Equal reports whether two nodes represent the same JSON value, ignoring positions:
+// numbers are compared by value in any NumberMode, arrays element by element
+// and objects member by member regardless of their order.
+// Example: `{"a": [1, 2.0]}` equals `{"a": [1.0, 2]}`.
+func Equal(a, b *AstNode) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.Type != b.Type {
+		return false
+	}
+	switch a.Type {
+	case token.Number:
+		cmp, ok := CompareNumbers(a, b)
+		return ok && cmp == 0
+	case token.Array:
+		x, y := a.Value.([]*AstNode), b.Value.([]*AstNode)
+		if len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !Equal(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	case token.Object:
+		x, y := a.Value.(*Object), b.Value.(*Object)
+		if x.Len() != y.Len() {
+			return false
+		}
+		for _, member := range x.Members() {
+			other, ok := y.Get(member.Key)
+			if !ok || !Equal(member.Value, other) {
+				return false
+			}
+		}
+		return true
+	default:
+		return a.Value == b.Value
+	}
+}
+
+// CompareNumbers compares two Number nodes in any NumberMode, returning -1, 0 or +1,
+// and false if either node does not hold a number.
+// Numbers are compared at the precision of the less precise one: if either is a float64, both are
+// compared as float64, so 0.1 decoded as float64 equals the Number "0.1", and a *big.Float
+// rounds the other number to its precision. Integers and Number text are otherwise compared exactly.
+// The JSON5 values NaN, Infinity and -Infinity are compared as float64 as well: an infinity is
+// greater or less than every finite number, and NaN is not ordered, so comparing it reports false.
+func CompareNumbers(a, b *AstNode) (int, bool) {
+	if fa, nonFiniteA := nonFinite(a.Value); nonFiniteA {
+		return compareNonFinite(fa, b.Value, 1)
+	}
+	if fb, nonFiniteB := nonFinite(b.Value); nonFiniteB {
+		return compareNonFinite(fb, a.Value, -1)
+	}
+
+	x, okX := bigNumber(a.Value)
+	y, okY := bigNumber(b.Value)
+	if !okX || !okY {
+		return 0, false
+	}
+	_, floatA := a.Value.(float64)
+	_, floatB := b.Value.(float64)
+	if floatA || floatB {
+		fx, _ := x.Float64()
+		fy, _ := y.Float64()
+		return big.NewFloat(fx).Cmp(big.NewFloat(fy)), true
+	}
+
+	_, bigA := a.Value.(*big.Float)
+	_, bigB := b.Value.(*big.Float)
+	if bigA || bigB {
+		prec := x.Prec()
+		if !bigA || (bigB && y.Prec() < prec) {
+			prec = y.Prec()
+		}
+		x = new(big.Float).SetPrec(prec).Set(x)
+		y = new(big.Float).SetPrec(prec).Set(y)
+	}
+	return x.Cmp(y), true
+}
+
+// compareNonFinite compares the NaN or infinity f with the number other, returning the result multiplied by sign,
+// so that -1 gives the comparison of other with f.
+func compareNonFinite(f float64, other interface{}, sign int) (int, bool) {
+	g, nonFiniteOther := nonFinite(other)
+	if !nonFiniteOther {
+		n, ok := bigNumber(other)
+		if !ok {
+			return 0, false
+		}
+		g, _ = n.Float64()
+	}
+	switch {
+	case math.IsNaN(f) || math.IsNaN(g):
+		return 0, false
+	case f < g:
+		return -sign, true
+	case f > g:
+		return sign, true
+	}
+	return 0, true
+}
+
+// nonFinite returns the value of a NaN or infinity, which JSON5 allows and big.Float cannot hold:
+// a float64, or with NumberString the literal text, e.g. Number("-Infinity").
+func nonFinite(value interface{}) (float64, bool) {
+	switch n := value.(type) {
+	case float64:
+		return n, math.IsNaN(n) || math.IsInf(n, 0)
+	case Number:
+		switch strings.TrimPrefix(string(n), "+") {
+		case "NaN", "-NaN":
+			return math.NaN(), true
+		case "Infinity":
+			return math.Inf(1), true
+		case "-Infinity":
+			return math.Inf(-1), true
+		}
+	}
+	return 0, false
+}
+
+// bigNumber converts the value of a Number node to a big.Float without losing precision.
+func bigNumber(value interface{}) (*big.Float, bool) {
+	switch n := value.(type) {
+	case float64:
+		return new(big.Float).SetFloat64(n), true
+	case int64:
+		return new(big.Float).SetInt64(n), true
+	case *big.Int:
+		return new(big.Float).SetInt(n), true
+	case *big.Float:
+		return n, true
+	case Number:
+		f, _, err := big.ParseFloat(string(n), 10, floatPrec(string(n)), big.ToNearestEven)
+		return f, err == nil
+	}
+	return nil, false
+}
@@ -0,0 +1,126 @@
+package parser
+
+import (
+	"github.com/onerciller/gojsonp/token"
+	"testing"
+)
+
+// TestClone tests that a clone is equal to its original and shares no containers with it.
+func TestClone(t *testing.T) {
+	original, err := Parse(tokenize(t, `{"a": [1, {"b": null}], "c": "d"}`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	clone := original.Clone()
+	if !Equal(clone, original) || clone.Start != original.Start || clone.End != original.End {
+		t.Fatalf("Clone() is not equal to the original")
+	}
+
+	list, _ := clone.Value.(*Object).Get("a")
+	list.Value = append(list.Value.([]*AstNode), &AstNode{Type: token.Number, Value: float64(2)})
+	inner := list.Value.([]*AstNode)[1].Value.(*Object)
+	inner.Set("b", &AstNode{Type: token.Boolean, Value: true})
+	clone.Value.(*Object).Delete("c")
+
+	want, _ := Parse(tokenize(t, `{"a": [1, {"b": null}], "c": "d"}`))
+	if !Equal(original, want) {
+		t.Errorf("changing the clone changed the original: %v", NodeToValue(original))
+	}
+	if (*AstNode)(nil).Clone() != nil {
+		t.Errorf("Clone() of nil should be nil")
+	}
+}
+
+// TestEqual tests the comparison of nodes by value.
+func TestEqual(t *testing.T) {
+	tests := []struct {
+		a, b string
+		opts []Option
+		want bool
+	}{
+		{a: `1`, b: `1.0`, want: true},
+		{a: `1`, b: `1e0`, opts: []Option{Numbers(NumberString)}, want: true},
+		{a: `100`, b: `1e2`, opts: []Option{Numbers(NumberBig)}, want: true},
+		{a: `1`, b: `2`, want: false},
+		{a: `1`, b: `"1"`, want: false},
+		{a: `"a"`, b: `"a"`, want: true},
+		{a: `null`, b: `false`, want: false},
+		{a: `[1, [2]]`, b: `[1, [2]]`, want: true},
+		{a: `[1, 2]`, b: `[2, 1]`, want: false},
+		{a: `[1]`, b: `[1, 1]`, want: false},
+		{a: `{"a": 1, "b": [true]}`, b: `{"b": [true], "a": 1}`, want: true},
+		{a: `{"a": 1}`, b: `{"a": 1, "b": 2}`, want: false},
+		{a: `{"a": 1}`, b: `{"b": 1}`, want: false},
+		{a: `{}`, b: `[]`, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+" "+tt.b, func(t *testing.T) {
+			a, _ := Parse(tokenize(t, tt.a), tt.opts...)
+			b, _ := Parse(tokenize(t, tt.b), tt.opts...)
+			if got := Equal(a, b); got != tt.want {
+				t.Errorf("Equal() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestCompareNumbers tests that numbers in different NumberModes compare by value.
+func TestCompareNumbers(t *testing.T) {
+	parse := func(input string, mode NumberMode) *AstNode {
+		node, err := Parse(tokenize(t, input), Numbers(mode))
+		if err != nil {
+			t.Fatalf("Parse() error = %v", err)
+		}
+		return node
+	}
+
+	if cmp, ok := CompareNumbers(parse(`0.1`, NumberFloat64), parse(`0.1`, NumberString)); !ok || cmp != 0 {
+		t.Errorf("float64 0.1 should equal the Number 0.1, got %d", cmp)
+	}
+	if cmp, _ := CompareNumbers(parse(`9007199254740993`, NumberInt64), parse(`9007199254740992`, NumberString)); cmp != 1 {
+		t.Errorf("integers should compare exactly, got %d", cmp)
+	}
+	if cmp, _ := CompareNumbers(parse(`-2.5`, NumberBig), parse(`2`, NumberBig)); cmp != -1 {
+		t.Errorf("-2.5 should be less than 2, got %d", cmp)
+	}
+	if _, ok := CompareNumbers(parse(`1`, NumberFloat64), parse(`"1"`, NumberFloat64)); ok {
+		t.Errorf("a string is not a number")
+	}
+}
+
+// TestCompareNumbersNonFinite tests that the JSON5 values NaN and Infinity compare without panicking:
+// infinities are ordered, NaN is not, in every NumberMode.
+func TestCompareNumbersNonFinite(t *testing.T) {
+	for _, mode := range []NumberMode{NumberFloat64, NumberString, NumberBig} {
+		tokens, err := token.Tokenizer([]byte(`[NaN, Infinity, -Infinity, 1e308, 1]`), token.JSON5())
+		if err != nil {
+			t.Fatalf("Tokenizer() error = %v", err)
+		}
+		root, err := Parse(tokens, JSON5(), Numbers(mode))
+		if err != nil {
+			t.Fatalf("Parse() error = %v", err)
+		}
+		values := root.Value.([]*AstNode)
+		nan, inf, negInf, large, one := values[0], values[1], values[2], values[3], values[4]
+
+		if _, ok := CompareNumbers(nan, one); ok {
+			t.Errorf("mode %d: NaN should not be ordered", mode)
+		}
+		if _, ok := CompareNumbers(one, nan); ok {
+			t.Errorf("mode %d: NaN should not be ordered", mode)
+		}
+		if Equal(nan, nan) {
+			t.Errorf("mode %d: NaN should not equal itself", mode)
+		}
+		if cmp, ok := CompareNumbers(inf, large); !ok || cmp != 1 {
+			t.Errorf("mode %d: Infinity should be greater than 1e308, got %d", mode, cmp)
+		}
+		if cmp, ok := CompareNumbers(one, negInf); !ok || cmp != 1 {
+			t.Errorf("mode %d: 1 should be greater than -Infinity, got %d", mode, cmp)
+		}
+		if !Equal(inf, inf) || Equal(inf, negInf) {
+			t.Errorf("mode %d: Infinity should equal only itself", mode)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package parser
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// ErrNumberRange is the kind of the SyntaxError reported for a number that does not fit the selected NumberMode.
+var ErrNumberRange = errors.New("number out of range")
+
+// Number is the literal text of a JSON number, kept as written in the input.
+// It is produced by the NumberString mode and lets callers tell `1` from `1.0` or keep large IDs intact.
+type Number string
+
+// String returns the literal text of the number.
+func (n Number) String() string {
+	return string(n)
+}
+
+// Float64 returns the number as a float64.
+func (n Number) Float64() (float64, error) {
+	return strconv.ParseFloat(string(n), 64)
+}
+
+// Int64 returns the number as an int64; it fails for fractions, exponents and values out of range.
+func (n Number) Int64() (int64, error) {
+	return strconv.ParseInt(string(n), 10, 64)
+}
+
+// NumberMode decides which Go value a Number token is turned into.
+type NumberMode int
+
+const (
+	// NumberFloat64 turns every number into a float64
+	NumberFloat64 NumberMode = iota
+
+	// NumberInt64 turns integers into int64 and numbers with a fraction or exponent into float64
+	NumberInt64
+
+	// NumberString keeps the literal text of every number as a Number
+	NumberString
+
+	// NumberBig turns integers into *big.Int and numbers with a fraction or exponent into *big.Float
+	NumberBig
+)
+
+// isIntegerLiteral checks if a number literal is written without a fraction or exponent.
+// Example: "-12" is an integer literal, "1.0" and "1e3" are not.
+func isIntegerLiteral(literal string) bool {
+	return !strings.ContainsAny(literal, ".eE")
+}
+
+// numberValue converts a valid number literal to the Go value selected by mode.
+// It returns ErrNumberRange if the value does not fit, e.g. 1e400 as a float64.
+func numberValue(literal string, mode NumberMode) (interface{}, error) {
+	switch mode {
+	case NumberString:
+		return Number(literal), nil
+	case NumberInt64:
+		if isIntegerLiteral(literal) {
+			n, err := strconv.ParseInt(literal, 10, 64)
+			if err != nil {
+				return nil, ErrNumberRange
+			}
+			return n, nil
+		}
+	case NumberBig:
+		if isIntegerLiteral(literal) {
+			n, _ := new(big.Int).SetString(literal, 10)
+			return n, nil
+		}
+		f, _, err := big.ParseFloat(literal, 10, floatPrec(literal), big.ToNearestEven)
+		if err != nil {
+			return nil, ErrNumberRange
+		}
+		return f, nil
+	}
+
+	f, err := strconv.ParseFloat(literal, 64)
+	if err != nil {
+		return nil, ErrNumberRange
+	}
+	return f, nil
+}
+
+// json5NumberValue converts a JSON5 number literal that is not valid JSON, e.g. "0x1F", "+.5" or "-Infinity".
+// Finite numbers are rewritten as the equivalent JSON literal and converted like numberValue,
+// so NumberString gives Number("31") for 0x1F. Infinity and NaN are float64 values in every mode
+// but NumberString, which keeps their literal text.
+func json5NumberValue(literal string, mode NumberMode) (interface{}, error) {
+	sign, digits := "", literal
+	switch literal[0] {
+	case '+':
+		digits = literal[1:]
+	case '-':
+		sign, digits = "-", literal[1:]
+	}
+
+	switch {
+	case digits == "Infinity" || digits == "NaN":
+		if mode == NumberString {
+			return Number(literal), nil
+		}
+		if digits == "NaN" {
+			return math.NaN(), nil
+		}
+		if sign == "-" {
+			return math.Inf(-1), nil
+		}
+		return math.Inf(1), nil
+	case len(digits) > 1 && (digits[1] == 'x' || digits[1] == 'X'):
+		n, _ := new(big.Int).SetString(digits[2:], 16)
+		digits = n.String()
+	default:
+		// ".5" becomes "0.5" and "5." becomes "5.0"
+		if digits[0] == '.' {
+			digits = "0" + digits
+		}
+		if i := strings.IndexByte(digits, '.'); i >= 0 && (i == len(digits)-1 || !isDigit(digits[i+1])) {
+			digits = digits[:i+1] + "0" + digits[i+1:]
+		}
+	}
+	return numberValue(sign+digits, mode)
+}
+
+// isDigit checks if a byte is a decimal digit.
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// floatPrec returns a big.Float precision that keeps every digit of a number literal:
+// about 3.3 bits per decimal digit, so four bits per byte of the literal, and at least 64.
+func floatPrec(literal string) uint {
+	prec := uint(len(literal)) * 4
+	if prec < 64 {
+		prec = 64
+	}
+	return prec
+}
@@ -0,0 +1,132 @@
+package parser
+
+import (
+	"errors"
+	"github.com/onerciller/gojsonp/token"
+	"math"
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+// TestNumberModes tests how each NumberMode turns number literals into Go values.
+func TestNumberModes(t *testing.T) {
+	bigInt, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	tests := []struct {
+		name    string
+		input   string
+		mode    NumberMode
+		want    interface{}
+		wantErr error
+	}{
+		{name: "Float64", input: `9007199254740993`, mode: NumberFloat64, want: float64(9007199254740992)},
+		{name: "Float64 out of range", input: `1e400`, mode: NumberFloat64, wantErr: ErrNumberRange},
+		{name: "Int64", input: `9007199254740993`, mode: NumberInt64, want: int64(9007199254740993)},
+		{name: "Int64 negative", input: `-12`, mode: NumberInt64, want: int64(-12)},
+		{name: "Int64 fraction", input: `1.0`, mode: NumberInt64, want: float64(1)},
+		{name: "Int64 exponent", input: `1e3`, mode: NumberInt64, want: float64(1000)},
+		{name: "Int64 overflow", input: `9223372036854775808`, mode: NumberInt64, wantErr: ErrNumberRange},
+		{name: "String", input: `1.0`, mode: NumberString, want: Number("1.0")},
+		{name: "String keeps exponent", input: `-2.50E+10`, mode: NumberString, want: Number("-2.50E+10")},
+		{name: "Big integer", input: `123456789012345678901234567890`, mode: NumberBig, want: bigInt},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := AstToValue(tokenize(t, tt.input), Numbers(tt.mode))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("AstToValue() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AstToValue() got = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestNumberBigFloat tests that NumberBig keeps every digit of a fraction.
+func TestNumberBigFloat(t *testing.T) {
+	literal := "3.14159265358979323846264338327950288419716939937510"
+	got, err := AstToValue(tokenize(t, literal), Numbers(NumberBig))
+	if err != nil {
+		t.Fatalf("AstToValue() error = %v", err)
+	}
+	f, ok := got.(*big.Float)
+	if !ok {
+		t.Fatalf("AstToValue() got = %T, want *big.Float", got)
+	}
+	if text := f.Text('f', 50); text != literal {
+		t.Errorf("Text() = %s, want %s", text, literal)
+	}
+}
+
+// TestNodeToValueNumbers tests that numbers parsed with NumberString are converted by NodeToValue.
+func TestNodeToValueNumbers(t *testing.T) {
+	node, err := Parse(tokenize(t, `[1, 2.5, 99999999999999999999]`), Numbers(NumberString))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	want := []interface{}{int64(1), float64(2.5), Number("99999999999999999999")}
+	if got := NodeToValue(node, Numbers(NumberInt64)); !reflect.DeepEqual(got, want) {
+		t.Errorf("NodeToValue() got = %#v, want %#v", got, want)
+	}
+	want = []interface{}{Number("1"), Number("2.5"), Number("99999999999999999999")}
+	if got := NodeToValue(node, Numbers(NumberString)); !reflect.DeepEqual(got, want) {
+		t.Errorf("NodeToValue() got = %#v, want %#v", got, want)
+	}
+}
+
+// TestNumberMethods tests the conversions of the Number type.
+func TestNumberMethods(t *testing.T) {
+	if f, err := Number("2.5e1").Float64(); err != nil || f != 25 {
+		t.Errorf("Float64() = %v, %v", f, err)
+	}
+	if n, err := Number("-9007199254740993").Int64(); err != nil || n != -9007199254740993 {
+		t.Errorf("Int64() = %v, %v", n, err)
+	}
+	if _, err := Number("1.5").Int64(); err == nil {
+		t.Errorf("Int64() of a fraction should fail")
+	}
+}
+
+// TestJSON5Numbers tests that the JSON5 number forms are converted to the value of the equivalent JSON number.
+func TestJSON5Numbers(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		mode  NumberMode
+		want  interface{}
+	}{
+		{name: "Hexadecimal", input: `0x1F`, mode: NumberInt64, want: int64(31)},
+		{name: "Negative hexadecimal", input: `-0xff`, mode: NumberString, want: Number("-255")},
+		{name: "Plus sign", input: `+1`, mode: NumberInt64, want: int64(1)},
+		{name: "Leading point", input: `.5`, mode: NumberString, want: Number("0.5")},
+		{name: "Trailing point", input: `5.`, mode: NumberString, want: Number("5.0")},
+		{name: "Trailing point before exponent", input: `5.e2`, mode: NumberFloat64, want: float64(500)},
+		{name: "Infinity", input: `-Infinity`, mode: NumberFloat64, want: math.Inf(-1)},
+		{name: "Infinity as string", input: `+Infinity`, mode: NumberString, want: Number("+Infinity")},
+		{name: "JSON number", input: `1.50`, mode: NumberString, want: Number("1.50")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := token.Tokenizer([]byte(tt.input), token.JSON5())
+			if err != nil {
+				t.Fatalf("Tokenizer() error = %v", err)
+			}
+			got, err := AstToValue(tokens, JSON5(), Numbers(tt.mode))
+			if err != nil {
+				t.Fatalf("AstToValue() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AstToValue() got = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+
+	tokens, _ := token.Tokenizer([]byte(`NaN`), token.JSON5())
+	if got, err := AstToValue(tokens, JSON5()); err != nil || !math.IsNaN(got.(float64)) {
+		t.Errorf("AstToValue() got = %v, %v, want NaN", got, err)
+	}
+}
@@ -0,0 +1,160 @@
+package parser
+
+// Object is the Value of an Object node.
+// It keeps the members in source order and indexes them by key for lookups.
+// The zero value is an empty Object ready to use.
+type Object struct {
+	members []*Member
+
+	// index maps a key to the position of its member in members
+	index map[string]int
+}
+
+// NewObject creates an Object holding members in the given order.
+// If a key repeats, lookups find the last member with that key.
+func NewObject(members []*Member) *Object {
+	o := &Object{members: members}
+	o.reindex()
+	return o
+}
+
+// Len returns the number of members.
+func (o *Object) Len() int {
+	return len(o.members)
+}
+
+// Members returns the members in source order.
+// The slice must not be modified; use Set and Delete instead.
+func (o *Object) Members() []*Member {
+	return o.members
+}
+
+// Keys returns the keys in source order.
+func (o *Object) Keys() []string {
+	keys := make([]string, len(o.members))
+	for i, member := range o.members {
+		keys[i] = member.Key
+	}
+	return keys
+}
+
+// Get returns the value for key.
+// Example: For the object `{"a": 1}`, Get("a") returns the Number node 1 and true.
+func (o *Object) Get(key string) (*AstNode, bool) {
+	member, ok := o.Member(key)
+	if !ok {
+		return nil, false
+	}
+	return member.Value, true
+}
+
+// Member returns the member for key, including the position of its key.
+func (o *Object) Member(key string) (*Member, bool) {
+	i, ok := o.index[key]
+	if !ok {
+		return nil, false
+	}
+	return o.members[i], true
+}
+
+// Set replaces the value for key in place, or appends a new member if key is not present.
+func (o *Object) Set(key string, value *AstNode) {
+	if i, ok := o.index[key]; ok {
+		o.members[i].Value = value
+		return
+	}
+	if o.index == nil {
+		o.index = map[string]int{}
+	}
+	o.members = append(o.members, &Member{Key: key, Value: value})
+	o.index[key] = len(o.members) - 1
+}
+
+// Delete removes the member for key and reports whether it was present.
+func (o *Object) Delete(key string) bool {
+	if _, ok := o.index[key]; !ok {
+		return false
+	}
+	members := o.members[:0]
+	for _, member := range o.members {
+		if member.Key != key {
+			members = append(members, member)
+		}
+	}
+	o.members = members
+	o.reindex()
+	return true
+}
+
+// reindex rebuilds the index after the members changed.
+func (o *Object) reindex() {
+	o.index = make(map[string]int, len(o.members))
+	for i, member := range o.members {
+		o.index[member.Key] = i
+	}
+}
+
+// OrderedMap is an object decoded into plain Go values that keeps its keys in source order.
+// NodeToValue returns it instead of map[string]interface{} with the OrderedObjects option.
+// The zero value is an empty OrderedMap ready to use.
+type OrderedMap struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+// NewOrderedMap creates an empty OrderedMap.
+func NewOrderedMap() *OrderedMap {
+	return &OrderedMap{values: map[string]interface{}{}}
+}
+
+// Len returns the number of keys.
+func (m *OrderedMap) Len() int {
+	return len(m.keys)
+}
+
+// Keys returns the keys in insertion order.
+// The slice must not be modified; use Set and Delete instead.
+func (m *OrderedMap) Keys() []string {
+	return m.keys
+}
+
+// Get returns the value for key.
+func (m *OrderedMap) Get(key string) (interface{}, bool) {
+	value, ok := m.values[key]
+	return value, ok
+}
+
+// Set replaces the value for key, or appends key if it is not present.
+func (m *OrderedMap) Set(key string, value interface{}) {
+	if m.values == nil {
+		m.values = map[string]interface{}{}
+	}
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+}
+
+// Delete removes key and reports whether it was present.
+func (m *OrderedMap) Delete(key string) bool {
+	if _, ok := m.values[key]; !ok {
+		return false
+	}
+	delete(m.values, key)
+	for i, k := range m.keys {
+		if k == key {
+			m.keys = append(m.keys[:i], m.keys[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+// Map returns the values as a plain map, losing the order of the keys.
+func (m *OrderedMap) Map() map[string]interface{} {
+	result := make(map[string]interface{}, len(m.values))
+	for k, v := range m.values {
+		result[k] = v
+	}
+	return result
+}
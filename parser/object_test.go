@@ -0,0 +1,94 @@
+package parser
+
+import (
+	"github.com/onerciller/gojsonp/token"
+	"reflect"
+	"testing"
+)
+
+// TestObject tests lookups, iteration order and mutation of an Object.
+func TestObject(t *testing.T) {
+	node, err := Parse(tokenize(t, `{"z": 1, "a": 2, "m": 3}`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	object := node.Value.(*Object)
+
+	if keys := object.Keys(); !reflect.DeepEqual(keys, []string{"z", "a", "m"}) {
+		t.Errorf("Keys() = %v, want source order", keys)
+	}
+	if value, ok := object.Get("a"); !ok || value.Value != float64(2) {
+		t.Errorf("Get(a) = %v, %v", value, ok)
+	}
+	if member, ok := object.Member("m"); !ok || member.KeyStart.Column != 18 {
+		t.Errorf("Member(m) = %+v, %v", member, ok)
+	}
+	if _, ok := object.Get("missing"); ok {
+		t.Errorf("Get(missing) found a value")
+	}
+
+	object.Set("a", &AstNode{Type: token.Boolean, Value: true})
+	object.Set("new", &AstNode{Type: token.Null})
+	if !object.Delete("z") || object.Delete("z") {
+		t.Errorf("Delete(z) should succeed exactly once")
+	}
+	if keys := object.Keys(); !reflect.DeepEqual(keys, []string{"a", "m", "new"}) {
+		t.Errorf("Keys() after mutation = %v", keys)
+	}
+	if value, _ := object.Get("a"); value.Value != true {
+		t.Errorf("Get(a) after Set = %v", value)
+	}
+	if value, ok := object.Get("m"); !ok || value.Value != float64(3) {
+		t.Errorf("Get(m) after Delete = %v, %v", value, ok)
+	}
+}
+
+// TestZeroObject tests that the zero values of Object and OrderedMap are empty and can be set.
+func TestZeroObject(t *testing.T) {
+	var object Object
+	if _, ok := object.Get("a"); ok || object.Len() != 0 || object.Delete("a") {
+		t.Errorf("zero Object is not empty")
+	}
+	object.Set("a", &AstNode{Type: token.Null})
+	object.Set("b", &AstNode{Type: token.Boolean, Value: true})
+	if value, ok := object.Get("b"); !ok || value.Value != true || !reflect.DeepEqual(object.Keys(), []string{"a", "b"}) {
+		t.Errorf("Get(b) after Set = %v, %v, keys %v", value, ok, object.Keys())
+	}
+
+	var m OrderedMap
+	if _, ok := m.Get("a"); ok || m.Len() != 0 || m.Delete("a") {
+		t.Errorf("zero OrderedMap is not empty")
+	}
+	m.Set("a", 1)
+	if value, ok := m.Get("a"); !ok || value != 1 || !reflect.DeepEqual(m.Keys(), []string{"a"}) {
+		t.Errorf("Get(a) after Set = %v, %v, keys %v", value, ok, m.Keys())
+	}
+}
+
+// TestOrderedObjects tests that the OrderedObjects option keeps the key order at every level.
+func TestOrderedObjects(t *testing.T) {
+	got, err := AstToValue(tokenize(t, `{"b": 1, "a": [{"y": true, "x": null}]}`), OrderedObjects())
+	if err != nil {
+		t.Fatalf("AstToValue() error = %v", err)
+	}
+
+	outer, ok := got.(*OrderedMap)
+	if !ok || !reflect.DeepEqual(outer.Keys(), []string{"b", "a"}) {
+		t.Fatalf("AstToValue() got = %#v, want *OrderedMap with keys b, a", got)
+	}
+	list, _ := outer.Get("a")
+	inner := list.([]interface{})[0].(*OrderedMap)
+	if !reflect.DeepEqual(inner.Keys(), []string{"y", "x"}) {
+		t.Errorf("inner keys = %v, want y, x", inner.Keys())
+	}
+	if !reflect.DeepEqual(inner.Map(), map[string]interface{}{"y": true, "x": nil}) {
+		t.Errorf("inner values = %v", inner.Map())
+	}
+
+	inner.Set("w", 1)
+	inner.Set("y", false)
+	inner.Delete("x")
+	if !reflect.DeepEqual(inner.Keys(), []string{"y", "w"}) || inner.Len() != 2 {
+		t.Errorf("inner keys after mutation = %v", inner.Keys())
+	}
+}
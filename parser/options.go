@@ -0,0 +1,94 @@
+package parser
+
+import "github.com/onerciller/gojsonp/token"
+
+// Options configures the parser and the conversion of AST nodes to Go values.
+type Options struct {
+
+	// OrderedObjects makes NodeToValue return objects as *OrderedMap instead of map[string]interface{}
+	OrderedObjects bool
+
+	// DuplicateKeys decides what happens when a key repeats within an object
+	DuplicateKeys DuplicatePolicy
+
+	// Numbers decides which Go value numbers are turned into
+	Numbers NumberMode
+
+	// JSON5 accepts trailing commas and JSON5 numbers, as produced by a token.JSON5 Lexer
+	JSON5 bool
+
+	// Limits bounds the nesting depth, object members and array length; see token.Limits
+	Limits token.Limits
+}
+
+// DuplicatePolicy decides what the parser does with a key that repeats within an object.
+type DuplicatePolicy int
+
+const (
+	// DuplicateKeepLast keeps the value of the last occurrence, at the position of the first one
+	DuplicateKeepLast DuplicatePolicy = iota
+
+	// DuplicateKeepFirst keeps the value of the first occurrence and ignores the others
+	DuplicateKeepFirst
+
+	// DuplicateReject fails with a SyntaxError of kind ErrDuplicateKey at the repeated key
+	DuplicateReject
+
+	// DuplicateCollect replaces the value with an array holding the values of all occurrences in order
+	DuplicateCollect
+)
+
+// Option sets a field of Options; pass options to NewParser, Parse or NodeToValue.
+// Example: Parse(tokens, OrderedObjects())
+type Option func(*Options)
+
+// OrderedObjects makes objects decode to *OrderedMap, keeping their keys in source order.
+func OrderedObjects() Option {
+	return func(o *Options) {
+		o.OrderedObjects = true
+	}
+}
+
+// DuplicateKeys sets the policy for keys that repeat within an object; the default is DuplicateKeepLast.
+// Example: Parse(tokens, DuplicateKeys(DuplicateReject))
+func DuplicateKeys(policy DuplicatePolicy) Option {
+	return func(o *Options) {
+		o.DuplicateKeys = policy
+	}
+}
+
+// Numbers sets the Go representation of numbers; the default is NumberFloat64.
+// Example: Parse(tokens, Numbers(NumberString))
+func Numbers(mode NumberMode) Option {
+	return func(o *Options) {
+		o.Numbers = mode
+	}
+}
+
+// JSON5 makes the parser accept the JSON5 syntax: trailing commas in objects and arrays,
+// and numbers such as 0x1F, +1, .5, 5., Infinity and NaN. Tokenize the input with token.JSON5 as well;
+// ParseReader sets this option itself when given token.JSON5.
+// Example: Parse(tokens, JSON5())
+func JSON5() Option {
+	return func(o *Options) {
+		o.JSON5 = true
+	}
+}
+
+// Limits bounds the resources spent on untrusted input: the parser checks MaxDepth, MaxMembers and MaxArrayLength
+// and reports a *token.LimitError. ParseReader takes the limits from token.WithLimits, and its Lexer checks the others.
+// Example: Parse(tokens, Limits(token.Limits{MaxDepth: 64, MaxMembers: 1000}))
+func Limits(limits token.Limits) Option {
+	return func(o *Options) {
+		o.Limits = limits
+	}
+}
+
+// newOptions applies opts to the default Options.
+func newOptions(opts []Option) Options {
+	var options Options
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
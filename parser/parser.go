@@ -1,102 +1,476 @@
 package parser
 
 import (
+	"errors"
 	"fmt"
 	"github.com/onerciller/gojsonp/token"
+	"io"
 	"strconv"
+	"strings"
 )
 
+// ErrDuplicateKey is the kind of the SyntaxError reported for a repeated key with the DuplicateReject policy.
+var ErrDuplicateKey = errors.New("duplicate key")
+
 // AstNode struct to represent an AST node.
+// Scalar nodes hold their Go value (string, a number as selected by NumberMode, bool or nil),
+// Object nodes hold an *Object and Array nodes hold a []*AstNode.
 type AstNode struct {
 	Type  token.Type
 	Value interface{}
+
+	// Start and End delimit the source text of the node, e.g. from '{' to the matching '}'
+	Start token.Position
+	End   token.Position
 }
 
-// Parser function to iterate over tokens and generate AST nodes.
-func parser(tokens []token.Token) ([]*AstNode, error) {
-	var ast []*AstNode
+// Member represents a single key-value pair of an Object node.
+// Members are kept in the order they appear in the source.
+type Member struct {
+	Key   string
+	Value *AstNode
 
-	for _, tk := range tokens {
-		astNode, err := parseValue(tk)
-		if err != nil {
-			return nil, err
-		}
-		if astNode != nil {
-			ast = append(ast, astNode)
-		}
+	// KeyStart and KeyEnd delimit the source text of the quoted key
+	KeyStart token.Position
+	KeyEnd   token.Position
+}
+
+// Parser is a recursive-descent parser that turns tokens into an AST.
+// It follows the JSON grammar:
+//
+//	value  = object | array | STRING | NUMBER | BOOLEAN | NULL
+//	object = "{" [ member { "," member } ] "}"
+//	member = STRING ":" value
+//	array  = "[" [ value { "," value } ] "]"
+//
+// With the JSON5 option, objects and arrays may end with a trailing comma.
+type Parser struct {
+	source  TokenSource
+	options Options
+
+	// lookahead is the token returned by peek and not yet consumed by next
+	lookahead *token.Token
+
+	// err is the first error returned by the source
+	err error
+
+	// failed is the error returned by ParseNext, after which the sequence of values ends
+	failed error
+
+	// depth is the number of objects and arrays being parsed, for Limits.MaxDepth
+	depth int
+}
+
+// TokenSource yields tokens one at a time; *token.Lexer implements it.
+// After the last token a TokenSource keeps returning EOF tokens.
+type TokenSource interface {
+	Next() (token.Token, error)
+}
+
+// tokenSlice is a TokenSource over tokens that were already read, e.g. by token.Tokenizer.
+type tokenSlice struct {
+	tokens  []token.Token
+	current int
+}
+
+// Next returns the next token of the slice, or an EOF token past its end.
+func (s *tokenSlice) Next() (token.Token, error) {
+	if s.current >= len(s.tokens) {
+		return token.Token{Type: token.EOF}, nil
+	}
+	s.current++
+	return s.tokens[s.current-1], nil
+}
+
+// NewParser creates a parser over the given tokens.
+func NewParser(tokens []token.Token, opts ...Option) *Parser {
+	return NewStreamParser(&tokenSlice{tokens: tokens}, opts...)
+}
+
+// NewStreamParser creates a parser that pulls tokens from source as it needs them,
+// so a document is parsed while it is being read.
+// Example: NewStreamParser(token.NewLexer(os.Stdin))
+func NewStreamParser(source TokenSource, opts ...Option) *Parser {
+	return &Parser{source: source, options: newOptions(opts)}
+}
+
+// Parse parses a single JSON value and makes sure no tokens are left after it.
+// Example Input: [{Type: LeftBrace, Val: "{"}, {Type: String, Val: "a"}, ...]
+// Example Output: &AstNode{Type: Object, Value: NewObject([]*Member{{Key: "a", ...}})}
+func Parse(tokens []token.Token, opts ...Option) (*AstNode, error) {
+	return NewParser(tokens, opts...).Parse()
+}
+
+// ParseReader parses a single JSON value read from r, tokenizing it incrementally with a Lexer configured by opts.
+// The parser follows the JSON5 and Limits options of the Lexer; to set other parser options,
+// use NewStreamParser(token.NewLexer(r, opts...), parserOpts...).Parse() instead.
+// Example: ParseReader(file, token.JSONC())
+func ParseReader(r io.Reader, opts ...token.Option) (*AstNode, error) {
+	var lexerOptions token.Options
+	for _, opt := range opts {
+		opt(&lexerOptions)
+	}
+	parserOpts := []Option{Limits(lexerOptions.Limits)}
+	if lexerOptions.JSON5 {
+		parserOpts = append(parserOpts, JSON5())
+	}
+	return NewStreamParser(token.NewLexer(r, opts...), parserOpts...).Parse()
+}
+
+// More reports whether another value follows in the source.
+// It also returns true if the source failed, so the error is reported by the next ParseNext,
+// and false once ParseNext returned an error, as no value can be read after it.
+func (p *Parser) More() bool {
+	return p.failed == nil && p.peek().Type != token.EOF
+}
 
+// ParseNext parses the next value of a sequence of values, e.g. newline-delimited JSON.
+// It returns io.EOF once the source is exhausted. After an error, it returns the same error on every call.
+func (p *Parser) ParseNext() (*AstNode, error) {
+	if p.failed != nil {
+		return nil, p.failed
+	}
+	if !p.More() {
+		return nil, io.EOF
+	}
+	node, err := p.parseValue()
+	if err != nil {
+		p.failed = err
+		return nil, err
 	}
+	return node, nil
+}
 
-	return ast, nil
+// Parse parses a single JSON value and makes sure no tokens are left after it.
+func (p *Parser) Parse() (*AstNode, error) {
+	node, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	if tk := p.peek(); tk.Type != token.EOF {
+		return nil, p.unexpected(tk, "end of input")
+	}
+	return node, nil
 }
 
 // AstToMap function to convert AST to map.
-// It iterates over the AST nodes and converts them into a map.
-// It uses the key to store the key of the key-value pair.
-func AstToMap(tokens []token.Token) (map[string]interface{}, error) {
-	ast, err := parser(tokens)
+// It parses the tokens into a tree and converts the top-level object into a map.
+// Nested objects become map[string]interface{}, or *OrderedMap with the OrderedObjects option,
+// and arrays become []interface{}.
+func AstToMap(tokens []token.Token, opts ...Option) (map[string]interface{}, error) {
+	ast, err := Parse(tokens, opts...)
 	if err != nil {
 		return nil, err
 	}
-	result := make(map[string]interface{})
-	var key string
-	for _, node := range ast {
-		switch node.Type {
-		case token.String:
-			if key == "" {
-				key = node.Value.(string)
-			} else {
-				result[key] = node.Value.(string)
-				key = ""
-			}
-		case token.Number:
-			if key == "" {
-				key = node.Value.(string)
-			} else {
-				result[key] = node.Value.(float64)
-				key = ""
+	if ast.Type != token.Object {
+		return nil, fmt.Errorf("expected object, got %s", ast.Type)
+	}
+	result := make(map[string]interface{}, ast.Value.(*Object).Len())
+	for _, member := range ast.Value.(*Object).Members() {
+		result[member.Key] = NodeToValue(member.Value, opts...)
+	}
+	return result, nil
+}
+
+// AstToValue function to convert AST to plain Go values.
+// It accepts any top-level JSON value: objects become map[string]interface{},
+// arrays become []interface{}, and scalars become string, float64, bool or nil.
+func AstToValue(tokens []token.Token, opts ...Option) (interface{}, error) {
+	ast, err := Parse(tokens, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return NodeToValue(ast, opts...), nil
+}
+
+// NodeToValue converts an AST node into plain Go values.
+// Objects become map[string]interface{}, or *OrderedMap with the OrderedObjects option,
+// and arrays become []interface{}. Numbers parsed with NumberString are converted to the mode
+// selected by the Numbers option; a Number that does not fit that mode is returned unchanged.
+func NodeToValue(node *AstNode, opts ...Option) interface{} {
+	return newOptions(opts).nodeToValue(node)
+}
+
+// nodeToValue converts an AST node into plain Go values according to the options.
+func (o Options) nodeToValue(node *AstNode) interface{} {
+	switch node.Type {
+	case token.Object:
+		object := node.Value.(*Object)
+		if o.OrderedObjects {
+			result := NewOrderedMap()
+			for _, member := range object.Members() {
+				result.Set(member.Key, o.nodeToValue(member.Value))
 			}
-		case token.Boolean:
-			if key == "" {
-				key = node.Value.(string)
-			} else {
-				result[key] = node.Value.(bool)
-				key = ""
+			return result
+		}
+		result := make(map[string]interface{}, object.Len())
+		for _, member := range object.Members() {
+			result[member.Key] = o.nodeToValue(member.Value)
+		}
+		return result
+	case token.Array:
+		elements := node.Value.([]*AstNode)
+		result := make([]interface{}, len(elements))
+		for i, element := range elements {
+			result[i] = o.nodeToValue(element)
+		}
+		return result
+	case token.Number:
+		if literal, ok := node.Value.(Number); ok && o.Numbers != NumberString {
+			if number, err := numberValue(string(literal), o.Numbers); err == nil {
+				return number
 			}
-		case token.Null:
-			if key == "" {
-				key = node.Value.(string)
-			} else {
-				result[key] = nil
-				key = ""
+		}
+		return node.Value
+	default:
+		return node.Value
+	}
+}
+
+// peek returns the current token without consuming it; comments are skipped.
+// If the source fails, it returns an ILLEGAL token and the error is kept in p.err.
+func (p *Parser) peek() token.Token {
+	for p.lookahead == nil {
+		tk, err := p.source.Next()
+		if err != nil {
+			p.err = err
+			tk = token.Token{Type: token.ILLEGAL}
+		}
+		if tk.Type != token.Comment {
+			p.lookahead = &tk
+		}
+	}
+	return *p.lookahead
+}
+
+// next consumes and returns the current token.
+func (p *Parser) next() token.Token {
+	tk := p.peek()
+	if p.err == nil {
+		p.lookahead = nil
+	}
+	return tk
+}
+
+// expect consumes the current token and checks that it has the given type.
+func (p *Parser) expect(t token.Type) (token.Token, error) {
+	tk := p.next()
+	if tk.Type != t {
+		return tk, p.unexpected(tk, strings.ToLower(t.String()))
+	}
+	return tk, nil
+}
+
+// unexpected reports a token the grammar does not allow at this point.
+// An error of the source takes precedence, as it is what caused the token to be missing.
+func (p *Parser) unexpected(tk token.Token, expected string) error {
+	if p.err != nil {
+		return p.err
+	}
+	return unexpected(tk, expected)
+}
+
+// parseValue parses any JSON value starting at the current token.
+func (p *Parser) parseValue() (*AstNode, error) {
+	switch p.peek().Type {
+	case token.LeftBrace:
+		return p.parseObject()
+	case token.LeftBracket:
+		return p.parseArray()
+	default:
+		tk := p.next()
+		if p.err != nil {
+			return nil, p.err
+		}
+		return parseValue(tk, p.options)
+	}
+}
+
+// parseObject parses an object and keeps its members in source order.
+// Example: `{"a": 1, "b": [true]}`
+func (p *Parser) parseObject() (*AstNode, error) {
+	open, err := p.expect(token.LeftBrace)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.enter(open); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+	members := []*Member{}
+	if p.peek().Type == token.RightBrace {
+		tk := p.next()
+		return &AstNode{Type: token.Object, Value: NewObject(members), Start: open.Start, End: tk.End}, nil
+	}
+
+	seen := &seenKeys{index: map[string]int{}}
+	for {
+		key, err := p.expect(token.String)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(token.Colon); err != nil {
+			return nil, err
+		}
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		member := &Member{Key: key.Val, Value: value, KeyStart: key.Start, KeyEnd: key.End}
+		if members, err = p.addMember(members, seen, member); err != nil {
+			return nil, err
+		}
+		if max := p.options.Limits.MaxMembers; max > 0 && len(members) > max {
+			return nil, &token.LimitError{Kind: token.ErrMaxMembers, Limit: max, Pos: key.Start}
+		}
+
+		tk := p.next()
+		if tk.Type == token.Comma && p.options.JSON5 && p.peek().Type == token.RightBrace {
+			tk = p.next()
+		}
+		switch tk.Type {
+		case token.Comma:
+			continue
+		case token.RightBrace:
+			return &AstNode{Type: token.Object, Value: NewObject(members), Start: open.Start, End: tk.End}, nil
+		default:
+			return nil, p.unexpected(tk, ", or }")
+		}
+	}
+}
+
+// enter opens the object or array starting at open, checking Limits.MaxDepth; leave closes it.
+func (p *Parser) enter(open token.Token) error {
+	if max := p.options.Limits.MaxDepth; max > 0 && p.depth >= max {
+		return &token.LimitError{Kind: token.ErrMaxDepth, Limit: max, Pos: open.Start}
+	}
+	p.depth++
+	return nil
+}
+
+// leave closes the object or array opened by enter.
+func (p *Parser) leave() {
+	p.depth--
+}
+
+// seenKeys tracks the keys of the object being parsed.
+type seenKeys struct {
+
+	// index maps each key to the position of its member
+	index map[string]int
+
+	// collected holds the keys whose values were already gathered into an array by DuplicateCollect
+	collected map[string]bool
+}
+
+// addMember appends member to members, applying the duplicate-key policy if its key was seen before.
+// Example: with DuplicateCollect, `{"a": 1, "a": 2}` has a single member "a" holding the array [1, 2].
+func (p *Parser) addMember(members []*Member, seen *seenKeys, member *Member) ([]*Member, error) {
+	i, ok := seen.index[member.Key]
+	if !ok {
+		seen.index[member.Key] = len(members)
+		return append(members, member), nil
+	}
+
+	switch p.options.DuplicateKeys {
+	case DuplicateReject:
+		return nil, token.NewSyntaxError(ErrDuplicateKey, member.KeyStart, member.Key, "")
+	case DuplicateKeepFirst:
+	case DuplicateCollect:
+		previous := members[i]
+		if !seen.collected[member.Key] {
+			if seen.collected == nil {
+				seen.collected = map[string]bool{}
 			}
+			previous.Value = &AstNode{Type: token.Array, Value: []*AstNode{previous.Value}, Start: previous.Value.Start}
+			seen.collected[member.Key] = true
 		}
+		previous.Value.Value = append(previous.Value.Value.([]*AstNode), member.Value)
+		previous.Value.End = member.Value.End
+	default:
+		members[i].Value = member.Value
 	}
-	return result, nil
+	return members, nil
 }
 
-// parseValue converts a token to an AST node.
-func parseValue(tk token.Token) (*AstNode, error) {
+// parseArray parses an array and keeps its elements in source order.
+// Example: `[1, "two", {"three": 3}]`
+func (p *Parser) parseArray() (*AstNode, error) {
+	open, err := p.expect(token.LeftBracket)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.enter(open); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+	elements := []*AstNode{}
+	if p.peek().Type == token.RightBracket {
+		tk := p.next()
+		return &AstNode{Type: token.Array, Value: elements, Start: open.Start, End: tk.End}, nil
+	}
+	for {
+		element, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		elements = append(elements, element)
+		if max := p.options.Limits.MaxArrayLength; max > 0 && len(elements) > max {
+			return nil, &token.LimitError{Kind: token.ErrMaxArrayLength, Limit: max, Pos: element.Start}
+		}
+
+		tk := p.next()
+		if tk.Type == token.Comma && p.options.JSON5 && p.peek().Type == token.RightBracket {
+			tk = p.next()
+		}
+		switch tk.Type {
+		case token.Comma:
+			continue
+		case token.RightBracket:
+			return &AstNode{Type: token.Array, Value: elements, Start: open.Start, End: tk.End}, nil
+		default:
+			return nil, p.unexpected(tk, ", or ]")
+		}
+	}
+}
+
+// parseValue converts a scalar token to an AST node, turning numbers into the Go value selected by options.Numbers.
+func parseValue(tk token.Token, options Options) (*AstNode, error) {
 	switch tk.Type {
-	case token.RightBrace, token.RightBracket, token.Comma, token.Colon, token.LeftBrace, token.LeftBracket, token.EOF:
-		return nil, nil
 	case token.String:
-		return &AstNode{Type: tk.Type, Value: tk.Val}, nil
+		return &AstNode{Type: tk.Type, Value: tk.Val, Start: tk.Start, End: tk.End}, nil
 	case token.Number:
-		number, err := strconv.ParseFloat(tk.Val, 64)
+		convert := numberValue
+		if options.JSON5 && !token.IsNumber(tk.Val) {
+			if !token.IsJSON5Number(tk.Val) {
+				return nil, token.NewSyntaxError(token.ErrInvalidNumber, tk.Start, tk.Val, "")
+			}
+			convert = json5NumberValue
+		} else if !token.IsNumber(tk.Val) {
+			return nil, token.NewSyntaxError(token.ErrInvalidNumber, tk.Start, tk.Val, "")
+		}
+		number, err := convert(tk.Val, options.Numbers)
 		if err != nil {
-			return nil, err
+			return nil, token.NewSyntaxError(err, tk.Start, tk.Val, "")
 		}
-		return &AstNode{Type: tk.Type, Value: number}, nil
+		return &AstNode{Type: tk.Type, Value: number, Start: tk.Start, End: tk.End}, nil
 	case token.Boolean:
 		boolean, err := strconv.ParseBool(tk.Val)
 		if err != nil {
-			return nil, err
+			return nil, token.NewSyntaxError(token.ErrInvalidLiteral, tk.Start, tk.Val, "")
 		}
-		return &AstNode{Type: tk.Type, Value: boolean}, nil
+		return &AstNode{Type: tk.Type, Value: boolean, Start: tk.Start, End: tk.End}, nil
 	case token.Null:
-		return &AstNode{Type: tk.Type, Value: nil}, nil
+		return &AstNode{Type: tk.Type, Value: nil, Start: tk.Start, End: tk.End}, nil
 	default:
-		return nil, fmt.Errorf("unexpected token type: %s", tk.Type)
+		return nil, unexpected(tk, "value")
+	}
+}
+
+// unexpected creates a SyntaxError for a token the grammar does not allow at this point.
+func unexpected(tk token.Token, expected string) error {
+	if tk.Type == token.EOF {
+		return token.NewSyntaxError(token.ErrUnexpectedEOF, tk.Start, "", expected)
 	}
+	return token.NewSyntaxError(token.ErrUnexpectedToken, tk.Start, tk.Val, expected)
 }
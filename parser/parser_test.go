@@ -1,17 +1,21 @@
 package parser
 
 import (
+	"errors"
 	"github.com/onerciller/gojsonp/token"
+	"io"
 	"reflect"
+	"strings"
 	"testing"
+	"testing/iotest"
 )
 
-// TestParser tests the parser function for various token inputs.
-func TestParser(t *testing.T) {
+// TestParse tests the Parse function for various token inputs.
+func TestParse(t *testing.T) {
 	tests := []struct {
 		name    string
 		tokens  []token.Token
-		want    []*AstNode
+		want    *AstNode
 		wantErr bool
 	}{
 		{
@@ -19,9 +23,7 @@ func TestParser(t *testing.T) {
 			tokens: []token.Token{
 				{Type: token.String, Val: "hello"},
 			},
-			want: []*AstNode{
-				{Type: token.String, Value: "hello"},
-			},
+			want:    &AstNode{Type: token.String, Value: "hello"},
 			wantErr: false,
 		},
 		{
@@ -29,9 +31,7 @@ func TestParser(t *testing.T) {
 			tokens: []token.Token{
 				{Type: token.Number, Val: "123"},
 			},
-			want: []*AstNode{
-				{Type: token.Number, Value: float64(123)},
-			},
+			want:    &AstNode{Type: token.Number, Value: float64(123)},
 			wantErr: false,
 		},
 		{
@@ -39,9 +39,7 @@ func TestParser(t *testing.T) {
 			tokens: []token.Token{
 				{Type: token.Boolean, Val: "true"},
 			},
-			want: []*AstNode{
-				{Type: token.Boolean, Value: true},
-			},
+			want:    &AstNode{Type: token.Boolean, Value: true},
 			wantErr: false,
 		},
 		{
@@ -49,22 +47,80 @@ func TestParser(t *testing.T) {
 			tokens: []token.Token{
 				{Type: token.Null, Val: "null"},
 			},
-			want: []*AstNode{
-				{Type: token.Null, Value: nil},
-			},
+			want:    &AstNode{Type: token.Null, Value: nil},
 			wantErr: false,
 		},
+		{
+			name:   "Nested object",
+			tokens: tokenize(t, `{"a": {"b": 1}}`),
+			want: &AstNode{Type: token.Object, Value: NewObject([]*Member{
+				{Key: "a", Value: &AstNode{Type: token.Object, Value: NewObject([]*Member{
+					{Key: "b", Value: &AstNode{Type: token.Number, Value: float64(1)}},
+				})}},
+			})},
+			wantErr: false,
+		},
+		{
+			name:   "Array of values",
+			tokens: tokenize(t, `{"a": [1, "two", [], {}]}`),
+			want: &AstNode{Type: token.Object, Value: NewObject([]*Member{
+				{Key: "a", Value: &AstNode{Type: token.Array, Value: []*AstNode{
+					{Type: token.Number, Value: float64(1)},
+					{Type: token.String, Value: "two"},
+					{Type: token.Array, Value: []*AstNode{}},
+					{Type: token.Object, Value: NewObject([]*Member{})},
+				}}},
+			})},
+			wantErr: false,
+		},
+		{
+			name: "Missing colon",
+			tokens: []token.Token{
+				{Type: token.LeftBrace, Val: "{"},
+				{Type: token.String, Val: "a"},
+				{Type: token.Number, Val: "1"},
+				{Type: token.RightBrace, Val: "}"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Mismatched closing token",
+			tokens: []token.Token{
+				{Type: token.LeftBracket, Val: "["},
+				{Type: token.Number, Val: "1"},
+				{Type: token.RightBrace, Val: "}"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Unexpected trailing token",
+			tokens: []token.Token{
+				{Type: token.String, Val: "a"},
+				{Type: token.String, Val: "b"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Unclosed object",
+			tokens: []token.Token{
+				{Type: token.LeftBrace, Val: "{"},
+				{Type: token.String, Val: "a"},
+				{Type: token.Colon, Val: ":"},
+				{Type: token.Number, Val: "1"},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := parser(tt.tokens)
+			got, err := Parse(tt.tokens)
 			if (err != nil) != tt.wantErr {
-				t.Errorf("parser() error = %v, wantErr %v", err, tt.wantErr)
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
-			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("parser() got = %v, want %v", got, tt.want)
+			if !reflect.DeepEqual(withoutPositions(got), tt.want) {
+				t.Errorf("Parse() got = %v, want %v", got, tt.want)
 			}
 		})
 	}
@@ -79,11 +135,8 @@ func TestAstToMap(t *testing.T) {
 		wantErr bool
 	}{
 		{
-			name: "Simple key-value pair",
-			tokens: []token.Token{
-				{Type: token.String, Val: "key"},
-				{Type: token.String, Val: "value"},
-			},
+			name:   "Simple key-value pair",
+			tokens: tokenize(t, `{"key": "value"}`),
 			want: map[string]interface{}{
 				"key": "value",
 			},
@@ -91,18 +144,28 @@ func TestAstToMap(t *testing.T) {
 		},
 
 		{
-			name: "Multiple key-value pairs",
-			tokens: []token.Token{
-				{Type: token.String, Val: "key1"},
-				{Type: token.String, Val: "value1"},
-				{Type: token.String, Val: "key2"},
-				{Type: token.String, Val: "value2"},
-			},
+			name:   "Multiple key-value pairs",
+			tokens: tokenize(t, `{"key1": "value1", "key2": "value2"}`),
 			want: map[string]interface{}{
 				"key1": "value1",
 				"key2": "value2",
 			},
 		},
+		{
+			name:   "Nested objects and arrays",
+			tokens: tokenize(t, `{"a": {"b": 1, "c": [true, null, {"d": "e"}]}}`),
+			want: map[string]interface{}{
+				"a": map[string]interface{}{
+					"b": float64(1),
+					"c": []interface{}{true, nil, map[string]interface{}{"d": "e"}},
+				},
+			},
+		},
+		{
+			name:    "Top-level array is not an object",
+			tokens:  tokenize(t, `[1]`),
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -118,3 +181,374 @@ func TestAstToMap(t *testing.T) {
 		})
 	}
 }
+
+// TestAstToValue tests the AstToValue function for any top-level JSON value.
+func TestAstToValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    interface{}
+		wantErr bool
+	}{
+		{name: "String", input: `"hello"`, want: "hello"},
+		{name: "Number", input: `3`, want: float64(3)},
+		{name: "Negative number", input: `-5`, want: float64(-5)},
+		{name: "Fraction", input: `3.14`, want: 3.14},
+		{name: "Exponent", input: `2.5E-3`, want: 2.5e-3},
+		{name: "Number out of range", input: `1e400`, wantErr: true},
+		{name: "True", input: `true`, want: true},
+		{name: "False", input: `false`, want: false},
+		{name: "Null", input: `null`, want: nil},
+		{name: "Empty array", input: `[]`, want: []interface{}{}},
+		{name: "Array", input: `[1, [2], {"a": null}]`, want: []interface{}{
+			float64(1), []interface{}{float64(2)}, map[string]interface{}{"a": nil},
+		}},
+		{name: "Object", input: `{"a": false}`, want: map[string]interface{}{"a": false}},
+		{name: "Two top-level values", input: `{}[]`, wantErr: true},
+		{name: "Empty input", input: ``, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := token.Tokenizer([]byte(tt.input))
+			if err != nil {
+				if !tt.wantErr {
+					t.Errorf("Tokenizer() error = %v", err)
+				}
+				return
+			}
+			got, err := AstToValue(tokens)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("AstToValue() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AstToValue() got = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestParsePositions tests that AST nodes and members carry the positions of their source text.
+func TestParsePositions(t *testing.T) {
+	got, err := Parse(tokenize(t, "{\n  \"a\": [1, true]\n}"))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	pos := func(offset, line, column int) token.Position {
+		return token.Position{Offset: offset, Line: line, Column: column}
+	}
+
+	if got.Start != pos(0, 1, 1) || got.End != pos(20, 3, 2) {
+		t.Errorf("object spans %v-%v, want 1:1-3:2", got.Start, got.End)
+	}
+	member := got.Value.(*Object).Members()[0]
+	if member.KeyStart != pos(4, 2, 3) || member.KeyEnd != pos(7, 2, 6) {
+		t.Errorf("key spans %v-%v, want 2:3-2:6", member.KeyStart, member.KeyEnd)
+	}
+	if member.Value.Start != pos(9, 2, 8) || member.Value.End != pos(18, 2, 17) {
+		t.Errorf("array spans %v-%v, want 2:8-2:17", member.Value.Start, member.Value.End)
+	}
+	second := member.Value.Value.([]*AstNode)[1]
+	if second.Start != pos(13, 2, 12) || second.End != pos(17, 2, 16) {
+		t.Errorf("boolean spans %v-%v, want 2:12-2:16", second.Start, second.End)
+	}
+}
+
+// TestParseErrorPosition tests that parser errors are SyntaxErrors pointing at the offending token.
+// The Tokenizer rejects a mismatched closer itself, so the tokens are changed to reach the parser's check.
+func TestParseErrorPosition(t *testing.T) {
+	tokens := tokenize(t, "[1,\n 2\n]")
+	tokens[4].Type, tokens[4].Val = token.RightBrace, "}"
+	_, err := Parse(tokens)
+	if err == nil || err.Error() != `3:1: unexpected token "}", expected , or ]` {
+		t.Errorf("Parse() error = %v", err)
+	}
+	if !errors.Is(err, token.ErrUnexpectedToken) {
+		t.Errorf("Parse() error = %v, want token.ErrUnexpectedToken", err)
+	}
+}
+
+// TestParseDuplicateKeys tests each duplicate-key policy, including repeated keys in nested objects.
+func TestParseDuplicateKeys(t *testing.T) {
+	input := `{"a": 1, "b": {"c": true, "c": false, "c": null}, "a": [2]}`
+	tests := []struct {
+		name   string
+		policy DuplicatePolicy
+		want   interface{}
+	}{
+		{
+			name:   "Keep last",
+			policy: DuplicateKeepLast,
+			want: map[string]interface{}{
+				"a": []interface{}{float64(2)},
+				"b": map[string]interface{}{"c": nil},
+			},
+		},
+		{
+			name:   "Keep first",
+			policy: DuplicateKeepFirst,
+			want: map[string]interface{}{
+				"a": float64(1),
+				"b": map[string]interface{}{"c": true},
+			},
+		},
+		{
+			name:   "Collect",
+			policy: DuplicateCollect,
+			want: map[string]interface{}{
+				"a": []interface{}{float64(1), []interface{}{float64(2)}},
+				"b": map[string]interface{}{"c": []interface{}{true, false, nil}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := AstToValue(tokenize(t, input), DuplicateKeys(tt.policy))
+			if err != nil {
+				t.Fatalf("AstToValue() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AstToValue() got = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestParseDuplicateKeysOrder tests that a duplicate key keeps the position of its first occurrence.
+func TestParseDuplicateKeysOrder(t *testing.T) {
+	got, err := Parse(tokenize(t, `{"a": 1, "b": 2, "a": 3}`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	object := got.Value.(*Object)
+	if keys := object.Keys(); !reflect.DeepEqual(keys, []string{"a", "b"}) {
+		t.Errorf("Keys() = %v, want [a b]", keys)
+	}
+	if value, _ := object.Get("a"); value.Value != float64(3) {
+		t.Errorf("Get(a) = %v, want 3", value.Value)
+	}
+
+	got, err = Parse(tokenize(t, `{"a": 1, "a": 2}`), DuplicateKeys(DuplicateCollect))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	collected, _ := got.Value.(*Object).Get("a")
+	if collected.Start.Offset != 6 || collected.End.Offset != 15 {
+		t.Errorf("collected array spans %d-%d, want 6-15", collected.Start.Offset, collected.End.Offset)
+	}
+}
+
+// TestParseDuplicateKeysReject tests that DuplicateReject reports the repeated key with its position.
+func TestParseDuplicateKeysReject(t *testing.T) {
+	_, err := Parse(tokenize(t, "{\"a\": {\"b\": 1,\n  \"b\": 2}}"), DuplicateKeys(DuplicateReject))
+	if !errors.Is(err, ErrDuplicateKey) {
+		t.Fatalf("Parse() error = %v, want ErrDuplicateKey", err)
+	}
+	if err.Error() != `2:3: duplicate key "b"` {
+		t.Errorf("Parse() error = %q", err.Error())
+	}
+
+	if _, err := Parse(tokenize(t, `{"a": {"b": 1}, "b": 2}`), DuplicateKeys(DuplicateReject)); err != nil {
+		t.Errorf("Parse() error = %v, keys of different objects are not duplicates", err)
+	}
+}
+
+// withoutPositions returns a copy of node with all positions cleared, so tests can focus on structure.
+func withoutPositions(node *AstNode) *AstNode {
+	if node == nil {
+		return nil
+	}
+	result := &AstNode{Type: node.Type, Value: node.Value}
+	switch value := node.Value.(type) {
+	case *Object:
+		members := make([]*Member, value.Len())
+		for i, member := range value.Members() {
+			members[i] = &Member{Key: member.Key, Value: withoutPositions(member.Value)}
+		}
+		result.Value = NewObject(members)
+	case []*AstNode:
+		elements := make([]*AstNode, len(value))
+		for i, element := range value {
+			elements[i] = withoutPositions(element)
+		}
+		result.Value = elements
+	}
+	return result
+}
+
+// TestParseReader tests that ParseReader parses while reading and reports reader and syntax errors.
+func TestParseReader(t *testing.T) {
+	input := `{"a": [1, {"b": "c"}], "d": null}`
+	got, err := ParseReader(iotest.OneByteReader(strings.NewReader(input)))
+	if err != nil {
+		t.Fatalf("ParseReader() error = %v", err)
+	}
+	want, _ := Parse(tokenize(t, input))
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseReader() got = %v, want %v", got, want)
+	}
+
+	_, err = ParseReader(strings.NewReader(`[1, 2`))
+	if !errors.Is(err, token.ErrUnexpectedEOF) {
+		t.Errorf("ParseReader() error = %v, want token.ErrUnexpectedEOF", err)
+	}
+
+	errRead := errors.New("connection reset")
+	_, err = ParseReader(io.MultiReader(strings.NewReader(`[1, `), iotest.ErrReader(errRead)))
+	if err != errRead {
+		t.Errorf("ParseReader() error = %v, want %v", err, errRead)
+	}
+}
+
+// TestParseJSON5 tests that trailing commas are accepted only with the JSON5 option, also when streaming.
+func TestParseJSON5(t *testing.T) {
+	input := "{name: 'gojsonp', tags: ['a', 'b',], // note\n}"
+	tokens, err := token.Tokenizer([]byte(input), token.JSON5())
+	if err != nil {
+		t.Fatalf("Tokenizer() error = %v", err)
+	}
+	got, err := AstToValue(tokens, JSON5())
+	want := map[string]interface{}{"name": "gojsonp", "tags": []interface{}{"a", "b"}}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("AstToValue() got = %v, %v, want %v", got, err, want)
+	}
+
+	if _, err := Parse(tokens); !errors.Is(err, token.ErrUnexpectedToken) {
+		t.Errorf("Parse() error = %v, want token.ErrUnexpectedToken without JSON5", err)
+	}
+
+	node, err := ParseReader(iotest.OneByteReader(strings.NewReader(input)), token.JSON5())
+	if err != nil {
+		t.Fatalf("ParseReader() error = %v", err)
+	}
+	if tags, _ := node.Value.(*Object).Get("tags"); len(tags.Value.([]*AstNode)) != 2 {
+		t.Errorf("ParseReader() tags = %v, want 2 elements", tags)
+	}
+}
+
+// TestParseJSONC tests that the parser skips Comment tokens, also between top-level values and when streaming.
+func TestParseJSONC(t *testing.T) {
+	input := "// settings\n{\"tabSize\": 4, /* spaces */ \"wrap\": [true // on\n]} // end"
+	tokens, err := token.Tokenizer([]byte(input), token.JSONC())
+	if err != nil {
+		t.Fatalf("Tokenizer() error = %v", err)
+	}
+	want, _ := Parse(tokenize(t, `{"tabSize": 4, "wrap": [true]}`))
+	got, err := Parse(tokens)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if !reflect.DeepEqual(withoutPositions(got), withoutPositions(want)) {
+		t.Errorf("Parse() got = %v, want %v", got, want)
+	}
+
+	got, err = ParseReader(iotest.OneByteReader(strings.NewReader(input)), token.JSONC())
+	if err != nil || !reflect.DeepEqual(withoutPositions(got), withoutPositions(want)) {
+		t.Errorf("ParseReader() got = %v, %v, want %v", got, err, want)
+	}
+
+	tokens, _ = token.Tokenizer([]byte("1 // one\n2 /* two */"), token.JSONC())
+	p := NewParser(tokens)
+	for _, want := range []float64{1, 2} {
+		node, err := p.ParseNext()
+		if err != nil || node.Value != want {
+			t.Errorf("ParseNext() got = %v, %v, want %v", node, err, want)
+		}
+	}
+	if _, err := p.ParseNext(); err != io.EOF {
+		t.Errorf("ParseNext() error = %v, want io.EOF after the trailing comment", err)
+	}
+}
+
+// TestParseLimits tests that the parser enforces the nesting, member and array limits on any tokens.
+func TestParseLimits(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		limits token.Limits
+		kind   error
+		pos    token.Position
+	}{
+		{name: "Depth", input: `[{"a": [1]}]`, limits: token.Limits{MaxDepth: 2}, kind: token.ErrMaxDepth, pos: token.Position{Offset: 7, Line: 1, Column: 8}},
+		{name: "Members", input: `{"a": 1, "b": 2, "c": 3}`, limits: token.Limits{MaxMembers: 2}, kind: token.ErrMaxMembers, pos: token.Position{Offset: 17, Line: 1, Column: 18}},
+		{name: "Nested members", input: `[{}, {"a": 1, "b": 2}]`, limits: token.Limits{MaxMembers: 1}, kind: token.ErrMaxMembers, pos: token.Position{Offset: 14, Line: 1, Column: 15}},
+		{name: "Array length", input: `[1, [2, 3, 4]]`, limits: token.Limits{MaxArrayLength: 2}, kind: token.ErrMaxArrayLength, pos: token.Position{Offset: 11, Line: 1, Column: 12}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tokenize(t, tt.input), Limits(tt.limits))
+			var limitErr *token.LimitError
+			if !errors.As(err, &limitErr) || !errors.Is(err, tt.kind) {
+				t.Fatalf("Parse() error = %v, want a *token.LimitError of kind %v", err, tt.kind)
+			}
+			if limitErr.Pos != tt.pos {
+				t.Errorf("Parse() error at %#v, want %#v", limitErr.Pos, tt.pos)
+			}
+		})
+	}
+
+	limits := Limits(token.Limits{MaxDepth: 2, MaxMembers: 2, MaxArrayLength: 2})
+	if _, err := Parse(tokenize(t, `[{"a": 1, "b": 2}, [1, 2]]`), limits); err != nil {
+		t.Errorf("Parse() error = %v, want input at the limits to be accepted", err)
+	}
+	if _, err := Parse(tokenize(t, `{"a": 1, "a": 2, "a": 3}`), limits); err != nil {
+		t.Errorf("Parse() error = %v, want repeated keys to count once", err)
+	}
+}
+
+// TestParseReaderLimits tests that ParseReader applies the limits in its Lexer and its parser.
+func TestParseReaderLimits(t *testing.T) {
+	_, err := ParseReader(strings.NewReader(`["abcd"]`), token.WithLimits(token.Limits{MaxStringBytes: 3}))
+	if !errors.Is(err, token.ErrMaxStringBytes) {
+		t.Errorf("ParseReader() error = %v, want token.ErrMaxStringBytes", err)
+	}
+	_, err = ParseReader(strings.NewReader(`[1, 2]`), token.WithLimits(token.Limits{MaxArrayLength: 1}))
+	if !errors.Is(err, token.ErrMaxArrayLength) {
+		t.Errorf("ParseReader() error = %v, want token.ErrMaxArrayLength", err)
+	}
+}
+
+// TestParseReaderReplaceInvalidUTF8 tests that ParseReader passes ReplaceInvalidUTF8 to its Lexer.
+func TestParseReaderReplaceInvalidUTF8(t *testing.T) {
+	input := "[\"a\xffb\"]"
+	if _, err := ParseReader(strings.NewReader(input)); !errors.Is(err, token.ErrInvalidUTF8) {
+		t.Errorf("ParseReader() error = %v, want token.ErrInvalidUTF8", err)
+	}
+	node, err := ParseReader(strings.NewReader(input), token.ReplaceInvalidUTF8())
+	if err != nil || node.Value.([]*AstNode)[0].Value != "a\uFFFDb" {
+		t.Errorf("ParseReader() got = %v, %v, want the invalid byte replaced", node, err)
+	}
+}
+
+// TestParseReaderAnyEncoding tests that ParseReader passes AnyEncoding to its Lexer.
+func TestParseReaderAnyEncoding(t *testing.T) {
+	input := "\xff\xfe[\x001\x00]\x00"
+	node, err := ParseReader(strings.NewReader(input), token.AnyEncoding())
+	if err != nil || len(node.Value.([]*AstNode)) != 1 {
+		t.Errorf("ParseReader() got = %v, %v, want [1]", node, err)
+	}
+}
+
+// TestParserStopsAtValueEnd tests that the parser pulls no tokens past the end of the value it parses.
+func TestParserStopsAtValueEnd(t *testing.T) {
+	source := &tokenSlice{tokens: tokenize(t, `[1, 2] [3]`)}
+	if _, err := NewStreamParser(source).parseValue(); err != nil {
+		t.Fatalf("parseValue() error = %v", err)
+	}
+	if next, _ := source.Next(); next.Type != token.LeftBracket {
+		t.Errorf("Expected the second array to be left in the source, got %v", next)
+	}
+}
+
+// tokenize runs the tokenizer on input and fails the test on error.
+func tokenize(t *testing.T, input string) []token.Token {
+	t.Helper()
+	tokens, err := token.Tokenizer([]byte(input))
+	if err != nil {
+		t.Fatalf("Tokenizer() error = %v", err)
+	}
+	return tokens
+}
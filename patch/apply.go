@@ -0,0 +1,87 @@
+package patch
+
+import (
+	"github.com/onerciller/gojsonp/parser"
+	"github.com/onerciller/gojsonp/token"
+)
+
+// Apply runs the operations in order on a copy of doc and returns the patched copy.
+// The patch is atomic: if an operation fails, the error identifies it and doc is left unchanged.
+// Example:
+//
+//	p, _ := Decode([]byte(`[{"op": "replace", "path": "/name", "value": "Jane"}]`))
+//	patched, err := p.Apply(doc)
+func (p Patch) Apply(doc *parser.AstNode) (*parser.AstNode, error) {
+	result := doc.Clone()
+	for i, op := range p {
+		var err error
+		if result, err = op.apply(result); err != nil {
+			return nil, &Error{Index: i, Op: op.Op, Pos: op.Pos, Err: err}
+		}
+	}
+	return result, nil
+}
+
+// ApplyJSON decodes a patch document and applies it to the JSON document doc, returning the patched document.
+func ApplyJSON(doc, patch []byte) (*parser.AstNode, error) {
+	p, err := Decode(patch)
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := token.Tokenizer(doc)
+	if err != nil {
+		return nil, err
+	}
+	root, err := parser.Parse(tokens)
+	if err != nil {
+		return nil, err
+	}
+	return p.Apply(root)
+}
+
+// apply runs the operation on doc, which it may modify, and returns the new root.
+func (op Operation) apply(doc *parser.AstNode) (*parser.AstNode, error) {
+	switch op.Op {
+	case OpAdd:
+		return op.Path.Add(doc, op.Value.Clone())
+	case OpRemove:
+		_, err := op.Path.Remove(doc)
+		return doc, err
+	case OpReplace:
+		// unlike Set, replace requires the target to exist
+		if _, err := op.Path.Get(doc); err != nil {
+			return nil, err
+		}
+		return op.Path.Set(doc, op.Value.Clone())
+	case OpMove:
+		if op.Path.String() == op.From.String() {
+			_, err := op.From.Get(doc)
+			return doc, err
+		}
+		if op.Path.HasPrefix(op.From) {
+			return nil, ErrMoveIntoSelf
+		}
+		value, err := op.From.Remove(doc)
+		if err != nil {
+			return nil, err
+		}
+		return op.Path.Add(doc, value)
+	case OpCopy:
+		value, err := op.From.Get(doc)
+		if err != nil {
+			return nil, err
+		}
+		return op.Path.Add(doc, value.Clone())
+	case OpTest:
+		value, err := op.Path.Get(doc)
+		if err != nil {
+			return nil, err
+		}
+		if !parser.Equal(value, op.Value) {
+			return nil, ErrTestFailed
+		}
+		return doc, nil
+	default:
+		return nil, ErrInvalidPatch
+	}
+}
@@ -0,0 +1,217 @@
+package patch
+
+import (
+	"errors"
+	"github.com/onerciller/gojsonp/parser"
+	"github.com/onerciller/gojsonp/pointer"
+	"github.com/onerciller/gojsonp/token"
+	"testing"
+)
+
+// TestApply tests the examples of RFC 6902, appendix A.
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     string
+		patch   string
+		want    string
+		wantErr error
+	}{
+		{
+			name:  "Adding an object member",
+			doc:   `{"foo": "bar"}`,
+			patch: `[{"op": "add", "path": "/baz", "value": "qux"}]`,
+			want:  `{"baz": "qux", "foo": "bar"}`,
+		},
+		{
+			name:  "Adding an array element",
+			doc:   `{"foo": ["bar", "baz"]}`,
+			patch: `[{"op": "add", "path": "/foo/1", "value": "qux"}]`,
+			want:  `{"foo": ["bar", "qux", "baz"]}`,
+		},
+		{
+			name:  "Removing an object member",
+			doc:   `{"baz": "qux", "foo": "bar"}`,
+			patch: `[{"op": "remove", "path": "/baz"}]`,
+			want:  `{"foo": "bar"}`,
+		},
+		{
+			name:  "Removing an array element",
+			doc:   `{"foo": ["bar", "qux", "baz"]}`,
+			patch: `[{"op": "remove", "path": "/foo/1"}]`,
+			want:  `{"foo": ["bar", "baz"]}`,
+		},
+		{
+			name:  "Replacing a value",
+			doc:   `{"baz": "qux", "foo": "bar"}`,
+			patch: `[{"op": "replace", "path": "/baz", "value": "boo"}]`,
+			want:  `{"baz": "boo", "foo": "bar"}`,
+		},
+		{
+			name:  "Moving a value",
+			doc:   `{"foo": {"bar": "baz", "waldo": "fred"}, "qux": {"corge": "grault"}}`,
+			patch: `[{"op": "move", "from": "/foo/waldo", "path": "/qux/thud"}]`,
+			want:  `{"foo": {"bar": "baz"}, "qux": {"corge": "grault", "thud": "fred"}}`,
+		},
+		{
+			name:  "Moving an array element",
+			doc:   `{"foo": ["all", "grass", "cows", "eat"]}`,
+			patch: `[{"op": "move", "from": "/foo/1", "path": "/foo/3"}]`,
+			want:  `{"foo": ["all", "cows", "eat", "grass"]}`,
+		},
+		{
+			name: "Testing a value: success",
+			doc:  `{"baz": "qux", "foo": ["a", 2, "c"]}`,
+			patch: `[{"op": "test", "path": "/baz", "value": "qux"},
+			         {"op": "test", "path": "/foo/1", "value": 2}]`,
+			want: `{"baz": "qux", "foo": ["a", 2, "c"]}`,
+		},
+		{
+			name:    "Testing a value: error",
+			doc:     `{"baz": "qux"}`,
+			patch:   `[{"op": "test", "path": "/baz", "value": "bar"}]`,
+			wantErr: ErrTestFailed,
+		},
+		{
+			name:  "Adding a nested member object",
+			doc:   `{"foo": "bar"}`,
+			patch: `[{"op": "add", "path": "/child", "value": {"grandchild": {}}}]`,
+			want:  `{"foo": "bar", "child": {"grandchild": {}}}`,
+		},
+		{
+			name:  "Ignoring unrecognized elements",
+			doc:   `{"foo": "bar"}`,
+			patch: `[{"op": "add", "path": "/baz", "value": "qux", "xyz": 123}]`,
+			want:  `{"foo": "bar", "baz": "qux"}`,
+		},
+		{
+			name:    "Adding to a nonexistent target",
+			doc:     `{"foo": "bar"}`,
+			patch:   `[{"op": "add", "path": "/baz/bat", "value": "qux"}]`,
+			wantErr: pointer.ErrNotFound,
+		},
+		{
+			name:  "~ escape ordering",
+			doc:   `{"/": 9, "~1": 10}`,
+			patch: `[{"op": "test", "path": "/~01", "value": 10}]`,
+			want:  `{"/": 9, "~1": 10}`,
+		},
+		{
+			name:    "Comparing strings and numbers",
+			doc:     `{"/": 9, "~1": 10}`,
+			patch:   `[{"op": "test", "path": "/~01", "value": "10"}]`,
+			wantErr: ErrTestFailed,
+		},
+		{
+			name:  "Adding an array value",
+			doc:   `{"foo": ["bar"]}`,
+			patch: `[{"op": "add", "path": "/foo/-", "value": ["abc", "def"]}]`,
+			want:  `{"foo": ["bar", ["abc", "def"]]}`,
+		},
+		{
+			name:  "Testing objects regardless of member order",
+			doc:   `{"a": {"x": 1, "y": [1.0]}}`,
+			patch: `[{"op": "test", "path": "/a", "value": {"y": [1], "x": 1}}]`,
+			want:  `{"a": {"x": 1, "y": [1.0]}}`,
+		},
+		{
+			name:  "Copying a value",
+			doc:   `{"a": {"b": [1]}}`,
+			patch: `[{"op": "copy", "from": "/a/b", "path": "/c"}, {"op": "add", "path": "/c/-", "value": 2}]`,
+			want:  `{"a": {"b": [1]}, "c": [1, 2]}`,
+		},
+		{
+			name:  "Replacing the root",
+			doc:   `{"a": 1}`,
+			patch: `[{"op": "replace", "path": "", "value": [1]}]`,
+			want:  `[1]`,
+		},
+		{
+			name:    "Replacing a missing member",
+			doc:     `{"a": 1}`,
+			patch:   `[{"op": "replace", "path": "/b", "value": 2}]`,
+			wantErr: pointer.ErrNotFound,
+		},
+		{
+			name:    "Moving a value into its child",
+			doc:     `{"a": {"b": {}}}`,
+			patch:   `[{"op": "move", "from": "/a", "path": "/a/b/c"}]`,
+			wantErr: ErrMoveIntoSelf,
+		},
+		{
+			name:  "Moving a value onto itself",
+			doc:   `{"a": {"b": {}}}`,
+			patch: `[{"op": "move", "from": "/a", "path": "/a"}]`,
+			want:  `{"a": {"b": {}}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ApplyJSON([]byte(tt.doc), []byte(tt.patch))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ApplyJSON() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if want := parse(t, tt.want); !parser.Equal(got, want) {
+				t.Errorf("ApplyJSON() got = %v, want %s", parser.NodeToValue(got), tt.want)
+			}
+		})
+	}
+}
+
+// TestApplyAtomic tests that a failing operation leaves the document unchanged and is identified in the error.
+func TestApplyAtomic(t *testing.T) {
+	doc := parse(t, `{"list": [1, 2], "name": "a"}`)
+	p, err := Decode([]byte(`[
+  {"op": "add", "path": "/list/-", "value": 3},
+  {"op": "remove", "path": "/name"},
+  {"op": "replace", "path": "/missing", "value": 0}
+]`))
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+
+	_, err = p.Apply(doc)
+	var patchErr *Error
+	if !errors.As(err, &patchErr) {
+		t.Fatalf("Apply() error = %v, want *Error", err)
+	}
+	if patchErr.Index != 2 || patchErr.Op != OpReplace || patchErr.Pos.Line != 4 {
+		t.Errorf("Apply() error = %+v, want operation 2 on line 4", patchErr)
+	}
+	want := `operation 2 (replace) at 4:3: 1:1: pointer "/missing": segment 0 "missing": value not found`
+	if err.Error() != want {
+		t.Errorf("Apply() error = %q, want %q", err.Error(), want)
+	}
+	if !parser.Equal(doc, parse(t, `{"list": [1, 2], "name": "a"}`)) {
+		t.Errorf("Apply() changed the document: %v", parser.NodeToValue(doc))
+	}
+
+	patched, err := p[:2].Apply(doc)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if !parser.Equal(patched, parse(t, `{"list": [1, 2, 3]}`)) {
+		t.Errorf("Apply() got = %v", parser.NodeToValue(patched))
+	}
+	if !parser.Equal(doc, parse(t, `{"list": [1, 2], "name": "a"}`)) {
+		t.Errorf("Apply() changed the document: %v", parser.NodeToValue(doc))
+	}
+}
+
+// parse tokenizes and parses input and fails the test on error.
+func parse(t *testing.T, input string) *parser.AstNode {
+	t.Helper()
+	tokens, err := token.Tokenizer([]byte(input))
+	if err != nil {
+		t.Fatalf("Tokenizer() error = %v", err)
+	}
+	root, err := parser.Parse(tokens)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	return root
+}
@@ -0,0 +1,387 @@
+package patch
+
+import (
+	"github.com/onerciller/gojsonp"
+	"github.com/onerciller/gojsonp/parser"
+	"github.com/onerciller/gojsonp/pointer"
+	"strconv"
+	"strings"
+)
+
+// DiffOption configures Diff.
+type DiffOption func(*diffOptions)
+
+// diffOptions holds the settings of a single Diff call.
+type diffOptions struct {
+
+	// moves enables move operations for array elements that changed place
+	moves bool
+}
+
+// DetectMoves makes Diff emit a single move operation for an array element that changed place,
+// instead of removing it and adding it again.
+// Example: Diff(original, modified, DetectMoves())
+func DetectMoves() DiffOption {
+	return func(o *diffOptions) {
+		o.moves = true
+	}
+}
+
+// Diff computes a patch that turns original into modified, so that Apply(original) equals modified.
+// Objects are compared member by member and arrays element by element along their longest common subsequence,
+// so unchanged elements are kept and only the differences are listed; scalars that differ are replaced.
+// Very large arrays that differ in many places are compared index by index instead, see maxLCSCells.
+// Neither document is modified.
+// Example: from `{"a": 1, "b": [1, 2]}` to `{"a": 2, "b": [1, 2, 3]}` it gives
+// [{"op": "replace", "path": "/a", "value": 2}, {"op": "add", "path": "/b/2", "value": 3}].
+func Diff(original, modified *parser.AstNode, opts ...DiffOption) Patch {
+	var o diffOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	d := &differ{options: o}
+	d.diff(pointer.Pointer{}, original, modified)
+	return d.patch
+}
+
+// differ collects the operations of a Diff call.
+type differ struct {
+	options diffOptions
+	patch   Patch
+}
+
+// emit appends an operation to the patch.
+func (d *differ) emit(op Operation) {
+	d.patch = append(d.patch, op)
+}
+
+// diff appends the operations that turn a into b, both located at path.
+func (d *differ) diff(path pointer.Pointer, a, b *parser.AstNode) {
+	if parser.Equal(a, b) {
+		return
+	}
+	switch from := a.Value.(type) {
+	case *parser.Object:
+		if to, ok := b.Value.(*parser.Object); ok {
+			d.diffObjects(path, from, to)
+			return
+		}
+	case []*parser.AstNode:
+		if to, ok := b.Value.([]*parser.AstNode); ok {
+			d.diffArrays(path, from, to)
+			return
+		}
+	}
+	d.emit(Operation{Op: OpReplace, Path: path, Value: b.Clone()})
+}
+
+// diffObjects removes the members missing from b, compares the common ones and adds the new ones.
+func (d *differ) diffObjects(path pointer.Pointer, a, b *parser.Object) {
+	for _, member := range a.Members() {
+		value, ok := b.Get(member.Key)
+		if !ok {
+			d.emit(Operation{Op: OpRemove, Path: path.Append(member.Key)})
+			continue
+		}
+		d.diff(path.Append(member.Key), member.Value, value)
+	}
+	for _, member := range b.Members() {
+		if _, ok := a.Get(member.Key); !ok {
+			d.emit(Operation{Op: OpAdd, Path: path.Append(member.Key), Value: member.Value.Clone()})
+		}
+	}
+}
+
+// source tells where an element of the modified array comes from.
+type source struct {
+
+	// index is the index of the element in the original array, or -1 for a new element
+	index int
+
+	// moved is set if the element is taken from elsewhere in the array with a move operation
+	moved bool
+}
+
+// diffArrays turns a into b in three passes: it removes the elements of a that are not reused,
+// moves the elements that changed place, then adds the new elements and compares the rest in place.
+// Elements on the longest common subsequence stay where they are; remaining elements between two
+// common ones are paired up and compared, so a changed element is patched rather than replaced.
+func (d *differ) diffArrays(path pointer.Pointer, a, b []*parser.AstNode) {
+	sources := d.sources(a, b)
+
+	used := make([]bool, len(a))
+	for _, src := range sources {
+		if src.index >= 0 {
+			used[src.index] = true
+		}
+	}
+	var current []int
+	for i := range a {
+		if used[i] {
+			current = append(current, i)
+		}
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		if !used[i] {
+			d.emit(Operation{Op: OpRemove, Path: path.Append(strconv.Itoa(i))})
+		}
+	}
+
+	// new elements are still missing from current, so a moved element goes right after
+	// the element it follows in b, which is either kept or was moved before
+	for j, src := range sources {
+		if !src.moved {
+			continue
+		}
+		from := indexOf(current, src.index)
+		current = append(current[:from], current[from+1:]...)
+		to := 0
+		for k := j - 1; k >= 0; k-- {
+			if sources[k].index >= 0 {
+				to = indexOf(current, sources[k].index) + 1
+				break
+			}
+		}
+		current = append(current[:to], append([]int{src.index}, current[to:]...)...)
+		if from != to {
+			d.emit(Operation{Op: OpMove, From: path.Append(strconv.Itoa(from)), Path: path.Append(strconv.Itoa(to))})
+		}
+	}
+
+	for j, src := range sources {
+		if src.index < 0 {
+			d.emit(Operation{Op: OpAdd, Path: path.Append(strconv.Itoa(j)), Value: b[j].Clone()})
+			continue
+		}
+		d.diff(path.Append(strconv.Itoa(j)), a[src.index], b[j])
+	}
+}
+
+// sources finds where each element of b comes from in a: the longest common subsequence is kept,
+// equal elements off it are moved if DetectMoves is set, and the remaining elements between two
+// common ones are paired in order.
+func (d *differ) sources(a, b []*parser.AstNode) []source {
+	sources := make([]source, len(b))
+	for j := range sources {
+		sources[j].index = -1
+	}
+	used := make([]bool, len(a))
+	common := lcs(a, b)
+	for _, pair := range common {
+		sources[pair[1]].index = pair[0]
+		used[pair[0]] = true
+	}
+
+	if d.options.moves {
+		for j := range b {
+			if sources[j].index >= 0 {
+				continue
+			}
+			for i := range a {
+				if !used[i] && parser.Equal(a[i], b[j]) {
+					sources[j] = source{index: i, moved: true}
+					used[i] = true
+					break
+				}
+			}
+		}
+	}
+
+	// pair the leftovers of each gap between two common elements
+	common = append(common, [2]int{len(a), len(b)})
+	i, j := 0, 0
+	for _, pair := range common {
+		for i < pair[0] && j < pair[1] {
+			switch {
+			case used[i]:
+				i++
+			case sources[j].index >= 0:
+				j++
+			default:
+				sources[j].index = i
+				used[i] = true
+				i++
+				j++
+			}
+		}
+		i, j = pair[0]+1, pair[1]+1
+	}
+	return sources
+}
+
+// maxLCSCells bounds the number of element pairs lcs compares after trimming the common prefix and suffix,
+// e.g. two differing parts of 4096 elements each.
+const maxLCSCells = 1 << 24
+
+// lcs returns the index pairs of a longest common subsequence of equal elements of a and b, in order.
+// The common prefix and suffix are matched first, so arrays changed in one place are cheap to compare.
+// If the parts left in between have more than maxLCSCells pairs, none of their elements are matched
+// and the caller compares them in place instead.
+// Example: for [1, 2, 3] and [2, 3, 4] it returns [[1, 0], [2, 1]].
+func lcs(a, b []*parser.AstNode) [][2]int {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && parser.Equal(a[prefix], b[prefix]) {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && parser.Equal(a[len(a)-1-suffix], b[len(b)-1-suffix]) {
+		suffix++
+	}
+
+	pairs := make([][2]int, 0, prefix+suffix)
+	for k := 0; k < prefix; k++ {
+		pairs = append(pairs, [2]int{k, k})
+	}
+	middleA, middleB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	if len(middleA)*len(middleB) <= maxLCSCells {
+		pairs = appendLCS(pairs, middleA, middleB, prefix, prefix)
+	}
+	for k := suffix; k > 0; k-- {
+		pairs = append(pairs, [2]int{len(a) - k, len(b) - k})
+	}
+	return pairs
+}
+
+// appendLCS appends the pairs of a longest common subsequence of a and b, whose first elements are at
+// offsets i and j, using Hirschberg's algorithm so that memory stays linear in the lengths:
+// the first half of a is matched against the start of b up to the split that keeps the subsequence longest,
+// and the second half against the rest.
+func appendLCS(pairs [][2]int, a, b []*parser.AstNode, i, j int) [][2]int {
+	switch {
+	case len(a) == 0 || len(b) == 0:
+		return pairs
+	case len(a) == 1:
+		for k := range b {
+			if parser.Equal(a[0], b[k]) {
+				return append(pairs, [2]int{i, j + k})
+			}
+		}
+		return pairs
+	}
+
+	middle := len(a) / 2
+	forward := lcsLengths(a[:middle], b, false)
+	backward := lcsLengths(a[middle:], b, true)
+	split, longest := 0, -1
+	for k := range forward {
+		if forward[k]+backward[k] > longest {
+			split, longest = k, forward[k]+backward[k]
+		}
+	}
+	pairs = appendLCS(pairs, a[:middle], b[:split], i, j)
+	return appendLCS(pairs, a[middle:], b[split:], i+middle, j+split)
+}
+
+// lcsLengths returns, for each k from 0 to len(b), the length of a longest common subsequence of a and b[:k],
+// or of a and b[k:] if reverse is set. It keeps two rows of the table instead of all of them.
+func lcsLengths(a, b []*parser.AstNode, reverse bool) []int {
+	previous, current := make([]int, len(b)+1), make([]int, len(b)+1)
+	for x := range a {
+		if reverse {
+			element := a[len(a)-1-x]
+			current[len(b)] = 0
+			for k := len(b) - 1; k >= 0; k-- {
+				current[k] = maxInt(previous[k], current[k+1])
+				if parser.Equal(element, b[k]) {
+					current[k] = previous[k+1] + 1
+				}
+			}
+		} else {
+			current[0] = 0
+			for k := 1; k <= len(b); k++ {
+				current[k] = maxInt(previous[k], current[k-1])
+				if parser.Equal(a[x], b[k-1]) {
+					current[k] = previous[k-1] + 1
+				}
+			}
+		}
+		previous, current = current, previous
+	}
+	return previous
+}
+
+// maxInt returns the larger of x and y.
+func maxInt(x, y int) int {
+	if x > y {
+		return x
+	}
+	return y
+}
+
+// indexOf returns the position of index in indexes, or -1.
+func indexOf(indexes []int, index int) int {
+	for k, i := range indexes {
+		if i == index {
+			return k
+		}
+	}
+	return -1
+}
+
+// Describe renders the patch as it applies to doc, one line per operation with the JSON Pointer it
+// changes and the values before and after, which is easier to review than the patch document.
+// It fails like Apply if an operation cannot be applied; doc is not modified.
+// Example output:
+//
+//	replace /port: 8080 -> 9090
+//	remove /tags/1: "beta"
+//	add /debug: true
+//	move /servers/0 -> /servers/2: {"host":"a"}
+func (p Patch) Describe(doc *parser.AstNode) (string, error) {
+	var b strings.Builder
+	current := doc.Clone()
+	for i, op := range p {
+		line, err := op.describe(current)
+		if err == nil {
+			current, err = op.apply(current)
+		}
+		if err != nil {
+			return "", &Error{Index: i, Op: op.Op, Pos: op.Pos, Err: err}
+		}
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+	return b.String(), nil
+}
+
+// describe renders the operation as it applies to doc, before it is applied.
+func (op Operation) describe(doc *parser.AstNode) (string, error) {
+	switch op.Op {
+	case OpAdd, OpTest:
+		return op.Op + " " + displayPath(op.Path) + ": " + displayValue(op.Value), nil
+	case OpRemove, OpReplace:
+		old, err := op.Path.Get(doc)
+		if err != nil {
+			return "", err
+		}
+		if op.Op == OpRemove {
+			return op.Op + " " + displayPath(op.Path) + ": " + displayValue(old), nil
+		}
+		return op.Op + " " + displayPath(op.Path) + ": " + displayValue(old) + " -> " + displayValue(op.Value), nil
+	case OpMove, OpCopy:
+		value, err := op.From.Get(doc)
+		if err != nil {
+			return "", err
+		}
+		return op.Op + " " + displayPath(op.From) + " -> " + displayPath(op.Path) + ": " + displayValue(value), nil
+	default:
+		return "", ErrInvalidPatch
+	}
+}
+
+// displayPath returns the pointer as written, or "" for the whole document.
+func displayPath(p pointer.Pointer) string {
+	if p.IsRoot() {
+		return `""`
+	}
+	return p.String()
+}
+
+// displayValue returns the compact JSON of a value.
+func displayValue(value *parser.AstNode) string {
+	data, err := gojsonp.Marshal(value)
+	if err != nil {
+		return "<" + err.Error() + ">"
+	}
+	return string(data)
+}
@@ -0,0 +1,273 @@
+package patch
+
+import (
+	"errors"
+	"github.com/onerciller/gojsonp"
+	"github.com/onerciller/gojsonp/parser"
+	"github.com/onerciller/gojsonp/pointer"
+	"github.com/onerciller/gojsonp/token"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// TestDiff tests that the patch computed by Diff is minimal and turns the original into the modified document.
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		original string
+		modified string
+		moves    bool
+		want     string
+	}{
+		{name: "Equal", original: `{"a": [1, {"b": 2}]}`, modified: `{"a": [1.0, {"b": 2}]}`, want: `[]`},
+		{name: "Root scalar", original: `1`, modified: `"1"`, want: `[{"op": "replace", "path": "", "value": "1"}]`},
+		{name: "Root type", original: `{"a": 1}`, modified: `[1]`, want: `[{"op": "replace", "path": "", "value": [1]}]`},
+		{
+			name:     "Object members",
+			original: `{"a": 1, "b": 2, "c": {"d": 3}}`,
+			modified: `{"b": 3, "c": {"d": 3, "e/f": null}, "g": true}`,
+			want: `[{"op": "remove", "path": "/a"}, {"op": "replace", "path": "/b", "value": 3},
+				{"op": "add", "path": "/c/e~1f", "value": null}, {"op": "add", "path": "/g", "value": true}]`,
+		},
+		{
+			name:     "Array append",
+			original: `{"a": 1, "b": [1, 2]}`,
+			modified: `{"a": 2, "b": [1, 2, 3]}`,
+			want:     `[{"op": "replace", "path": "/a", "value": 2}, {"op": "add", "path": "/b/2", "value": 3}]`,
+		},
+		{
+			name:     "Array insert and remove",
+			original: `[1, 2, 3, 4]`,
+			modified: `[0, 1, 3, 4]`,
+			want:     `[{"op": "remove", "path": "/1"}, {"op": "add", "path": "/0", "value": 0}]`,
+		},
+		{
+			name:     "Array element changed in place",
+			original: `[{"id": 1, "on": true}, {"id": 2, "on": true}]`,
+			modified: `[{"id": 1, "on": true}, {"id": 2, "on": false}]`,
+			want:     `[{"op": "replace", "path": "/1/on", "value": false}]`,
+		},
+		{
+			name:     "Array removals from the end",
+			original: `[1, 2, 3, 4, 5]`,
+			modified: `[2, 4]`,
+			want:     `[{"op": "remove", "path": "/4"}, {"op": "remove", "path": "/2"}, {"op": "remove", "path": "/0"}]`,
+		},
+		{
+			name:     "Array reorder without moves",
+			original: `["x", "y", "z"]`,
+			modified: `["y", "z", "x"]`,
+			want:     `[{"op": "remove", "path": "/0"}, {"op": "add", "path": "/2", "value": "x"}]`,
+		},
+		{
+			name:     "Array reorder with moves",
+			original: `["x", "y", "z"]`,
+			modified: `["y", "z", "x"]`,
+			moves:    true,
+			want:     `[{"op": "move", "from": "/0", "path": "/2"}]`,
+		},
+		{
+			name:     "Array move to front",
+			original: `[{"n": 1}, {"n": 2}, {"n": 3}]`,
+			modified: `[{"n": 3}, {"n": 1}, {"n": 2}]`,
+			moves:    true,
+			want:     `[{"op": "move", "from": "/2", "path": "/0"}]`,
+		},
+		{
+			name:     "Array moves with changes",
+			original: `["a", "b", "c", "d", "e"]`,
+			modified: `["e", "b", "x", "a", "d", "y"]`,
+			moves:    true,
+			want: `[{"op": "move", "from": "/4", "path": "/0"}, {"op": "move", "from": "/1", "path": "/3"},
+				{"op": "replace", "path": "/2", "value": "x"}, {"op": "add", "path": "/5", "value": "y"}]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original, modified := parse(t, tt.original), parse(t, tt.modified)
+			var opts []DiffOption
+			if tt.moves {
+				opts = append(opts, DetectMoves())
+			}
+			got := Diff(original, modified, opts...)
+			if !parser.Equal(got.Node(), parse(t, tt.want)) {
+				data, _ := gojsonp.Marshal(got.Node())
+				t.Errorf("Diff() got = %s, want %s", data, tt.want)
+			}
+			patched, err := got.Apply(original)
+			if err != nil {
+				t.Fatalf("Apply() error = %v", err)
+			}
+			if !parser.Equal(patched, modified) {
+				t.Errorf("Apply(Diff()) got = %v, want %s", parser.NodeToValue(patched), tt.modified)
+			}
+			if !parser.Equal(original, parse(t, tt.original)) {
+				t.Errorf("Diff() changed the original document")
+			}
+		})
+	}
+}
+
+// TestDiffRoundTrip tests that applying the computed patch gives the modified document, with and without moves.
+func TestDiffRoundTrip(t *testing.T) {
+	tests := []struct {
+		original, modified string
+	}{
+		{original: `[]`, modified: `[1, 2, 3]`},
+		{original: `[1, 2, 3]`, modified: `[]`},
+		{original: `[1, 2, 3, 4, 5, 6]`, modified: `[6, 5, 4, 3, 2, 1]`},
+		{original: `[1, 1, 2, 2, 3]`, modified: `[2, 1, 3, 1, 2, 2]`},
+		{original: `[1, 2, 3, 4]`, modified: `[4, 7, 2, 8, 1]`},
+		{original: `[[1, 2], [3, 4], [5]]`, modified: `[[5], [1, 2, 0], [4, 3]]`},
+		{original: `{"a": [{"b": [1, 2]}, 3], "c": {"d": [4]}}`, modified: `{"a": [3, {"b": [2, 1]}], "c": {"d": []}, "e": {}}`},
+		{original: `{"": [1], "~": {"/": 2}}`, modified: `{"": [], "~": {"/": 3}}`},
+	}
+
+	for _, tt := range tests {
+		for _, moves := range []bool{false, true} {
+			original, modified := parse(t, tt.original), parse(t, tt.modified)
+			var opts []DiffOption
+			if moves {
+				opts = append(opts, DetectMoves())
+			}
+			p := Diff(original, modified, opts...)
+			patched, err := p.Apply(original)
+			if err != nil {
+				t.Fatalf("Apply(Diff(%s, %s)) error = %v", tt.original, tt.modified, err)
+			}
+			if !parser.Equal(patched, modified) {
+				t.Errorf("Apply(Diff(%s, %s)) with moves %v got = %v", tt.original, tt.modified, moves, parser.NodeToValue(patched))
+			}
+		}
+	}
+}
+
+// TestDiffLargeArrays tests that diffing large arrays needs memory linear in their length
+// and still gives a patch listing only the changes.
+func TestDiffLargeArrays(t *testing.T) {
+	tests := []struct {
+		name    string
+		length  int
+		changed func(i int) bool
+		inserts bool
+		ops     int
+	}{
+		{name: "One change in the middle", length: 10000, changed: func(i int) bool { return i == 5000 }, ops: 1},
+		{name: "Insertions throughout", length: 3000, changed: func(i int) bool { return i%100 == 0 }, inserts: true, ops: 30},
+		{name: "Changes throughout", length: 10000, changed: func(i int) bool { return i%100 == 0 || i == 9999 }, ops: 101},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var a, b []string
+			for i := 0; i < tt.length; i++ {
+				element := strconv.Quote("element " + strconv.Itoa(i))
+				a = append(a, element)
+				if tt.changed(i) {
+					b = append(b, `"changed"`)
+					if !tt.inserts {
+						continue
+					}
+				}
+				b = append(b, element)
+			}
+			original := parse(t, "["+strings.Join(a, ",")+"]")
+			modified := parse(t, "["+strings.Join(b, ",")+"]")
+
+			var before, after runtime.MemStats
+			runtime.ReadMemStats(&before)
+			p := Diff(original, modified)
+			runtime.ReadMemStats(&after)
+			if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 10<<20 {
+				t.Errorf("Diff() allocated %d bytes", allocated)
+			}
+			if len(p) != tt.ops {
+				t.Errorf("Diff() got %d operations, want %d", len(p), tt.ops)
+			}
+			patched, err := p.Apply(original)
+			if err != nil {
+				t.Fatalf("Apply() error = %v", err)
+			}
+			if !parser.Equal(patched, modified) {
+				t.Errorf("Apply(Diff()) does not give the modified document")
+			}
+		})
+	}
+}
+
+// TestNode tests that a patch converted to a document decodes back to the same operations.
+func TestNode(t *testing.T) {
+	input := `[{"op":"add","path":"/a~1b","value":[1]},{"op":"remove","path":"/c"},{"op":"move","path":"/d","from":"/e"},{"op":"test","path":"","value":null}]`
+	p, err := Decode([]byte(input))
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	data, err := gojsonp.Marshal(p.Node())
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if string(data) != input {
+		t.Errorf("Marshal(Node()) got = %s, want %s", data, input)
+	}
+}
+
+// TestDescribe tests the human-readable rendering of a patch.
+func TestDescribe(t *testing.T) {
+	original := parse(t, `{"port": 8080, "tags": ["stable", "beta"], "servers": [{"host": "a"}, {"host": "b"}, {"host": "c"}]}`)
+	modified := parse(t, `{"port": 9090, "tags": ["stable"], "servers": [{"host": "b"}, {"host": "c"}, {"host": "a"}], "debug": true}`)
+	got, err := Diff(original, modified, DetectMoves()).Describe(original)
+	if err != nil {
+		t.Fatalf("Describe() error = %v", err)
+	}
+	want := `replace /port: 8080 -> 9090
+remove /tags/1: "beta"
+move /servers/0 -> /servers/2: {"host":"a"}
+add /debug: true
+`
+	if got != want {
+		t.Errorf("Describe() got =\n%s\nwant\n%s", got, want)
+	}
+
+	p, _ := Decode([]byte(`[{"op": "copy", "from": "/port", "path": ""}, {"op": "test", "path": "", "value": 8080}]`))
+	if got, err := p.Describe(original); err != nil || got != "copy /port -> \"\": 8080\ntest \"\": 8080\n" {
+		t.Errorf("Describe() got = %q, %v", got, err)
+	}
+	p, _ = Decode([]byte(`[{"op": "remove", "path": "/missing"}]`))
+	if _, err := p.Describe(original); err == nil {
+		t.Errorf("Describe() expected an error for a missing member")
+	}
+}
+
+// TestNonFinite tests that Diff and the test operation handle the JSON5 values NaN and Infinity:
+// NaN equals no value, not even itself, and Infinity equals itself.
+func TestNonFinite(t *testing.T) {
+	parseJSON5 := func(input string) *parser.AstNode {
+		tokens, err := token.Tokenizer([]byte(input), token.JSON5())
+		if err != nil {
+			t.Fatalf("Tokenizer() error = %v", err)
+		}
+		root, err := parser.Parse(tokens, parser.JSON5())
+		if err != nil {
+			t.Fatalf("Parse() error = %v", err)
+		}
+		return root
+	}
+	original := parseJSON5(`[NaN, Infinity, 1]`)
+
+	p := Diff(original, parseJSON5(`[NaN, Infinity, 2]`))
+	if len(p) != 2 || p[0].Path.String() != "/0" || p[1].Path.String() != "/2" {
+		t.Errorf("Diff() got = %v, want replacements of /0 and /2", p)
+	}
+
+	test := Patch{{Op: OpTest, Path: pointer.MustParse("/1"), Value: parseJSON5(`Infinity`)}}
+	if _, err := test.Apply(original); err != nil {
+		t.Errorf("Apply(test Infinity) error = %v", err)
+	}
+	test = Patch{{Op: OpTest, Path: pointer.MustParse("/0"), Value: parseJSON5(`NaN`)}}
+	if _, err := test.Apply(original); !errors.Is(err, ErrTestFailed) {
+		t.Errorf("Apply(test NaN) error = %v, want %v", err, ErrTestFailed)
+	}
+}
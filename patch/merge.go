@@ -0,0 +1,101 @@
+package patch
+
+import (
+	"errors"
+	"github.com/onerciller/gojsonp/parser"
+	"github.com/onerciller/gojsonp/token"
+)
+
+// ErrMergeNull is returned by CreateMergePatch if the modified document sets an object member to null,
+// which a merge patch cannot express since null removes the member.
+var ErrMergeNull = errors.New("merge patch cannot set an object member to null")
+
+// MergePatch applies a JSON Merge Patch (RFC 7396) to a copy of doc and returns the result:
+// null members of the patch delete, objects merge recursively and any other value replaces.
+// Neither doc nor mergePatch is modified.
+// Example: merging `{"a": null, "b": {"c": 2}}` into `{"a": 1, "b": {"d": 3}}` gives `{"b": {"d": 3, "c": 2}}`.
+func MergePatch(doc, mergePatch *parser.AstNode) *parser.AstNode {
+	return merge(doc.Clone(), mergePatch)
+}
+
+// merge applies mergePatch to target, which it may modify, following the pseudocode of RFC 7396, section 2.
+func merge(target, mergePatch *parser.AstNode) *parser.AstNode {
+	members, ok := mergePatch.Value.(*parser.Object)
+	if !ok {
+		return mergePatch.Clone()
+	}
+	if target == nil || target.Type != token.Object {
+		target = &parser.AstNode{Type: token.Object, Value: parser.NewObject(nil), Start: mergePatch.Start, End: mergePatch.End}
+	}
+	object := target.Value.(*parser.Object)
+	for _, member := range members.Members() {
+		if member.Value.Type == token.Null {
+			object.Delete(member.Key)
+			continue
+		}
+		current, _ := object.Get(member.Key)
+		object.Set(member.Key, merge(current, member.Value))
+	}
+	return target
+}
+
+// CreateMergePatch computes the smallest merge patch that turns original into modified:
+// it lists removed members as null, and added or changed members with their new value,
+// descending into objects present in both documents. Arrays are replaced as a whole.
+// It fails with ErrMergeNull if modified sets an object member to null, as merge patches cannot.
+// Example: from `{"a": 1, "b": {"c": 2, "d": 3}}` to `{"b": {"c": 4, "d": 3}}` gives `{"a": null, "b": {"c": 4}}`.
+func CreateMergePatch(original, modified *parser.AstNode) (*parser.AstNode, error) {
+	from, okFrom := original.Value.(*parser.Object)
+	to, okTo := modified.Value.(*parser.Object)
+	if !okFrom || !okTo {
+		if err := checkMergeValue(modified); err != nil {
+			return nil, err
+		}
+		return modified.Clone(), nil
+	}
+
+	var members []*parser.Member
+	for _, member := range from.Members() {
+		if _, ok := to.Get(member.Key); !ok {
+			members = append(members, &parser.Member{Key: member.Key, Value: &parser.AstNode{Type: token.Null}})
+		}
+	}
+	for _, member := range to.Members() {
+		current, ok := from.Get(member.Key)
+		if ok && parser.Equal(current, member.Value) {
+			continue
+		}
+		if member.Value.Type == token.Null {
+			return nil, ErrMergeNull
+		}
+		var value *parser.AstNode
+		var err error
+		if ok {
+			value, err = CreateMergePatch(current, member.Value)
+		} else {
+			value, err = member.Value.Clone(), checkMergeValue(member.Value)
+		}
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, &parser.Member{Key: member.Key, Value: value})
+	}
+	return &parser.AstNode{Type: token.Object, Value: parser.NewObject(members)}, nil
+}
+
+// checkMergeValue fails with ErrMergeNull if value has an object member that is null at any depth;
+// merging such a value would drop the member instead of setting it.
+func checkMergeValue(value *parser.AstNode) error {
+	switch container := value.Value.(type) {
+	case *parser.Object:
+		for _, member := range container.Members() {
+			if member.Value.Type == token.Null {
+				return ErrMergeNull
+			}
+			if err := checkMergeValue(member.Value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
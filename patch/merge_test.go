@@ -0,0 +1,112 @@
+package patch
+
+import (
+	"errors"
+	"github.com/onerciller/gojsonp/parser"
+	"testing"
+)
+
+// TestMergePatch tests the examples of RFC 7396, appendix A.
+func TestMergePatch(t *testing.T) {
+	tests := []struct {
+		doc, patch, want string
+	}{
+		{doc: `{"a":"b"}`, patch: `{"a":"c"}`, want: `{"a":"c"}`},
+		{doc: `{"a":"b"}`, patch: `{"b":"c"}`, want: `{"a":"b","b":"c"}`},
+		{doc: `{"a":"b"}`, patch: `{"a":null}`, want: `{}`},
+		{doc: `{"a":"b","b":"c"}`, patch: `{"a":null}`, want: `{"b":"c"}`},
+		{doc: `{"a":["b"]}`, patch: `{"a":"c"}`, want: `{"a":"c"}`},
+		{doc: `{"a":"c"}`, patch: `{"a":["b"]}`, want: `{"a":["b"]}`},
+		{doc: `{"a":{"b":"c"}}`, patch: `{"a":{"b":"d","c":null}}`, want: `{"a":{"b":"d"}}`},
+		{doc: `{"a":[{"b":"c"}]}`, patch: `{"a":[1]}`, want: `{"a":[1]}`},
+		{doc: `["a","b"]`, patch: `["c","d"]`, want: `["c","d"]`},
+		{doc: `{"a":"b"}`, patch: `["c"]`, want: `["c"]`},
+		{doc: `{"a":"foo"}`, patch: `null`, want: `null`},
+		{doc: `{"a":"foo"}`, patch: `"bar"`, want: `"bar"`},
+		{doc: `{"e":null}`, patch: `{"a":1}`, want: `{"e":null,"a":1}`},
+		{doc: `[1,2]`, patch: `{"a":"b","c":null}`, want: `{"a":"b"}`},
+		{doc: `{}`, patch: `{"a":{"bb":{"ccc":null}}}`, want: `{"a":{"bb":{}}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.doc+" "+tt.patch, func(t *testing.T) {
+			doc := parse(t, tt.doc)
+			mergePatch := parse(t, tt.patch)
+			got := MergePatch(doc, mergePatch)
+			if !parser.Equal(got, parse(t, tt.want)) {
+				t.Errorf("MergePatch() got = %v, want %s", parser.NodeToValue(got), tt.want)
+			}
+			if !parser.Equal(doc, parse(t, tt.doc)) || !parser.Equal(mergePatch, parse(t, tt.patch)) {
+				t.Errorf("MergePatch() changed its arguments")
+			}
+		})
+	}
+}
+
+// TestMergePatchOrder tests that merged members keep their place and new members are appended.
+func TestMergePatchOrder(t *testing.T) {
+	got := MergePatch(parse(t, `{"z": 1, "a": {"y": 2, "b": 3}}`), parse(t, `{"a": {"b": 4, "c": 5}, "m": 6}`))
+	object := got.Value.(*parser.Object)
+	inner, _ := object.Get("a")
+	if keys := object.Keys(); len(keys) != 3 || keys[0] != "z" || keys[1] != "a" || keys[2] != "m" {
+		t.Errorf("Keys() = %v, want [z a m]", keys)
+	}
+	if keys := inner.Value.(*parser.Object).Keys(); len(keys) != 3 || keys[0] != "y" || keys[2] != "c" {
+		t.Errorf("Keys() = %v, want [y b c]", keys)
+	}
+}
+
+// TestCreateMergePatch tests that generated merge patches are minimal and turn the original into the modified document.
+func TestCreateMergePatch(t *testing.T) {
+	tests := []struct {
+		name, original, modified, want string
+	}{
+		{name: "Equal", original: `{"a": 1}`, modified: `{"a": 1.0}`, want: `{}`},
+		{name: "Added", original: `{"a": 1}`, modified: `{"a": 1, "b": [null]}`, want: `{"b": [null]}`},
+		{name: "Removed", original: `{"a": 1, "b": 2}`, modified: `{"b": 2}`, want: `{"a": null}`},
+		{name: "Changed", original: `{"a": "x"}`, modified: `{"a": "y"}`, want: `{"a": "y"}`},
+		{
+			name:     "Nested",
+			original: `{"title": "Goodbye!", "author": {"givenName": "John", "familyName": "Doe"}, "tags": ["example", "sample"], "content": "This will be unchanged"}`,
+			modified: `{"title": "Hello!", "author": {"givenName": "John"}, "tags": ["example"], "content": "This will be unchanged", "phoneNumber": "+01-123-456-7890"}`,
+			want:     `{"title": "Hello!", "author": {"familyName": null}, "tags": ["example"], "phoneNumber": "+01-123-456-7890"}`,
+		},
+		{name: "Array to object", original: `[1]`, modified: `{"a": {"b": 1}}`, want: `{"a": {"b": 1}}`},
+		{name: "Object to scalar", original: `{"a": {"b": 1}}`, modified: `{"a": true}`, want: `{"a": true}`},
+		{name: "Scalar root", original: `1`, modified: `2`, want: `2`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original, modified := parse(t, tt.original), parse(t, tt.modified)
+			got, err := CreateMergePatch(original, modified)
+			if err != nil {
+				t.Fatalf("CreateMergePatch() error = %v", err)
+			}
+			if !parser.Equal(got, parse(t, tt.want)) {
+				t.Errorf("CreateMergePatch() got = %v, want %s", parser.NodeToValue(got), tt.want)
+			}
+			if merged := MergePatch(original, got); !parser.Equal(merged, modified) {
+				t.Errorf("MergePatch(CreateMergePatch()) got = %v, want %s", parser.NodeToValue(merged), tt.modified)
+			}
+		})
+	}
+}
+
+// TestCreateMergePatchNull tests that null object members, which a merge patch cannot set, are rejected.
+func TestCreateMergePatchNull(t *testing.T) {
+	tests := []struct {
+		original, modified string
+	}{
+		{original: `{"a": 1}`, modified: `{"a": null}`},
+		{original: `{}`, modified: `{"a": null}`},
+		{original: `{}`, modified: `{"a": {"b": null}}`},
+		{original: `[]`, modified: `{"a": {"b": null}}`},
+	}
+
+	for _, tt := range tests {
+		if _, err := CreateMergePatch(parse(t, tt.original), parse(t, tt.modified)); !errors.Is(err, ErrMergeNull) {
+			t.Errorf("CreateMergePatch(%s, %s) error = %v, want ErrMergeNull", tt.original, tt.modified, err)
+		}
+	}
+}
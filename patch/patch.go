@@ -0,0 +1,209 @@
+// Package patch implements JSON Patch (RFC 6902) and JSON Merge Patch (RFC 7396) on parsed documents.
+// A patch is an array of operations such as {"op": "add", "path": "/a", "value": 1};
+// Apply runs them all on a copy of the document, so it is either fully patched or left as it was.
+// Diff computes the patch between two documents, and Describe renders a patch for review.
+// A merge patch is a document shaped like the target, see MergePatch and CreateMergePatch.
+package patch
+
+import (
+	"errors"
+	"fmt"
+	"github.com/onerciller/gojsonp/parser"
+	"github.com/onerciller/gojsonp/pointer"
+	"github.com/onerciller/gojsonp/token"
+)
+
+// Kinds of patch errors; use errors.Is to tell them apart.
+// Errors of the pointers an operation refers to are wrapped as well, e.g. pointer.ErrNotFound.
+var (
+	ErrInvalidPatch = errors.New("invalid patch")
+	ErrTestFailed   = errors.New("test failed")
+	ErrMoveIntoSelf = errors.New("cannot move a value into itself")
+)
+
+// The operations of RFC 6902.
+const (
+	OpAdd     = "add"
+	OpRemove  = "remove"
+	OpReplace = "replace"
+	OpMove    = "move"
+	OpCopy    = "copy"
+	OpTest    = "test"
+)
+
+// Operation is a single operation of a patch.
+type Operation struct {
+
+	// Op is one of OpAdd, OpRemove, OpReplace, OpMove, OpCopy and OpTest
+	Op string
+
+	// Path is the location the operation applies to
+	Path pointer.Pointer
+
+	// From is the location a move or copy reads from
+	From pointer.Pointer
+
+	// Value is the value to add, replace with or test against
+	Value *parser.AstNode
+
+	// Pos is the position of the operation in the patch document, if it was decoded
+	Pos token.Position
+}
+
+// Patch is a sequence of operations, applied in order.
+type Patch []Operation
+
+// Error describes an operation that is malformed or cannot be applied.
+type Error struct {
+
+	// Index is the index of the failing operation in the patch, or -1 if the patch itself is malformed
+	Index int
+
+	// Op is the name of the failing operation, if known
+	Op string
+
+	// Pos is the position of the failing operation in the patch document, if it was decoded
+	Pos token.Position
+
+	// Err describes the failure; it wraps one of the Err* kinds or a pointer error
+	Err error
+}
+
+// Error method for Error to get a human-readable description, e.g.
+// `operation 2 (replace) at 4:3: 1:1: pointer "/a": segment 0 "a": value not found`,
+// where 4:3 locates the operation in the patch and 1:1 the value in the document it was applied to.
+// Positions are left out if they are unknown.
+func (e *Error) Error() string {
+	if e.Index < 0 {
+		return fmt.Sprintf("patch: %s", e.Err)
+	}
+	msg := fmt.Sprintf("operation %d", e.Index)
+	if e.Op != "" {
+		msg += fmt.Sprintf(" (%s)", e.Op)
+	}
+	if e.Pos.Line > 0 {
+		msg += " at " + e.Pos.String()
+	}
+	return msg + ": " + e.Err.Error()
+}
+
+// Unwrap returns the cause of the error, so errors.Is(err, ErrTestFailed) works.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Decode parses a patch document.
+// Syntax errors are returned as a *token.SyntaxError, malformed operations as an *Error of kind ErrInvalidPatch.
+// Example: Decode([]byte(`[{"op": "remove", "path": "/a/0"}]`))
+func Decode(data []byte) (Patch, error) {
+	tokens, err := token.Tokenizer(data)
+	if err != nil {
+		return nil, err
+	}
+	root, err := parser.Parse(tokens)
+	if err != nil {
+		return nil, err
+	}
+	return FromNode(root)
+}
+
+// FromNode reads a patch from a parsed patch document.
+// Members of an operation other than "op", "path", "from" and "value" are ignored.
+func FromNode(root *parser.AstNode) (Patch, error) {
+	elements, ok := root.Value.([]*parser.AstNode)
+	if !ok {
+		return nil, &Error{Index: -1, Pos: root.Start, Err: fmt.Errorf("%w: a patch must be an array", ErrInvalidPatch)}
+	}
+	patch := make(Patch, len(elements))
+	for i, element := range elements {
+		op, err := decodeOperation(element)
+		if err != nil {
+			return nil, &Error{Index: i, Op: op.Op, Pos: element.Start, Err: err}
+		}
+		patch[i] = op
+	}
+	return patch, nil
+}
+
+// Node returns the patch document, the inverse of FromNode; encode it with gojsonp.Marshal to get its JSON.
+// Example: `[{"op": "move", "path": "/b", "from": "/a"}]`
+func (p Patch) Node() *parser.AstNode {
+	elements := make([]*parser.AstNode, len(p))
+	for i, op := range p {
+		members := []*parser.Member{
+			{Key: "op", Value: &parser.AstNode{Type: token.String, Value: op.Op}},
+			{Key: "path", Value: &parser.AstNode{Type: token.String, Value: op.Path.String()}},
+		}
+		switch op.Op {
+		case OpMove, OpCopy:
+			members = append(members, &parser.Member{Key: "from", Value: &parser.AstNode{Type: token.String, Value: op.From.String()}})
+		case OpAdd, OpReplace, OpTest:
+			members = append(members, &parser.Member{Key: "value", Value: op.Value.Clone()})
+		}
+		elements[i] = &parser.AstNode{Type: token.Object, Value: parser.NewObject(members)}
+	}
+	return &parser.AstNode{Type: token.Array, Value: elements}
+}
+
+// decodeOperation reads one operation object and checks that it has the members its op requires.
+func decodeOperation(node *parser.AstNode) (Operation, error) {
+	op := Operation{Pos: node.Start}
+	object, ok := node.Value.(*parser.Object)
+	if !ok {
+		return op, fmt.Errorf("%w: an operation must be an object", ErrInvalidPatch)
+	}
+
+	name, err := stringMember(object, "op")
+	if err != nil {
+		return op, err
+	}
+	switch name {
+	case OpAdd, OpRemove, OpReplace, OpMove, OpCopy, OpTest:
+		op.Op = name
+	default:
+		return op, fmt.Errorf("%w: unknown op %q", ErrInvalidPatch, name)
+	}
+
+	if op.Path, err = pointerMember(object, "path"); err != nil {
+		return op, err
+	}
+	switch op.Op {
+	case OpMove, OpCopy:
+		if op.From, err = pointerMember(object, "from"); err != nil {
+			return op, err
+		}
+	case OpAdd, OpReplace, OpTest:
+		value, ok := object.Get("value")
+		if !ok {
+			return op, fmt.Errorf("%w: missing \"value\"", ErrInvalidPatch)
+		}
+		op.Value = value
+	}
+	return op, nil
+}
+
+// stringMember returns the string value of a required member.
+func stringMember(object *parser.Object, key string) (string, error) {
+	value, ok := object.Get(key)
+	if !ok {
+		return "", fmt.Errorf("%w: missing %q", ErrInvalidPatch, key)
+	}
+	s, ok := value.Value.(string)
+	if !ok || value.Type != token.String {
+		return "", fmt.Errorf("%w: %q must be a string", ErrInvalidPatch, key)
+	}
+	return s, nil
+}
+
+// pointerMember returns the parsed pointer held by a required member.
+func pointerMember(object *parser.Object, key string) (pointer.Pointer, error) {
+	s, err := stringMember(object, key)
+	if err != nil {
+		return pointer.Pointer{}, err
+	}
+	p, err := pointer.Parse(s)
+	if err != nil {
+		return pointer.Pointer{}, fmt.Errorf("%w: %w", ErrInvalidPatch, err)
+	}
+	return p, nil
+}
@@ -0,0 +1,74 @@
+package patch
+
+import (
+	"errors"
+	"github.com/onerciller/gojsonp/pointer"
+	"github.com/onerciller/gojsonp/token"
+	"testing"
+)
+
+// TestDecode tests that operations are read with their pointers and values.
+func TestDecode(t *testing.T) {
+	p, err := Decode([]byte(`[
+  {"op": "move", "from": "/a~1b", "path": "/c/0"},
+  {"op": "add", "path": "", "value": null}
+]`))
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if len(p) != 2 {
+		t.Fatalf("Decode() got %d operations, want 2", len(p))
+	}
+	if p[0].Op != OpMove || p[0].From.String() != "/a~1b" || p[0].Path.String() != "/c/0" || p[0].Pos.Line != 2 {
+		t.Errorf("Decode() got = %+v", p[0])
+	}
+	if p[1].Op != OpAdd || !p[1].Path.IsRoot() || p[1].Value == nil || p[1].Value.Type != token.Null {
+		t.Errorf("Decode() got = %+v", p[1])
+	}
+}
+
+// TestDecodeErrors tests that malformed operations are reported with their index.
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		patch string
+		want  string
+	}{
+		{name: "Not an array", patch: `{"op": "add"}`, want: `patch: invalid patch: a patch must be an array`},
+		{name: "Not an object", patch: `[1]`, want: `operation 0 at 1:2: invalid patch: an operation must be an object`},
+		{name: "Missing op", patch: `[{"path": "/a"}]`, want: `operation 0 at 1:2: invalid patch: missing "op"`},
+		{name: "Unknown op", patch: `[{"op": "append", "path": "/a"}]`, want: `operation 0 at 1:2: invalid patch: unknown op "append"`},
+		{name: "Op not a string", patch: `[{"op": 1, "path": "/a"}]`, want: `operation 0 at 1:2: invalid patch: "op" must be a string`},
+		{
+			name:  "Missing path",
+			patch: `[{"op": "test", "path": "", "value": 1}, {"op": "remove"}]`,
+			want:  `operation 1 (remove) at 1:42: invalid patch: missing "path"`,
+		},
+		{name: "Missing from", patch: `[{"op": "copy", "path": "/a"}]`, want: `operation 0 (copy) at 1:2: invalid patch: missing "from"`},
+		{name: "Missing value", patch: `[{"op": "add", "path": "/a"}]`, want: `operation 0 (add) at 1:2: invalid patch: missing "value"`},
+		{
+			name:  "Malformed pointer",
+			patch: `[{"op": "remove", "path": "a"}]`,
+			want:  `operation 0 (remove) at 1:2: invalid patch: pointer "a": invalid pointer`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Decode([]byte(tt.patch))
+			if !errors.Is(err, ErrInvalidPatch) {
+				t.Fatalf("Decode() error = %v, want ErrInvalidPatch", err)
+			}
+			if err.Error() != tt.want {
+				t.Errorf("Decode() error = %q, want %q", err.Error(), tt.want)
+			}
+		})
+	}
+
+	if _, err := Decode([]byte(`[{"op": "add",]`)); !errors.Is(err, token.ErrUnexpectedToken) {
+		t.Errorf("Decode() error = %v, want token.ErrUnexpectedToken", err)
+	}
+	if _, err := Decode([]byte(`[{"op": "remove", "path": "/a~"}]`)); !errors.Is(err, pointer.ErrSyntax) {
+		t.Errorf("Decode() error = %v, want pointer.ErrSyntax", err)
+	}
+}
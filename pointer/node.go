@@ -0,0 +1,150 @@
+package pointer
+
+import (
+	"github.com/onerciller/gojsonp/parser"
+	"github.com/onerciller/gojsonp/token"
+)
+
+// Get returns the node the pointer refers to in the document root.
+// Example: For `{"a": [1, 2]}`, MustParse("/a/1").Get(root) returns the Number node 2.
+func (p Pointer) Get(root *parser.AstNode) (*parser.AstNode, error) {
+	node := root
+	for segment := range p.tokens {
+		child, err := p.child(node, segment)
+		if err != nil {
+			return nil, err
+		}
+		node = child
+	}
+	return node, nil
+}
+
+// Set replaces the value the pointer refers to and returns the new root.
+// A missing object member is created, but an array element must exist.
+// Containers are modified in place; their End positions are not updated.
+func (p Pointer) Set(root, value *parser.AstNode) (*parser.AstNode, error) {
+	if p.IsRoot() {
+		return value, nil
+	}
+	parent, err := p.Parent().Get(root)
+	if err != nil {
+		return nil, err
+	}
+	last := len(p.tokens) - 1
+	switch container := containerOf(parent).(type) {
+	case *parser.Object:
+		container.Set(p.tokens[last], value)
+	case []*parser.AstNode:
+		index, err := arrayIndex(p.tokens[last], len(container), false)
+		if err != nil {
+			return nil, p.fail(err, last, parent.Start)
+		}
+		container[index] = value
+	default:
+		return nil, p.fail(ErrNotContainer, last, positionOf(parent))
+	}
+	return root, nil
+}
+
+// Add inserts value at the location the pointer refers to and returns the new root, as JSON Patch "add" does:
+// an object member is created or replaced, and in an array the value is inserted before the element
+// at the index, or appended for the index "-".
+// Example: For `[1, 3]`, MustParse("/1").Add(root, two) gives `[1, 2, 3]`.
+func (p Pointer) Add(root, value *parser.AstNode) (*parser.AstNode, error) {
+	if p.IsRoot() {
+		return value, nil
+	}
+	parent, err := p.Parent().Get(root)
+	if err != nil {
+		return nil, err
+	}
+	last := len(p.tokens) - 1
+	switch container := containerOf(parent).(type) {
+	case *parser.Object:
+		container.Set(p.tokens[last], value)
+	case []*parser.AstNode:
+		index, err := arrayIndex(p.tokens[last], len(container), true)
+		if err != nil {
+			return nil, p.fail(err, last, parent.Start)
+		}
+		elements := make([]*parser.AstNode, 0, len(container)+1)
+		elements = append(append(append(elements, container[:index]...), value), container[index:]...)
+		parent.Value = elements
+	default:
+		return nil, p.fail(ErrNotContainer, last, positionOf(parent))
+	}
+	return root, nil
+}
+
+// Remove deletes the value the pointer refers to and returns it.
+// Later array elements shift down by one; the root itself cannot be removed.
+func (p Pointer) Remove(root *parser.AstNode) (*parser.AstNode, error) {
+	if p.IsRoot() {
+		return nil, &Error{Kind: ErrRoot, Pointer: "", Segment: -1}
+	}
+	parent, err := p.Parent().Get(root)
+	if err != nil {
+		return nil, err
+	}
+	last := len(p.tokens) - 1
+	switch container := containerOf(parent).(type) {
+	case *parser.Object:
+		removed, ok := container.Get(p.tokens[last])
+		if !ok {
+			return nil, p.fail(ErrNotFound, last, parent.Start)
+		}
+		container.Delete(p.tokens[last])
+		return removed, nil
+	case []*parser.AstNode:
+		index, err := arrayIndex(p.tokens[last], len(container), false)
+		if err != nil {
+			return nil, p.fail(err, last, parent.Start)
+		}
+		elements := make([]*parser.AstNode, 0, len(container)-1)
+		parent.Value = append(append(elements, container[:index]...), container[index+1:]...)
+		return container[index], nil
+	default:
+		return nil, p.fail(ErrNotContainer, last, positionOf(parent))
+	}
+}
+
+// child returns the member or array element of node selected by the reference token at segment.
+func (p Pointer) child(node *parser.AstNode, segment int) (*parser.AstNode, error) {
+	tk := p.tokens[segment]
+	switch container := containerOf(node).(type) {
+	case *parser.Object:
+		child, ok := container.Get(tk)
+		if !ok {
+			return nil, p.fail(ErrNotFound, segment, node.Start)
+		}
+		return child, nil
+	case []*parser.AstNode:
+		index, err := arrayIndex(tk, len(container), false)
+		if err != nil {
+			return nil, p.fail(err, segment, node.Start)
+		}
+		return container[index], nil
+	default:
+		return nil, p.fail(ErrNotContainer, segment, positionOf(node))
+	}
+}
+
+// containerOf returns the *parser.Object or []*parser.AstNode held by node, or nil for scalars.
+func containerOf(node *parser.AstNode) interface{} {
+	if node == nil {
+		return nil
+	}
+	switch container := node.Value.(type) {
+	case *parser.Object, []*parser.AstNode:
+		return container
+	}
+	return nil
+}
+
+// positionOf returns the start of node, or the zero Position for a nil node.
+func positionOf(node *parser.AstNode) token.Position {
+	if node == nil {
+		return token.Position{}
+	}
+	return node.Start
+}
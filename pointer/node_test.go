@@ -0,0 +1,266 @@
+package pointer
+
+import (
+	"errors"
+	"github.com/onerciller/gojsonp/parser"
+	"github.com/onerciller/gojsonp/token"
+	"reflect"
+	"testing"
+)
+
+// rfcDocument is the example document of RFC 6901, section 5.
+const rfcDocument = `{
+  "foo": ["bar", "baz"],
+  "": 0,
+  "a/b": 1,
+  "c%d": 2,
+  "e^f": 3,
+  "g|h": 4,
+  "i\\j": 5,
+  "k\"l": 6,
+  " ": 7,
+  "m~n": 8
+}`
+
+// TestGet tests the examples of RFC 6901 on an AST.
+func TestGet(t *testing.T) {
+	root := parse(t, rfcDocument)
+	tests := []struct {
+		pointer string
+		want    interface{}
+	}{
+		{pointer: ``, want: parser.NodeToValue(root)},
+		{pointer: `/foo`, want: []interface{}{"bar", "baz"}},
+		{pointer: `/foo/0`, want: "bar"},
+		{pointer: `/`, want: float64(0)},
+		{pointer: `/a~1b`, want: float64(1)},
+		{pointer: `/c%d`, want: float64(2)},
+		{pointer: `/e^f`, want: float64(3)},
+		{pointer: `/g|h`, want: float64(4)},
+		{pointer: `/i\j`, want: float64(5)},
+		{pointer: `/k"l`, want: float64(6)},
+		{pointer: `/ `, want: float64(7)},
+		{pointer: `/m~0n`, want: float64(8)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pointer, func(t *testing.T) {
+			node, err := MustParse(tt.pointer).Get(root)
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			if got := parser.NodeToValue(node); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Get() got = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestGetErrors tests that failed lookups report the segment and the position of the value it was applied to.
+func TestGetErrors(t *testing.T) {
+	root := parse(t, "{\n  \"a\": [1, {\"b\": true}]\n}")
+	tests := []struct {
+		pointer string
+		kind    error
+		want    string
+	}{
+		{pointer: `/x`, kind: ErrNotFound, want: `1:1: pointer "/x": segment 0 "x": value not found`},
+		{pointer: `/a/2`, kind: ErrNotFound, want: `2:8: pointer "/a/2": segment 1 "2": value not found`},
+		{pointer: `/a/-`, kind: ErrNotFound, want: `2:8: pointer "/a/-": segment 1 "-": value not found`},
+		{pointer: `/a/01`, kind: ErrInvalidIndex, want: `2:8: pointer "/a/01": segment 1 "01": invalid array index`},
+		{pointer: `/a/1/c`, kind: ErrNotFound, want: `2:12: pointer "/a/1/c": segment 2 "c": value not found`},
+		{pointer: `/a/0/c`, kind: ErrNotContainer, want: `2:9: pointer "/a/0/c": segment 2 "c": value is not an object or array`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pointer, func(t *testing.T) {
+			_, err := MustParse(tt.pointer).Get(root)
+			if !errors.Is(err, tt.kind) {
+				t.Fatalf("Get() error = %v, want %v", err, tt.kind)
+			}
+			if err.Error() != tt.want {
+				t.Errorf("Get() error = %q, want %q", err.Error(), tt.want)
+			}
+		})
+	}
+}
+
+// TestMutations tests Set, Add and Remove on an AST.
+func TestMutations(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		apply   func(root *parser.AstNode) (*parser.AstNode, error)
+		want    interface{}
+		wantErr error
+	}{
+		{
+			name:  "Set member",
+			input: `{"a": {"b": 1}}`,
+			apply: func(root *parser.AstNode) (*parser.AstNode, error) {
+				return MustParse("/a/b").Set(root, number(2))
+			},
+			want: map[string]interface{}{"a": map[string]interface{}{"b": float64(2)}},
+		},
+		{
+			name:  "Set creates member",
+			input: `{}`,
+			apply: func(root *parser.AstNode) (*parser.AstNode, error) {
+				return MustParse("/x").Set(root, number(2))
+			},
+			want: map[string]interface{}{"x": float64(2)},
+		},
+		{
+			name:  "Set element",
+			input: `[1, 2]`,
+			apply: func(root *parser.AstNode) (*parser.AstNode, error) {
+				return MustParse("/1").Set(root, number(3))
+			},
+			want: []interface{}{float64(1), float64(3)},
+		},
+		{
+			name:  "Set past the end",
+			input: `[1, 2]`,
+			apply: func(root *parser.AstNode) (*parser.AstNode, error) {
+				return MustParse("/-").Set(root, number(3))
+			},
+			wantErr: ErrNotFound,
+		},
+		{
+			name:  "Set root",
+			input: `[1]`,
+			apply: func(root *parser.AstNode) (*parser.AstNode, error) {
+				return MustParse("").Set(root, number(3))
+			},
+			want: float64(3),
+		},
+		{
+			name:  "Add inserts element",
+			input: `{"a": [1, 3]}`,
+			apply: func(root *parser.AstNode) (*parser.AstNode, error) {
+				return MustParse("/a/1").Add(root, number(2))
+			},
+			want: map[string]interface{}{"a": []interface{}{float64(1), float64(2), float64(3)}},
+		},
+		{
+			name:  "Add appends element",
+			input: `[1]`,
+			apply: func(root *parser.AstNode) (*parser.AstNode, error) {
+				return MustParse("/-").Add(root, number(2))
+			},
+			want: []interface{}{float64(1), float64(2)},
+		},
+		{
+			name:  "Add at length",
+			input: `[1]`,
+			apply: func(root *parser.AstNode) (*parser.AstNode, error) {
+				return MustParse("/1").Add(root, number(2))
+			},
+			want: []interface{}{float64(1), float64(2)},
+		},
+		{
+			name:  "Add past length",
+			input: `[1]`,
+			apply: func(root *parser.AstNode) (*parser.AstNode, error) {
+				return MustParse("/2").Add(root, number(2))
+			},
+			wantErr: ErrNotFound,
+		},
+		{
+			name:  "Add to missing parent",
+			input: `{}`,
+			apply: func(root *parser.AstNode) (*parser.AstNode, error) {
+				return MustParse("/a/b").Add(root, number(2))
+			},
+			wantErr: ErrNotFound,
+		},
+		{
+			name:  "Add into scalar",
+			input: `{"a": 1}`,
+			apply: func(root *parser.AstNode) (*parser.AstNode, error) {
+				return MustParse("/a/b").Add(root, number(2))
+			},
+			wantErr: ErrNotContainer,
+		},
+		{
+			name:  "Remove member",
+			input: `{"a": 1, "b": 2}`,
+			apply: func(root *parser.AstNode) (*parser.AstNode, error) {
+				_, err := MustParse("/a").Remove(root)
+				return root, err
+			},
+			want: map[string]interface{}{"b": float64(2)},
+		},
+		{
+			name:  "Remove element",
+			input: `[1, 2, 3]`,
+			apply: func(root *parser.AstNode) (*parser.AstNode, error) {
+				_, err := MustParse("/1").Remove(root)
+				return root, err
+			},
+			want: []interface{}{float64(1), float64(3)},
+		},
+		{
+			name:  "Remove missing member",
+			input: `{"a": 1}`,
+			apply: func(root *parser.AstNode) (*parser.AstNode, error) {
+				return MustParse("/b").Remove(root)
+			},
+			wantErr: ErrNotFound,
+		},
+		{
+			name:  "Remove root",
+			input: `{}`,
+			apply: func(root *parser.AstNode) (*parser.AstNode, error) {
+				return MustParse("").Remove(root)
+			},
+			wantErr: ErrRoot,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := tt.apply(parse(t, tt.input))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := parser.NodeToValue(root); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestRemoveReturnsValue tests that Remove returns the removed node with its position.
+func TestRemoveReturnsValue(t *testing.T) {
+	root := parse(t, `{"a": [true, "x"]}`)
+	removed, err := MustParse("/a/1").Remove(root)
+	if err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if removed.Value != "x" || removed.Start.Offset != 13 {
+		t.Errorf("Remove() got = %v at %d, want \"x\" at 13", removed.Value, removed.Start.Offset)
+	}
+}
+
+// parse tokenizes and parses input and fails the test on error.
+func parse(t *testing.T, input string) *parser.AstNode {
+	t.Helper()
+	tokens, err := token.Tokenizer([]byte(input))
+	if err != nil {
+		t.Fatalf("Tokenizer() error = %v", err)
+	}
+	root, err := parser.Parse(tokens)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	return root
+}
+
+// number creates a Number node holding n.
+func number(n float64) *parser.AstNode {
+	return &parser.AstNode{Type: token.Number, Value: n}
+}
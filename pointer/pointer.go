@@ -0,0 +1,212 @@
+// Package pointer implements JSON Pointers (RFC 6901) on parsed documents.
+// A pointer such as "/users/0/name" selects a value by object keys and array indexes;
+// it can be applied to parser.AstNode trees and to values decoded into interface{}.
+package pointer
+
+import (
+	"errors"
+	"fmt"
+	"github.com/onerciller/gojsonp/token"
+	"strconv"
+	"strings"
+)
+
+// Kinds of pointer errors; use errors.Is to tell them apart.
+var (
+	ErrSyntax       = errors.New("invalid pointer")
+	ErrNotFound     = errors.New("value not found")
+	ErrInvalidIndex = errors.New("invalid array index")
+	ErrNotContainer = errors.New("value is not an object or array")
+	ErrRoot         = errors.New("cannot remove the document root")
+)
+
+// Error describes a pointer that is malformed or cannot be applied to a document.
+type Error struct {
+
+	// Kind is one of the Err* values above
+	Kind error
+
+	// Pointer is the pointer that failed, as written
+	Pointer string
+
+	// Segment is the index of the reference token that failed, or -1 if the error concerns the whole pointer
+	Segment int
+
+	// Token is the reference token that failed, unescaped
+	Token string
+
+	// Pos is the position of the value the token was applied to; it is only set for AST nodes
+	Pos token.Position
+}
+
+// Error method for Error to get the "line:column: message" representation of the error.
+// The position is left out if it is unknown.
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("pointer %q: %s", e.Pointer, e.Kind)
+	if e.Segment >= 0 {
+		msg = fmt.Sprintf("pointer %q: segment %d %q: %s", e.Pointer, e.Segment, e.Token, e.Kind)
+	}
+	if e.Pos.Line > 0 {
+		msg = e.Pos.String() + ": " + msg
+	}
+	return msg
+}
+
+// Unwrap returns the kind of the error, so errors.Is(err, ErrNotFound) works.
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+// Pointer is a parsed JSON Pointer, holding its unescaped reference tokens.
+// The empty Pointer refers to the whole document.
+type Pointer struct {
+	tokens []string
+}
+
+// Parse parses a JSON Pointer string.
+// The pointer is either empty or starts with '/', and '~' must be followed by '0' or '1'.
+// Example: Parse("/a~1b/0") returns the reference tokens "a/b" and "0".
+func Parse(s string) (Pointer, error) {
+	if s == "" {
+		return Pointer{}, nil
+	}
+	if s[0] != '/' {
+		return Pointer{}, &Error{Kind: ErrSyntax, Pointer: s, Segment: -1}
+	}
+	parts := strings.Split(s[1:], "/")
+	for i, part := range parts {
+		unescaped, ok := unescape(part)
+		if !ok {
+			return Pointer{}, &Error{Kind: ErrSyntax, Pointer: s, Segment: i, Token: part}
+		}
+		parts[i] = unescaped
+	}
+	return Pointer{tokens: parts}, nil
+}
+
+// MustParse is like Parse but panics if the pointer is malformed.
+// It simplifies the initialization of pointers written in the source code.
+func MustParse(s string) Pointer {
+	p, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// New creates a Pointer from unescaped reference tokens.
+// Example: New("a/b", "0").String() returns "/a~1b/0".
+func New(tokens ...string) Pointer {
+	return Pointer{tokens: append([]string(nil), tokens...)}
+}
+
+// Tokens returns the unescaped reference tokens.
+func (p Pointer) Tokens() []string {
+	return append([]string(nil), p.tokens...)
+}
+
+// IsRoot reports whether the pointer refers to the whole document.
+func (p Pointer) IsRoot() bool {
+	return len(p.tokens) == 0
+}
+
+// Parent returns the pointer to the container of the referenced value; the root is its own parent.
+func (p Pointer) Parent() Pointer {
+	if p.IsRoot() {
+		return p
+	}
+	return Pointer{tokens: p.tokens[:len(p.tokens)-1]}
+}
+
+// Append returns the pointer extended by the given unescaped reference tokens.
+func (p Pointer) Append(tokens ...string) Pointer {
+	result := make([]string, 0, len(p.tokens)+len(tokens))
+	return Pointer{tokens: append(append(result, p.tokens...), tokens...)}
+}
+
+// HasPrefix reports whether prefix refers to p or to one of its containers.
+// Example: "/a" is a prefix of "/a/b" but not of "/ab".
+func (p Pointer) HasPrefix(prefix Pointer) bool {
+	if len(prefix.tokens) > len(p.tokens) {
+		return false
+	}
+	for i, tk := range prefix.tokens {
+		if p.tokens[i] != tk {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns the pointer in its escaped form, e.g. "/a~1b/0".
+func (p Pointer) String() string {
+	var sb strings.Builder
+	for _, tk := range p.tokens {
+		sb.WriteByte('/')
+		sb.WriteString(escape(tk))
+	}
+	return sb.String()
+}
+
+// escape encodes '~' as "~0" and '/' as "~1".
+func escape(s string) string {
+	if !strings.ContainsAny(s, "~/") {
+		return s
+	}
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(s)
+}
+
+// unescape decodes "~1" to '/' and "~0" to '~', in that order as RFC 6901 requires,
+// and reports false for a '~' followed by anything else.
+func unescape(s string) (string, bool) {
+	if !strings.Contains(s, "~") {
+		return s, true
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '~' {
+			sb.WriteByte(s[i])
+			continue
+		}
+		if i+1 == len(s) || (s[i+1] != '0' && s[i+1] != '1') {
+			return "", false
+		}
+		if s[i+1] == '0' {
+			sb.WriteByte('~')
+		} else {
+			sb.WriteByte('/')
+		}
+		i++
+	}
+	return sb.String(), true
+}
+
+// arrayIndex converts a reference token to an index into an array of length n.
+// The token "-" refers to the position past the last element, which only exists if allowEnd is set,
+// as does index n. Indexes have no sign and no leading zeros.
+func arrayIndex(tk string, n int, allowEnd bool) (int, error) {
+	if tk == "-" {
+		if allowEnd {
+			return n, nil
+		}
+		return 0, ErrNotFound
+	}
+	if tk == "" || (tk[0] == '0' && len(tk) > 1) {
+		return 0, ErrInvalidIndex
+	}
+	for i := 0; i < len(tk); i++ {
+		if tk[i] < '0' || tk[i] > '9' {
+			return 0, ErrInvalidIndex
+		}
+	}
+	index, err := strconv.Atoi(tk)
+	if err != nil || index > n || (index == n && !allowEnd) {
+		return 0, ErrNotFound
+	}
+	return index, nil
+}
+
+// fail creates an Error for the reference token at segment.
+func (p Pointer) fail(kind error, segment int, pos token.Position) error {
+	return &Error{Kind: kind, Pointer: p.String(), Segment: segment, Token: p.tokens[segment], Pos: pos}
+}
@@ -0,0 +1,98 @@
+package pointer
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// TestParse tests parsing pointers, including the ~0 and ~1 escapes.
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []string
+		wantErr bool
+	}{
+		{name: "Root", input: ``, want: nil},
+		{name: "Empty key", input: `/`, want: []string{""}},
+		{name: "Path", input: `/a/0/b`, want: []string{"a", "0", "b"}},
+		{name: "Escaped slash", input: `/a~1b`, want: []string{"a/b"}},
+		{name: "Escaped tilde", input: `/m~0n`, want: []string{"m~n"}},
+		{name: "Escapes decode once", input: `/~01`, want: []string{"~1"}},
+		{name: "Spaces and quotes", input: `/ /"`, want: []string{" ", `"`}},
+		{name: "Missing slash", input: `a/b`, wantErr: true},
+		{name: "Bad escape", input: `/a~2`, wantErr: true},
+		{name: "Trailing tilde", input: `/a~`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if !errors.Is(err, ErrSyntax) {
+					t.Errorf("Parse() error = %v, want ErrSyntax", err)
+				}
+				return
+			}
+			if !reflect.DeepEqual(got.tokens, tt.want) {
+				t.Errorf("Parse() got = %q, want %q", got.tokens, tt.want)
+			}
+			if got.String() != tt.input {
+				t.Errorf("String() = %q, want %q", got.String(), tt.input)
+			}
+		})
+	}
+}
+
+// TestPointerHelpers tests building pointers from tokens and deriving related pointers.
+func TestPointerHelpers(t *testing.T) {
+	p := New("a/b", "~", "0")
+	if p.String() != "/a~1b/~0/0" {
+		t.Errorf("String() = %q", p.String())
+	}
+	if got := p.Parent().String(); got != "/a~1b/~0" {
+		t.Errorf("Parent() = %q", got)
+	}
+	if got := p.Parent().Append("x").String(); got != "/a~1b/~0/x" {
+		t.Errorf("Append() = %q", got)
+	}
+	if !p.HasPrefix(MustParse("/a~1b")) || p.HasPrefix(MustParse("/a")) {
+		t.Errorf("HasPrefix() compares whole reference tokens")
+	}
+	if !New().IsRoot() || New().Parent().String() != "" {
+		t.Errorf("the root pointer is its own parent")
+	}
+}
+
+// TestArrayIndex tests the array index rules of RFC 6901.
+func TestArrayIndex(t *testing.T) {
+	tests := []struct {
+		tk       string
+		allowEnd bool
+		want     int
+		wantErr  error
+	}{
+		{tk: "0", want: 0},
+		{tk: "2", want: 2},
+		{tk: "3", wantErr: ErrNotFound},
+		{tk: "3", allowEnd: true, want: 3},
+		{tk: "-", wantErr: ErrNotFound},
+		{tk: "-", allowEnd: true, want: 3},
+		{tk: "01", wantErr: ErrInvalidIndex},
+		{tk: "-1", wantErr: ErrInvalidIndex},
+		{tk: "1a", wantErr: ErrInvalidIndex},
+		{tk: "", wantErr: ErrInvalidIndex},
+		{tk: "99999999999999999999", wantErr: ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		got, err := arrayIndex(tt.tk, 3, tt.allowEnd)
+		if err != tt.wantErr || (err == nil && got != tt.want) {
+			t.Errorf("arrayIndex(%q, 3, %v) = %d, %v, want %d, %v", tt.tk, tt.allowEnd, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
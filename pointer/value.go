@@ -0,0 +1,173 @@
+package pointer
+
+import (
+	"github.com/onerciller/gojsonp/parser"
+	"github.com/onerciller/gojsonp/token"
+)
+
+// GetValue returns the value the pointer refers to in a document decoded into plain Go values:
+// map[string]interface{} or *parser.OrderedMap for objects and []interface{} for arrays.
+// Example: For the value of `{"a": [1, 2]}`, MustParse("/a/1").GetValue(doc) returns float64(2).
+func (p Pointer) GetValue(doc interface{}) (interface{}, error) {
+	value := doc
+	for segment := range p.tokens {
+		child, err := p.childValue(value, segment)
+		if err != nil {
+			return nil, err
+		}
+		value = child
+	}
+	return value, nil
+}
+
+// SetValue replaces the value the pointer refers to and returns the new document.
+// A missing object member is created, but an array element must exist.
+// Maps are modified in place.
+func (p Pointer) SetValue(doc, value interface{}) (interface{}, error) {
+	if p.IsRoot() {
+		return value, nil
+	}
+	return p.update(doc, 0, func(container interface{}, segment int) (interface{}, error) {
+		tk := p.tokens[segment]
+		switch c := container.(type) {
+		case map[string]interface{}:
+			c[tk] = value
+		case *parser.OrderedMap:
+			c.Set(tk, value)
+		case []interface{}:
+			index, err := arrayIndex(tk, len(c), false)
+			if err != nil {
+				return nil, p.fail(err, segment, token.Position{})
+			}
+			c[index] = value
+		default:
+			return nil, p.fail(ErrNotContainer, segment, token.Position{})
+		}
+		return container, nil
+	})
+}
+
+// AddValue inserts value at the location the pointer refers to and returns the new document,
+// following the rules of Add. Maps are modified in place, arrays are copied.
+func (p Pointer) AddValue(doc, value interface{}) (interface{}, error) {
+	if p.IsRoot() {
+		return value, nil
+	}
+	return p.update(doc, 0, func(container interface{}, segment int) (interface{}, error) {
+		tk := p.tokens[segment]
+		switch c := container.(type) {
+		case map[string]interface{}:
+			c[tk] = value
+		case *parser.OrderedMap:
+			c.Set(tk, value)
+		case []interface{}:
+			index, err := arrayIndex(tk, len(c), true)
+			if err != nil {
+				return nil, p.fail(err, segment, token.Position{})
+			}
+			elements := make([]interface{}, 0, len(c)+1)
+			return append(append(append(elements, c[:index]...), value), c[index:]...), nil
+		default:
+			return nil, p.fail(ErrNotContainer, segment, token.Position{})
+		}
+		return container, nil
+	})
+}
+
+// RemoveValue deletes the value the pointer refers to and returns the new document and the removed value.
+// Maps are modified in place, arrays are copied; the root itself cannot be removed.
+func (p Pointer) RemoveValue(doc interface{}) (interface{}, interface{}, error) {
+	if p.IsRoot() {
+		return nil, nil, &Error{Kind: ErrRoot, Pointer: "", Segment: -1}
+	}
+	var removed interface{}
+	result, err := p.update(doc, 0, func(container interface{}, segment int) (interface{}, error) {
+		tk := p.tokens[segment]
+		switch c := container.(type) {
+		case map[string]interface{}:
+			value, ok := c[tk]
+			if !ok {
+				return nil, p.fail(ErrNotFound, segment, token.Position{})
+			}
+			removed = value
+			delete(c, tk)
+		case *parser.OrderedMap:
+			value, ok := c.Get(tk)
+			if !ok {
+				return nil, p.fail(ErrNotFound, segment, token.Position{})
+			}
+			removed = value
+			c.Delete(tk)
+		case []interface{}:
+			index, err := arrayIndex(tk, len(c), false)
+			if err != nil {
+				return nil, p.fail(err, segment, token.Position{})
+			}
+			removed = c[index]
+			elements := make([]interface{}, 0, len(c)-1)
+			return append(append(elements, c[:index]...), c[index+1:]...), nil
+		default:
+			return nil, p.fail(ErrNotContainer, segment, token.Position{})
+		}
+		return container, nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return result, removed, nil
+}
+
+// update walks from value down to the container of the last reference token and applies change to it.
+// Containers that change returns a new copy of, such as arrays, are stored back into their parents.
+func (p Pointer) update(value interface{}, segment int, change func(container interface{}, segment int) (interface{}, error)) (interface{}, error) {
+	if segment == len(p.tokens)-1 {
+		return change(value, segment)
+	}
+	child, err := p.childValue(value, segment)
+	if err != nil {
+		return nil, err
+	}
+	updated, err := p.update(child, segment+1, change)
+	if err != nil {
+		return nil, err
+	}
+
+	tk := p.tokens[segment]
+	switch c := value.(type) {
+	case map[string]interface{}:
+		c[tk] = updated
+	case *parser.OrderedMap:
+		c.Set(tk, updated)
+	case []interface{}:
+		index, _ := arrayIndex(tk, len(c), false)
+		c[index] = updated
+	}
+	return value, nil
+}
+
+// childValue returns the member or array element of value selected by the reference token at segment.
+func (p Pointer) childValue(value interface{}, segment int) (interface{}, error) {
+	tk := p.tokens[segment]
+	switch c := value.(type) {
+	case map[string]interface{}:
+		child, ok := c[tk]
+		if !ok {
+			return nil, p.fail(ErrNotFound, segment, token.Position{})
+		}
+		return child, nil
+	case *parser.OrderedMap:
+		child, ok := c.Get(tk)
+		if !ok {
+			return nil, p.fail(ErrNotFound, segment, token.Position{})
+		}
+		return child, nil
+	case []interface{}:
+		index, err := arrayIndex(tk, len(c), false)
+		if err != nil {
+			return nil, p.fail(err, segment, token.Position{})
+		}
+		return c[index], nil
+	default:
+		return nil, p.fail(ErrNotContainer, segment, token.Position{})
+	}
+}
@@ -0,0 +1,109 @@
+package pointer
+
+import (
+	"errors"
+	"github.com/onerciller/gojsonp/parser"
+	"reflect"
+	"testing"
+)
+
+// TestGetValue tests lookups in maps, ordered maps and slices.
+func TestGetValue(t *testing.T) {
+	ordered := parser.NewOrderedMap()
+	ordered.Set("k", []interface{}{"v"})
+	doc := map[string]interface{}{
+		"a": []interface{}{float64(1), map[string]interface{}{"b/c": true}},
+		"o": ordered,
+	}
+
+	tests := []struct {
+		pointer string
+		want    interface{}
+		wantErr error
+	}{
+		{pointer: `/a/1/b~1c`, want: true},
+		{pointer: `/o/k/0`, want: "v"},
+		{pointer: `/a/0`, want: float64(1)},
+		{pointer: `/a/2`, wantErr: ErrNotFound},
+		{pointer: `/a/x`, wantErr: ErrInvalidIndex},
+		{pointer: `/a/0/b`, wantErr: ErrNotContainer},
+		{pointer: `/missing`, wantErr: ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pointer, func(t *testing.T) {
+			got, err := MustParse(tt.pointer).GetValue(doc)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetValue() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetValue() got = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestValueMutations tests that SetValue, AddValue and RemoveValue store changed arrays in their parents.
+func TestValueMutations(t *testing.T) {
+	doc := interface{}(map[string]interface{}{"list": []interface{}{"a", "c"}})
+
+	doc, err := MustParse("/list/1").AddValue(doc, "b")
+	if err != nil {
+		t.Fatalf("AddValue() error = %v", err)
+	}
+	doc, err = MustParse("/list/-").AddValue(doc, "d")
+	if err != nil {
+		t.Fatalf("AddValue() error = %v", err)
+	}
+	doc, err = MustParse("/list/0").SetValue(doc, "A")
+	if err != nil {
+		t.Fatalf("SetValue() error = %v", err)
+	}
+	doc, removed, err := MustParse("/list/2").RemoveValue(doc)
+	if err != nil || removed != "c" {
+		t.Fatalf("RemoveValue() got = %v, %v", removed, err)
+	}
+	doc, err = MustParse("/new").SetValue(doc, nil)
+	if err != nil {
+		t.Fatalf("SetValue() error = %v", err)
+	}
+
+	want := map[string]interface{}{"list": []interface{}{"A", "b", "d"}, "new": nil}
+	if !reflect.DeepEqual(doc, want) {
+		t.Errorf("got = %#v, want %#v", doc, want)
+	}
+
+	if _, _, err := MustParse("/missing").RemoveValue(doc); !errors.Is(err, ErrNotFound) {
+		t.Errorf("RemoveValue() error = %v, want ErrNotFound", err)
+	}
+	if _, err := MustParse("/list/5").SetValue(doc, 1); !errors.Is(err, ErrNotFound) {
+		t.Errorf("SetValue() error = %v, want ErrNotFound", err)
+	}
+	if _, _, err := MustParse("").RemoveValue(doc); !errors.Is(err, ErrRoot) {
+		t.Errorf("RemoveValue() error = %v, want ErrRoot", err)
+	}
+}
+
+// TestValueMutationsOrderedMap tests that ordered maps keep their key order when changed.
+func TestValueMutationsOrderedMap(t *testing.T) {
+	m := parser.NewOrderedMap()
+	m.Set("z", float64(1))
+	m.Set("a", []interface{}{})
+
+	if _, err := MustParse("/a/-").AddValue(m, "x"); err != nil {
+		t.Fatalf("AddValue() error = %v", err)
+	}
+	if _, err := MustParse("/m").AddValue(m, true); err != nil {
+		t.Fatalf("AddValue() error = %v", err)
+	}
+	if _, _, err := MustParse("/z").RemoveValue(m); err != nil {
+		t.Fatalf("RemoveValue() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(m.Keys(), []string{"a", "m"}) {
+		t.Errorf("Keys() = %v, want [a m]", m.Keys())
+	}
+	if list, _ := m.Get("a"); !reflect.DeepEqual(list, []interface{}{"x"}) {
+		t.Errorf("Get(a) = %#v, want [x]", list)
+	}
+}
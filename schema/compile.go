@@ -0,0 +1,541 @@
+package schema
+
+import (
+	"fmt"
+	"github.com/onerciller/gojsonp/parser"
+	"github.com/onerciller/gojsonp/pointer"
+	"github.com/onerciller/gojsonp/token"
+	"math"
+	"math/big"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// typeNames are the values allowed in the type keyword.
+var typeNames = map[string]bool{
+	"null": true, "boolean": true, "object": true, "array": true, "number": true, "string": true, "integer": true,
+}
+
+// compiler turns a schema document into linked Schemas.
+type compiler struct {
+	root *parser.AstNode
+
+	// id is the $id of the root schema; references starting with it point into the document
+	id string
+
+	// schemas holds the compiled subschemas by location, so each is compiled once and $ref cycles terminate
+	schemas map[string]*Schema
+
+	// anchors maps the names declared with $anchor to the location of their schema
+	anchors map[string]pointer.Pointer
+}
+
+// compileRoot compiles the whole document.
+func (c *compiler) compileRoot() (*Schema, error) {
+	if object, ok := c.root.Value.(*parser.Object); ok {
+		if id, ok := object.Get("$id"); ok {
+			if s, ok := id.Value.(string); ok {
+				c.id = strings.TrimSuffix(s, "#")
+			}
+		}
+	}
+	c.anchors = make(map[string]pointer.Pointer)
+	c.collectAnchors(pointer.Pointer{}, c.root)
+	s, err := c.compile(pointer.Pointer{}, c.root)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.checkCycles(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// applicator is a keyword that applies a subschema to the instance being validated, rather than to a part of it.
+type applicator struct {
+
+	// location is the pointer of the keyword value in the schema document, e.g. "/allOf/1"
+	location pointer.Pointer
+
+	// keyword is the name of the keyword, e.g. "allOf", and schema the subschema it applies
+	keyword string
+	schema  *Schema
+}
+
+// applicators lists the $ref, allOf, anyOf, oneOf and not subschemas of s.
+func (s *Schema) applicators() []applicator {
+	var list []applicator
+	if s.ref != nil {
+		list = append(list, applicator{location: s.location.Append("$ref"), keyword: "$ref", schema: s.ref})
+	}
+	for _, keyword := range []struct {
+		name    string
+		schemas []*Schema
+	}{{"allOf", s.allOf}, {"anyOf", s.anyOf}, {"oneOf", s.oneOf}} {
+		for i, sub := range keyword.schemas {
+			list = append(list, applicator{location: s.location.Append(keyword.name, strconv.Itoa(i)), keyword: keyword.name, schema: sub})
+		}
+	}
+	if s.not != nil {
+		list = append(list, applicator{location: s.location.Append("not"), keyword: "not", schema: s.not})
+	}
+	return list
+}
+
+// checkCycles rejects a $ref cycle that leads back to a schema through applicators only, such as {"$ref": "#"}:
+// validating it would apply the same schema to the same instance forever. A cycle through a keyword such as
+// items or properties is fine, as it moves into the instance and ends with it.
+// The schemas are checked in order of their location, so the same cycle is reported every time.
+func (c *compiler) checkCycles() error {
+	locations := make([]string, 0, len(c.schemas))
+	for location := range c.schemas {
+		locations = append(locations, location)
+	}
+	sort.Strings(locations)
+
+	// state is 1 for the schemas on the current path and 2 for the schemas checked before
+	state := make(map[*Schema]int, len(c.schemas))
+	var visit func(s *Schema) error
+	visit = func(s *Schema) error {
+		state[s] = 1
+		for _, a := range s.applicators() {
+			switch state[a.schema] {
+			case 1:
+				return &Error{Location: a.location, Pos: s.positions[a.keyword], Err: fmt.Errorf(
+					"%w: %s leads back to %q without validating a part of the instance", ErrInvalidSchema, a.keyword, a.schema.location.String())}
+			case 0:
+				if err := visit(a.schema); err != nil {
+					return err
+				}
+			}
+		}
+		state[s] = 2
+		return nil
+	}
+	for _, location := range locations {
+		if s := c.schemas[location]; state[s] == 0 {
+			if err := visit(s); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// collectAnchors records the $anchor names declared in node and below, skipping the values
+// of keywords that hold instance data rather than schemas.
+func (c *compiler) collectAnchors(location pointer.Pointer, node *parser.AstNode) {
+	switch value := node.Value.(type) {
+	case *parser.Object:
+		if anchor, ok := value.Get("$anchor"); ok {
+			if name, ok := anchor.Value.(string); ok {
+				c.anchors[name] = location
+			}
+		}
+		for _, member := range value.Members() {
+			switch member.Key {
+			case "const", "enum", "default", "examples":
+				continue
+			}
+			c.collectAnchors(location.Append(member.Key), member.Value)
+		}
+	case []*parser.AstNode:
+		for i, element := range value {
+			c.collectAnchors(location.Append(strconv.Itoa(i)), element)
+		}
+	}
+}
+
+// compile compiles the schema at location, or returns it if it was compiled before.
+func (c *compiler) compile(location pointer.Pointer, node *parser.AstNode) (*Schema, error) {
+	if s, ok := c.schemas[location.String()]; ok {
+		return s, nil
+	}
+	s := &Schema{location: location, start: node.Start, maxLength: -1, minLength: -1,
+		maxItems: -1, minItems: -1, maxProperties: -1, minProperties: -1}
+	c.schemas[location.String()] = s
+
+	switch value := node.Value.(type) {
+	case bool:
+		s.boolean = &value
+		return s, nil
+	case *parser.Object:
+		s.positions = make(map[string]token.Position, value.Len())
+		for _, member := range value.Members() {
+			s.positions[member.Key] = member.Value.Start
+			if err := c.keyword(s, member.Key, member.Value); err != nil {
+				return nil, err
+			}
+		}
+		return s, nil
+	default:
+		return nil, c.fail(location, node, "a schema must be an object or a boolean")
+	}
+}
+
+// keyword compiles a single keyword of s; unknown keywords are ignored.
+func (c *compiler) keyword(s *Schema, name string, value *parser.AstNode) error {
+	location := s.location.Append(name)
+	var err error
+	switch name {
+	case "$ref":
+		s.ref, err = c.resolve(location, value)
+	case "$defs":
+		_, err = c.schemaMap(location, value)
+	case "type":
+		s.types, err = c.typeList(location, value)
+	case "enum":
+		if _, ok := value.Value.([]*parser.AstNode); !ok {
+			return c.fail(location, value, "enum must be an array")
+		}
+		s.enum = value
+	case "const":
+		s.constant = value
+	case "multipleOf":
+		s.multipleOf, err = c.number(location, value)
+		if err == nil {
+			if n, ok := decimal(value); !ok {
+				err = c.fail(location, value, "multipleOf must have an exponent between %d and %d", -maxExponent, maxExponent)
+			} else if n.Sign() <= 0 {
+				err = c.fail(location, value, "multipleOf must be greater than 0")
+			}
+		}
+	case "maximum":
+		s.maximum, err = c.number(location, value)
+	case "exclusiveMaximum":
+		s.exclusiveMaximum, err = c.number(location, value)
+	case "minimum":
+		s.minimum, err = c.number(location, value)
+	case "exclusiveMinimum":
+		s.exclusiveMinimum, err = c.number(location, value)
+	case "maxLength":
+		s.maxLength, err = c.count(location, value)
+	case "minLength":
+		s.minLength, err = c.count(location, value)
+	case "maxItems":
+		s.maxItems, err = c.count(location, value)
+	case "minItems":
+		s.minItems, err = c.count(location, value)
+	case "maxProperties":
+		s.maxProperties, err = c.count(location, value)
+	case "minProperties":
+		s.minProperties, err = c.count(location, value)
+	case "pattern":
+		s.pattern, err = c.regexp(location, value)
+	case "uniqueItems":
+		unique, ok := value.Value.(bool)
+		if !ok {
+			return c.fail(location, value, "uniqueItems must be a boolean")
+		}
+		s.uniqueItems = unique
+	case "prefixItems":
+		s.prefixItems, err = c.schemaList(location, value)
+	case "items":
+		s.items, err = c.compile(location, value)
+	case "required":
+		s.required, err = c.stringList(location, value)
+	case "properties":
+		s.properties, err = c.schemaMap(location, value)
+	case "patternProperties":
+		s.patternProperties, err = c.patternMap(location, value)
+	case "additionalProperties":
+		s.additionalProperties, err = c.compile(location, value)
+	case "allOf":
+		s.allOf, err = c.schemaList(location, value)
+	case "anyOf":
+		s.anyOf, err = c.schemaList(location, value)
+	case "oneOf":
+		s.oneOf, err = c.schemaList(location, value)
+	case "not":
+		s.not, err = c.compile(location, value)
+	}
+	return err
+}
+
+// resolve compiles the target of a $ref: "#" is the root, "#/..." a JSON Pointer into the document
+// and "#name" the schema declaring $anchor "name". The $id of the root may precede the '#'.
+func (c *compiler) resolve(location pointer.Pointer, value *parser.AstNode) (*Schema, error) {
+	ref, ok := value.Value.(string)
+	if !ok {
+		return nil, c.fail(location, value, "$ref must be a string")
+	}
+	fragment := ref
+	if c.id != "" {
+		fragment = strings.TrimPrefix(fragment, c.id)
+	}
+	if !strings.HasPrefix(fragment, "#") {
+		return nil, c.fail(location, value, "unsupported $ref %q, only references within the document are resolved", ref)
+	}
+	fragment, err := url.PathUnescape(fragment[1:])
+	if err != nil {
+		return nil, c.fail(location, value, "invalid $ref %q", ref)
+	}
+
+	var target pointer.Pointer
+	if fragment == "" || fragment[0] == '/' {
+		if target, err = pointer.Parse(fragment); err != nil {
+			return nil, c.fail(location, value, "invalid $ref %q: %v", ref, err)
+		}
+	} else if target, ok = c.anchors[fragment]; !ok {
+		return nil, c.fail(location, value, "$ref %q: no schema declares this $anchor", ref)
+	}
+	node, err := target.Get(c.root)
+	if err != nil {
+		return nil, c.fail(location, value, "$ref %q: %v", ref, err)
+	}
+	return c.compile(target, node)
+}
+
+// typeList reads the type keyword, a type name or an array of unique type names.
+func (c *compiler) typeList(location pointer.Pointer, value *parser.AstNode) ([]string, error) {
+	var types []string
+	if name, ok := value.Value.(string); ok {
+		types = []string{name}
+	} else if _, ok := value.Value.([]*parser.AstNode); ok {
+		var err error
+		if types, err = c.stringList(location, value); err != nil {
+			return nil, err
+		}
+	} else {
+		return nil, c.fail(location, value, "type must be a string or an array")
+	}
+	for _, name := range types {
+		if !typeNames[name] {
+			return nil, c.fail(location, value, "unknown type %q", name)
+		}
+	}
+	return types, nil
+}
+
+// stringList reads an array of unique strings.
+func (c *compiler) stringList(location pointer.Pointer, value *parser.AstNode) ([]string, error) {
+	elements, ok := value.Value.([]*parser.AstNode)
+	if !ok {
+		return nil, c.fail(location, value, "%s must be an array of strings", keywordOf(location))
+	}
+	list := make([]string, len(elements))
+	seen := make(map[string]bool, len(elements))
+	for i, element := range elements {
+		s, ok := element.Value.(string)
+		if !ok || element.Type != token.String {
+			return nil, c.fail(location.Append(strconv.Itoa(i)), element, "must be a string")
+		}
+		if seen[s] {
+			return nil, c.fail(location.Append(strconv.Itoa(i)), element, "duplicate string %q", s)
+		}
+		seen[s] = true
+		list[i] = s
+	}
+	return list, nil
+}
+
+// schemaList compiles a non-empty array of schemas.
+func (c *compiler) schemaList(location pointer.Pointer, value *parser.AstNode) ([]*Schema, error) {
+	elements, ok := value.Value.([]*parser.AstNode)
+	if !ok || len(elements) == 0 {
+		return nil, c.fail(location, value, "%s must be a non-empty array of schemas", keywordOf(location))
+	}
+	schemas := make([]*Schema, len(elements))
+	for i, element := range elements {
+		s, err := c.compile(location.Append(strconv.Itoa(i)), element)
+		if err != nil {
+			return nil, err
+		}
+		schemas[i] = s
+	}
+	return schemas, nil
+}
+
+// schemaMap compiles an object whose member values are schemas.
+func (c *compiler) schemaMap(location pointer.Pointer, value *parser.AstNode) (map[string]*Schema, error) {
+	object, ok := value.Value.(*parser.Object)
+	if !ok {
+		return nil, c.fail(location, value, "%s must be an object", keywordOf(location))
+	}
+	schemas := make(map[string]*Schema, object.Len())
+	for _, member := range object.Members() {
+		s, err := c.compile(location.Append(member.Key), member.Value)
+		if err != nil {
+			return nil, err
+		}
+		schemas[member.Key] = s
+	}
+	return schemas, nil
+}
+
+// patternMap compiles patternProperties, an object from regular expressions to schemas.
+func (c *compiler) patternMap(location pointer.Pointer, value *parser.AstNode) ([]patternSchema, error) {
+	object, ok := value.Value.(*parser.Object)
+	if !ok {
+		return nil, c.fail(location, value, "patternProperties must be an object")
+	}
+	patterns := make([]patternSchema, 0, object.Len())
+	for _, member := range object.Members() {
+		re, err := regexp.Compile(member.Key)
+		if err != nil {
+			return nil, c.fail(location.Append(member.Key), member.Value, "invalid pattern %q: %v", member.Key, err)
+		}
+		s, err := c.compile(location.Append(member.Key), member.Value)
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, patternSchema{pattern: re, schema: s})
+	}
+	return patterns, nil
+}
+
+// regexp compiles the pattern keyword. Patterns use the RE2 syntax of package regexp,
+// which covers the ECMA-262 subset recommended by JSON Schema apart from lookarounds and backreferences.
+func (c *compiler) regexp(location pointer.Pointer, value *parser.AstNode) (*regexp.Regexp, error) {
+	pattern, ok := value.Value.(string)
+	if !ok {
+		return nil, c.fail(location, value, "pattern must be a string")
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, c.fail(location, value, "invalid pattern %q: %v", pattern, err)
+	}
+	return re, nil
+}
+
+// number checks that a keyword value is a number.
+func (c *compiler) number(location pointer.Pointer, value *parser.AstNode) (*parser.AstNode, error) {
+	if !isNumber(value) {
+		return nil, c.fail(location, value, "%s must be a number", keywordOf(location))
+	}
+	return value, nil
+}
+
+// count reads a non-negative integer keyword value; 2.0 counts as an integer.
+func (c *compiler) count(location pointer.Pointer, value *parser.AstNode) (int, error) {
+	n, ok := decimal(value)
+	if !ok || !n.IsInt() || n.Sign() < 0 || !n.Num().IsInt64() || n.Num().Int64() > int64(maxCount) {
+		return 0, c.fail(location, value, "%s must be a non-negative integer", keywordOf(location))
+	}
+	return int(n.Num().Int64()), nil
+}
+
+// keywordOf returns the name of the keyword at location, for error messages.
+func keywordOf(location pointer.Pointer) string {
+	tokens := location.Tokens()
+	return tokens[len(tokens)-1]
+}
+
+// maxCount is the largest length or count limit, the largest int on 32-bit platforms.
+const maxCount = 1<<31 - 1
+
+// fail creates an Error of kind ErrInvalidSchema for the keyword value at location.
+func (c *compiler) fail(location pointer.Pointer, value *parser.AstNode, format string, args ...interface{}) error {
+	return &Error{Location: location, Pos: value.Start, Err: fmt.Errorf("%w: %s", ErrInvalidSchema, fmt.Sprintf(format, args...))}
+}
+
+// maxExponent bounds the decimal exponent of the numbers decimal converts to exact fractions.
+// The size of the fraction grows with the exponent: 1e500000 takes 1.6 million bits and milliseconds to build.
+const maxExponent = 10000
+
+// decimal converts the value of a Number node to an exact fraction, reporting false for other nodes,
+// infinities, NaN and numbers with an exponent beyond ±maxExponent.
+// A float64 is taken at its shortest decimal representation, so 0.1 is exactly one tenth
+// and multipleOf works as it does for the number as written.
+func decimal(node *parser.AstNode) (*big.Rat, bool) {
+	var literal string
+	switch n := node.Value.(type) {
+	case int64:
+		return new(big.Rat).SetInt64(n), true
+	case *big.Int:
+		return new(big.Rat).SetInt(n), true
+	case float64:
+		literal = strconv.FormatFloat(n, 'g', -1, 64)
+	case *big.Float:
+		// writing out the number takes as long as building the fraction, so the binary exponent is checked first
+		if exp := n.MantExp(nil); exp > 4*maxExponent || exp < -4*maxExponent {
+			return nil, false
+		}
+		literal = n.Text('g', -1)
+	case parser.Number:
+		literal = string(n)
+	default:
+		return nil, false
+	}
+	if exp, ok := exponent(literal); !ok || exp > maxExponent || exp < -maxExponent {
+		return nil, false
+	}
+	return new(big.Rat).SetString(literal)
+}
+
+// exponent returns the exponent written in a number literal, or 0 if it has none,
+// reporting false if it does not fit an int.
+// Example: For "1.5e-7", it returns -7.
+func exponent(literal string) (int, bool) {
+	i := strings.IndexAny(literal, "eE")
+	if i < 0 {
+		return 0, true
+	}
+	exp, err := strconv.Atoi(literal[i+1:])
+	return exp, err == nil
+}
+
+// isNumber checks if node is a finite number.
+func isNumber(node *parser.AstNode) bool {
+	switch n := node.Value.(type) {
+	case int64, *big.Int:
+		return true
+	case float64:
+		return !math.IsInf(n, 0) && !math.IsNaN(n)
+	case *big.Float:
+		return !n.IsInf()
+	case parser.Number:
+		// the literal of a JSON5 Infinity or NaN starts with a letter
+		digits := strings.TrimLeft(string(n), "+-")
+		return digits != "" && isDigit(digits[0])
+	}
+	return false
+}
+
+// isInteger checks if node is a number without a fractional part, like 1.0 or 1e500000.
+// The literal of a Number is checked digit by digit, so a huge exponent costs no more than the text.
+func isInteger(node *parser.AstNode) bool {
+	switch n := node.Value.(type) {
+	case int64, *big.Int:
+		return true
+	case float64:
+		return !math.IsInf(n, 0) && n == math.Trunc(n)
+	case *big.Float:
+		return n.IsInt()
+	case parser.Number:
+		return isNumber(node) && isIntegerLiteral(string(n))
+	}
+	return false
+}
+
+// isIntegerLiteral checks if a JSON number literal has no fractional part: its digits, without trailing zeros,
+// may not reach further right than the exponent moves the decimal point.
+// Example: "1.50e1" and "0.0e-9" are integers, "15e-1" and "1.5" are not.
+func isIntegerLiteral(literal string) bool {
+	mantissa := literal
+	if i := strings.IndexAny(literal, "eE"); i >= 0 {
+		mantissa = literal[:i]
+	}
+	intPart, fraction, _ := strings.Cut(strings.TrimLeft(mantissa, "+-"), ".")
+	digits := strings.TrimRight(intPart+fraction, "0")
+	if strings.Trim(digits, "0") == "" {
+		return true
+	}
+
+	// places is the number of digits right of the decimal point, not counting trailing zeros
+	places := len(digits) - len(intPart)
+	exp, ok := exponent(literal)
+	if !ok {
+		// an exponent too large for an int moves the point past every digit, or far left of them if negative
+		return !strings.Contains(literal[len(mantissa):], "-")
+	}
+	return places <= exp
+}
+
+// isDigit checks if c is an ASCII digit.
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,81 @@
+package schema
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestCompileErrors tests that malformed schemas are reported with the location and position of the keyword.
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		schema   string
+		location string
+		line     int
+		column   int
+	}{
+		{name: "Not a schema", schema: `1`, location: "", line: 1, column: 1},
+		{name: "Unknown type", schema: `{"type": "int"}`, location: "/type", line: 1, column: 10},
+		{name: "Type not a string", schema: `{"type": 1}`, location: "/type", line: 1, column: 10},
+		{name: "Duplicate type", schema: `{"type": ["null", "null"]}`, location: "/type/1", line: 1, column: 19},
+		{name: "Enum not an array", schema: `{"enum": 1}`, location: "/enum", line: 1, column: 10},
+		{name: "Minimum not a number", schema: `{"minimum": "1"}`, location: "/minimum", line: 1, column: 13},
+		{name: "Multiple of zero", schema: `{"multipleOf": 0}`, location: "/multipleOf", line: 1, column: 16},
+		{name: "Negative length", schema: `{"maxLength": -1}`, location: "/maxLength", line: 1, column: 15},
+		{name: "Fractional count", schema: `{"minItems": 1.5}`, location: "/minItems", line: 1, column: 14},
+		{name: "Invalid pattern", schema: `{"pattern": "("}`, location: "/pattern", line: 1, column: 13},
+		{name: "Invalid pattern property", schema: `{"patternProperties": {"[": {}}}`, location: "/patternProperties/[", line: 1, column: 29},
+		{name: "Required not strings", schema: `{"required": [1]}`, location: "/required/0", line: 1, column: 15},
+		{name: "Empty allOf", schema: `{"allOf": []}`, location: "/allOf", line: 1, column: 11},
+		{name: "Nested", schema: "{\"properties\": {\n  \"a\": {\"items\": {\"type\": null}}\n}}", location: "/properties/a/items/type", line: 2, column: 27},
+		{name: "Invalid defs", schema: `{"$defs": {"a": []}}`, location: "/$defs/a", line: 1, column: 17},
+		{name: "Ref not a string", schema: `{"$ref": 1}`, location: "/$ref", line: 1, column: 10},
+		{name: "Remote ref", schema: `{"$ref": "other.json#/a"}`, location: "/$ref", line: 1, column: 10},
+		{name: "Missing ref target", schema: `{"$ref": "#/$defs/a"}`, location: "/$ref", line: 1, column: 10},
+		{name: "Missing anchor", schema: `{"$ref": "#a"}`, location: "/$ref", line: 1, column: 10},
+		{name: "Invalid ref target", schema: `{"$defs": {"a": 1}, "$ref": "#/$defs/a"}`, location: "/$defs/a", line: 1, column: 17},
+		{name: "Ref to itself", schema: `{"$ref": "#"}`, location: "/$ref", line: 1, column: 10},
+		{name: "Ref cycle through allOf", schema: `{"allOf": [{"$ref": "#"}]}`, location: "/allOf/0/$ref", line: 1, column: 21},
+		{name: "Ref cycle through not", schema: `{"$defs": {"a": {"$ref": "#/$defs/b"}, "b": {"not": {"$ref": "#/$defs/a"}}}, "items": {"$ref": "#/$defs/a"}}`, location: "/$defs/b/not/$ref", line: 1, column: 62},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := CompileJSON([]byte(tt.schema))
+			if !errors.Is(err, ErrInvalidSchema) {
+				t.Fatalf("CompileJSON() error = %v, want ErrInvalidSchema", err)
+			}
+			var serr *Error
+			if !errors.As(err, &serr) {
+				t.Fatalf("CompileJSON() error = %T, want *Error", err)
+			}
+			if serr.Location.String() != tt.location || serr.Pos.Line != tt.line || serr.Pos.Column != tt.column {
+				t.Errorf("CompileJSON() error at %q %s, want %q %d:%d", serr.Location.String(), serr.Pos, tt.location, tt.line, tt.column)
+			}
+		})
+	}
+}
+
+// TestCompileRefs tests that references are compiled once, so cyclic and shared definitions work.
+func TestCompileRefs(t *testing.T) {
+	s, err := CompileJSON([]byte(`{
+  "$defs": {
+    "node": {"type": "object", "properties": {"next": {"$ref": "#/$defs/node"}, "value": {"$ref": "#/$defs/value"}}},
+    "value": {"type": "number"}
+  },
+  "properties": {"head": {"$ref": "#/$defs/node"}, "total": {"$ref": "#/$defs/value"}}
+}`))
+	if err != nil {
+		t.Fatalf("CompileJSON() error = %v", err)
+	}
+	head := s.properties["head"].ref
+	if head.properties["next"].ref != head {
+		t.Errorf("cyclic $ref was not resolved to the same schema")
+	}
+	if head.properties["value"].ref != s.properties["total"].ref {
+		t.Errorf("shared $ref was not resolved to the same schema")
+	}
+	if err := s.ValidateJSON([]byte(`{"head": {"next": {"next": {"value": 1}}}, "total": 3}`)); err != nil {
+		t.Errorf("ValidateJSON() error = %v", err)
+	}
+}
@@ -0,0 +1,213 @@
+// Package schema validates parsed documents against JSON Schema (draft 2020-12).
+// A schema is compiled once from a parsed schema document and can then validate any number of instances,
+// reporting every violation with the JSON Pointers of the failing value and keyword and their source positions.
+// Supported keywords: type, enum, const, multipleOf, maximum, exclusiveMaximum, minimum, exclusiveMinimum,
+// maxLength, minLength, pattern, maxItems, minItems, uniqueItems, prefixItems, items, maxProperties,
+// minProperties, required, properties, patternProperties, additionalProperties, allOf, anyOf, oneOf, not,
+// $ref and $defs. References must point into the same document; other keywords, such as format, are ignored.
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"github.com/onerciller/gojsonp/parser"
+	"github.com/onerciller/gojsonp/pointer"
+	"github.com/onerciller/gojsonp/token"
+	"regexp"
+	"strings"
+)
+
+// ErrInvalidSchema is the kind of the errors returned by Compile for malformed schemas.
+var ErrInvalidSchema = errors.New("invalid schema")
+
+// Error describes a malformed schema.
+type Error struct {
+
+	// Location is the pointer of the offending keyword in the schema document
+	Location pointer.Pointer
+
+	// Pos is the position of the offending keyword value in the schema document, if known
+	Pos token.Position
+
+	// Err describes the problem; it wraps ErrInvalidSchema
+	Err error
+}
+
+// Error method for Error to get a human-readable description, e.g.
+// `4:15: schema "/properties/age/minimum": invalid schema: minimum must be a number`.
+// The position is left out if it is unknown.
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("schema %q: %s", e.Location.String(), e.Err)
+	if e.Pos.Line > 0 {
+		msg = e.Pos.String() + ": " + msg
+	}
+	return msg
+}
+
+// Unwrap returns the cause of the error, so errors.Is(err, ErrInvalidSchema) works.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Violation is a single way in which an instance fails a schema.
+type Violation struct {
+
+	// InstanceLocation is the pointer of the failing value in the instance
+	InstanceLocation pointer.Pointer
+
+	// InstancePos is the position of the failing value in the instance, if it was parsed
+	InstancePos token.Position
+
+	// KeywordLocation is the pointer of the failing keyword in the schema document, after following $ref
+	KeywordLocation pointer.Pointer
+
+	// KeywordPos is the position of the failing keyword value in the schema document, if it was parsed
+	KeywordPos token.Position
+
+	// Keyword is the name of the failing keyword, e.g. "minimum"
+	Keyword string
+
+	// Message describes the failure, e.g. "3 is less than 5"
+	Message string
+}
+
+// String method for Violation to get a human-readable description, e.g.
+// `"/age" at 3:10: minimum: 15 is less than 18 (schema "/properties/age/minimum" at 5:19)`.
+func (v Violation) String() string {
+	msg := fmt.Sprintf("%q", v.InstanceLocation.String())
+	if v.InstancePos.Line > 0 {
+		msg += " at " + v.InstancePos.String()
+	}
+	msg += fmt.Sprintf(": %s: %s (schema %q", v.Keyword, v.Message, v.KeywordLocation.String())
+	if v.KeywordPos.Line > 0 {
+		msg += " at " + v.KeywordPos.String()
+	}
+	return msg + ")"
+}
+
+// ValidationError is returned by Validate for an instance that does not conform to the schema.
+type ValidationError struct {
+
+	// Violations lists every failure in the order they were found
+	Violations []Violation
+}
+
+// Error method for ValidationError to get all violations, one per line.
+func (e *ValidationError) Error() string {
+	lines := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		lines[i] = v.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Schema is a compiled schema; it is safe for concurrent use.
+// Every subschema of the document is a Schema as well, linked to the others by the applicator keywords.
+type Schema struct {
+
+	// location is the pointer of the schema in its document and start its position
+	location pointer.Pointer
+	start    token.Position
+
+	// positions holds the position of each keyword value
+	positions map[string]token.Position
+
+	// boolean is set for the schemas true and false, which accept any and no instance
+	boolean *bool
+
+	ref   *Schema
+	types []string
+
+	// enum is the array node of the enum keyword and constant the value of const; both are nil if absent
+	enum, constant *parser.AstNode
+
+	multipleOf, maximum, exclusiveMaximum, minimum, exclusiveMinimum *parser.AstNode
+
+	// the length and count limits are -1 if absent
+	maxLength, minLength         int
+	maxItems, minItems           int
+	maxProperties, minProperties int
+
+	pattern              *regexp.Regexp
+	uniqueItems          bool
+	prefixItems          []*Schema
+	items                *Schema
+	required             []string
+	properties           map[string]*Schema
+	patternProperties    []patternSchema
+	additionalProperties *Schema
+
+	allOf, anyOf, oneOf []*Schema
+	not                 *Schema
+}
+
+// patternSchema is an entry of patternProperties.
+type patternSchema struct {
+	pattern *regexp.Regexp
+	schema  *Schema
+}
+
+// Compile compiles a parsed schema document, resolving every $ref.
+// Malformed schemas are reported as an *Error wrapping ErrInvalidSchema, and so are $ref cycles
+// such as {"$ref": "#"} that would apply a schema to the same value forever.
+// Example:
+//
+//	root, _ := parser.Parse(tokens)
+//	s, err := Compile(root)
+func Compile(root *parser.AstNode) (*Schema, error) {
+	c := &compiler{root: root, schemas: make(map[string]*Schema)}
+	return c.compileRoot()
+}
+
+// CompileJSON parses and compiles a schema document.
+func CompileJSON(data []byte) (*Schema, error) {
+	root, err := parse(data)
+	if err != nil {
+		return nil, err
+	}
+	return Compile(root)
+}
+
+// MustCompileJSON is like CompileJSON but panics if the schema is malformed.
+// It simplifies the initialization of schemas written in the source code.
+func MustCompileJSON(data []byte) *Schema {
+	s, err := CompileJSON(data)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// Validate checks instance against the schema and returns a *ValidationError listing every violation,
+// or nil if the instance is valid.
+// Example:
+//
+//	if err := s.Validate(payload); err != nil {
+//		for _, v := range err.(*schema.ValidationError).Violations { ... }
+//	}
+func (s *Schema) Validate(instance *parser.AstNode) error {
+	violations := s.validate(instance, pointer.Pointer{})
+	if len(violations) == 0 {
+		return nil
+	}
+	return &ValidationError{Violations: violations}
+}
+
+// ValidateJSON parses data and validates it like Validate.
+// Syntax errors are returned as a *token.SyntaxError.
+func (s *Schema) ValidateJSON(data []byte) error {
+	instance, err := parse(data)
+	if err != nil {
+		return err
+	}
+	return s.Validate(instance)
+}
+
+// parse tokenizes and parses a document, keeping number literals so no precision is lost.
+func parse(data []byte) (*parser.AstNode, error) {
+	tokens, err := token.Tokenizer(data)
+	if err != nil {
+		return nil, err
+	}
+	return parser.Parse(tokens, parser.Numbers(parser.NumberString))
+}
@@ -0,0 +1,104 @@
+package schema
+
+import (
+	"errors"
+	"github.com/onerciller/gojsonp/parser"
+	"github.com/onerciller/gojsonp/token"
+	"testing"
+)
+
+// TestViolationPositions tests that violations carry the pointers and positions of the instance value and the keyword.
+func TestViolationPositions(t *testing.T) {
+	s := MustCompileJSON([]byte(`{
+  "properties": {
+    "age": {"minimum": 18}
+  }
+}`))
+	err := s.ValidateJSON([]byte(`{
+  "name": "x",
+  "age": 15
+}`))
+	var verr *ValidationError
+	if !errors.As(err, &verr) || len(verr.Violations) != 1 {
+		t.Fatalf("ValidateJSON() error = %v, want one violation", err)
+	}
+	v := verr.Violations[0]
+	if v.InstanceLocation.String() != "/age" || v.InstancePos.Line != 3 || v.InstancePos.Column != 10 {
+		t.Errorf("instance at %q %s, want \"/age\" 3:10", v.InstanceLocation.String(), v.InstancePos)
+	}
+	if v.KeywordLocation.String() != "/properties/age/minimum" || v.KeywordPos.Line != 3 || v.KeywordPos.Column != 24 {
+		t.Errorf("keyword at %q %s, want \"/properties/age/minimum\" 3:24", v.KeywordLocation.String(), v.KeywordPos)
+	}
+	want := `"/age" at 3:10: minimum: 15 is less than 18 (schema "/properties/age/minimum" at 3:24)`
+	if err.Error() != want {
+		t.Errorf("Error() got = %s, want %s", err.Error(), want)
+	}
+}
+
+// TestValidateNumberModes tests that instances parsed in any NumberMode are validated alike.
+func TestValidateNumberModes(t *testing.T) {
+	s := MustCompileJSON([]byte(`{"type": "integer", "minimum": 1, "multipleOf": 0.5, "enum": [2, 9007199254740993]}`))
+	tests := []struct {
+		input string
+		valid bool
+	}{
+		{input: `2`, valid: true},
+		{input: `2.0`, valid: true},
+		{input: `9007199254740993`, valid: true},
+		{input: `3`, valid: false},
+	}
+
+	for _, mode := range []parser.NumberMode{parser.NumberFloat64, parser.NumberInt64, parser.NumberString, parser.NumberBig} {
+		for _, tt := range tests {
+			tokens, _ := token.Tokenizer([]byte(tt.input))
+			instance, err := parser.Parse(tokens, parser.Numbers(mode))
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if err := s.Validate(instance); (err == nil) != tt.valid {
+				t.Errorf("Validate(%s) in mode %d error = %v, want valid %v", tt.input, mode, err, tt.valid)
+			}
+		}
+	}
+}
+
+// TestValidateJSONSyntaxError tests that malformed instances are reported as syntax errors.
+func TestValidateJSONSyntaxError(t *testing.T) {
+	err := MustCompileJSON([]byte(`true`)).ValidateJSON([]byte(`{"a":`))
+	var serr *token.SyntaxError
+	if !errors.As(err, &serr) {
+		t.Errorf("ValidateJSON() error = %v, want a *token.SyntaxError", err)
+	}
+}
+
+// TestValidateNonFinite tests that the JSON5 values NaN and Infinity are validated without panicking:
+// NaN equals no value and is within no bounds, and Infinity compares by value.
+func TestValidateNonFinite(t *testing.T) {
+	tests := []struct {
+		schema, input string
+		valid         bool
+	}{
+		{schema: `{"enum": [1, "a"]}`, input: `NaN`, valid: false},
+		{schema: `{"const": 1}`, input: `NaN`, valid: false},
+		{schema: `{"maximum": 1}`, input: `NaN`, valid: false},
+		{schema: `{"minimum": 1}`, input: `NaN`, valid: false},
+		{schema: `{"uniqueItems": true}`, input: `[NaN, 1, NaN]`, valid: true},
+		{schema: `{"uniqueItems": true}`, input: `[Infinity, 1, Infinity]`, valid: false},
+		{schema: `{"minimum": 1e308}`, input: `Infinity`, valid: true},
+		{schema: `{"exclusiveMaximum": 0}`, input: `-Infinity`, valid: true},
+	}
+
+	for _, tt := range tests {
+		tokens, err := token.Tokenizer([]byte(tt.input), token.JSON5())
+		if err != nil {
+			t.Fatalf("Tokenizer() error = %v", err)
+		}
+		instance, err := parser.Parse(tokens, parser.JSON5())
+		if err != nil {
+			t.Fatalf("Parse() error = %v", err)
+		}
+		if err := MustCompileJSON([]byte(tt.schema)).Validate(instance); (err == nil) != tt.valid {
+			t.Errorf("Validate(%s) against %s error = %v, want valid %v", tt.input, tt.schema, err, tt.valid)
+		}
+	}
+}
@@ -0,0 +1,281 @@
+package schema
+
+import (
+	"fmt"
+	"github.com/onerciller/gojsonp"
+	"github.com/onerciller/gojsonp/parser"
+	"github.com/onerciller/gojsonp/pointer"
+	"github.com/onerciller/gojsonp/token"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// validate returns the violations of instance, located at location in the instance document.
+// Every keyword is checked independently, so all failures are reported, not just the first.
+func (s *Schema) validate(instance *parser.AstNode, location pointer.Pointer) []Violation {
+	if s.boolean != nil {
+		if *s.boolean {
+			return nil
+		}
+		return []Violation{{InstanceLocation: location, InstancePos: instance.Start, KeywordLocation: s.location,
+			KeywordPos: s.start, Keyword: "false", Message: "no value is allowed"}}
+	}
+
+	var violations []Violation
+	fail := func(keyword, format string, args ...interface{}) {
+		violations = append(violations, s.violation(instance, location, keyword, fmt.Sprintf(format, args...)))
+	}
+
+	if s.ref != nil {
+		violations = append(violations, s.ref.validate(instance, location)...)
+	}
+	if s.types != nil && !hasType(instance, s.types) {
+		fail("type", "got %s, want %s", typeOf(instance), strings.Join(s.types, " or "))
+	}
+	if s.enum != nil && !inEnum(instance, s.enum) {
+		fail("enum", "%s is not one of %s", display(instance), display(s.enum))
+	}
+	if s.constant != nil && !parser.Equal(instance, s.constant) {
+		fail("const", "%s does not equal %s", display(instance), display(s.constant))
+	}
+
+	switch value := instance.Value.(type) {
+	case string:
+		if instance.Type == token.String {
+			s.validateString(value, fail)
+		}
+	case *parser.Object:
+		violations = append(violations, s.validateObject(value, location, fail)...)
+	case []*parser.AstNode:
+		violations = append(violations, s.validateArray(value, location, fail)...)
+	default:
+		if instance.Type == token.Number {
+			s.validateNumber(instance, fail)
+		}
+	}
+
+	for _, sub := range s.allOf {
+		violations = append(violations, sub.validate(instance, location)...)
+	}
+	if s.anyOf != nil {
+		valid := false
+		for _, sub := range s.anyOf {
+			if len(sub.validate(instance, location)) == 0 {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			fail("anyOf", "matches none of the %d schemas", len(s.anyOf))
+		}
+	}
+	if s.oneOf != nil {
+		var matched []string
+		for i, sub := range s.oneOf {
+			if len(sub.validate(instance, location)) == 0 {
+				matched = append(matched, strconv.Itoa(i))
+			}
+		}
+		switch {
+		case len(matched) == 0:
+			fail("oneOf", "matches none of the %d schemas", len(s.oneOf))
+		case len(matched) > 1:
+			fail("oneOf", "matches schemas %s, want exactly one", strings.Join(matched, ", "))
+		}
+	}
+	if s.not != nil && len(s.not.validate(instance, location)) == 0 {
+		fail("not", "matches the schema it must not match")
+	}
+	return violations
+}
+
+// violation creates a Violation of keyword for instance.
+func (s *Schema) violation(instance *parser.AstNode, location pointer.Pointer, keyword, message string) Violation {
+	return Violation{
+		InstanceLocation: location,
+		InstancePos:      instance.Start,
+		KeywordLocation:  s.location.Append(keyword),
+		KeywordPos:       s.positions[keyword],
+		Keyword:          keyword,
+		Message:          message,
+	}
+}
+
+// validateNumber checks the numeric keywords; NaN, which is not ordered, is within no bounds.
+func (s *Schema) validateNumber(instance *parser.AstNode, fail func(keyword, format string, args ...interface{})) {
+	if s.maximum != nil {
+		if cmp, ok := parser.CompareNumbers(instance, s.maximum); !ok || cmp > 0 {
+			fail("maximum", "%s is greater than %s", display(instance), display(s.maximum))
+		}
+	}
+	if s.exclusiveMaximum != nil {
+		if cmp, ok := parser.CompareNumbers(instance, s.exclusiveMaximum); !ok || cmp >= 0 {
+			fail("exclusiveMaximum", "%s is not less than %s", display(instance), display(s.exclusiveMaximum))
+		}
+	}
+	if s.minimum != nil {
+		if cmp, ok := parser.CompareNumbers(instance, s.minimum); !ok || cmp < 0 {
+			fail("minimum", "%s is less than %s", display(instance), display(s.minimum))
+		}
+	}
+	if s.exclusiveMinimum != nil {
+		if cmp, ok := parser.CompareNumbers(instance, s.exclusiveMinimum); !ok || cmp <= 0 {
+			fail("exclusiveMinimum", "%s is not greater than %s", display(instance), display(s.exclusiveMinimum))
+		}
+	}
+	if s.multipleOf != nil {
+		n, okN := decimal(instance)
+		m, _ := decimal(s.multipleOf)
+		if !okN && isNumber(instance) {
+			fail("multipleOf", "%s has an exponent beyond ±%d and cannot be checked against %s",
+				display(instance), maxExponent, display(s.multipleOf))
+		} else if !okN || !new(big.Rat).Quo(n, m).IsInt() {
+			fail("multipleOf", "%s is not a multiple of %s", display(instance), display(s.multipleOf))
+		}
+	}
+}
+
+// validateString checks the string keywords; lengths count Unicode code points, not bytes.
+func (s *Schema) validateString(value string, fail func(keyword, format string, args ...interface{})) {
+	length := utf8.RuneCountInString(value)
+	if s.maxLength >= 0 && length > s.maxLength {
+		fail("maxLength", "length %d is greater than %d", length, s.maxLength)
+	}
+	if s.minLength >= 0 && length < s.minLength {
+		fail("minLength", "length %d is less than %d", length, s.minLength)
+	}
+	if s.pattern != nil && !s.pattern.MatchString(value) {
+		fail("pattern", "%q does not match %q", value, s.pattern.String())
+	}
+}
+
+// validateArray checks the array keywords and validates the elements against prefixItems and items.
+func (s *Schema) validateArray(elements []*parser.AstNode, location pointer.Pointer, fail func(keyword, format string, args ...interface{})) []Violation {
+	if s.maxItems >= 0 && len(elements) > s.maxItems {
+		fail("maxItems", "%d items, want at most %d", len(elements), s.maxItems)
+	}
+	if s.minItems >= 0 && len(elements) < s.minItems {
+		fail("minItems", "%d items, want at least %d", len(elements), s.minItems)
+	}
+	if s.uniqueItems {
+	unique:
+		for i := range elements {
+			for j := i + 1; j < len(elements); j++ {
+				if parser.Equal(elements[i], elements[j]) {
+					fail("uniqueItems", "items %d and %d are equal", i, j)
+					break unique
+				}
+			}
+		}
+	}
+
+	var violations []Violation
+	for i, element := range elements {
+		sub := s.items
+		if i < len(s.prefixItems) {
+			sub = s.prefixItems[i]
+		}
+		if sub != nil {
+			violations = append(violations, sub.validate(element, location.Append(strconv.Itoa(i)))...)
+		}
+	}
+	return violations
+}
+
+// validateObject checks the object keywords and validates each member against properties,
+// the matching patternProperties, or additionalProperties if neither applies.
+func (s *Schema) validateObject(object *parser.Object, location pointer.Pointer, fail func(keyword, format string, args ...interface{})) []Violation {
+	if s.maxProperties >= 0 && object.Len() > s.maxProperties {
+		fail("maxProperties", "%d properties, want at most %d", object.Len(), s.maxProperties)
+	}
+	if s.minProperties >= 0 && object.Len() < s.minProperties {
+		fail("minProperties", "%d properties, want at least %d", object.Len(), s.minProperties)
+	}
+	for _, name := range s.required {
+		if _, ok := object.Get(name); !ok {
+			fail("required", "missing property %q", name)
+		}
+	}
+
+	var violations []Violation
+	for _, member := range object.Members() {
+		memberLocation := location.Append(member.Key)
+		matched := false
+		if sub, ok := s.properties[member.Key]; ok {
+			violations = append(violations, sub.validate(member.Value, memberLocation)...)
+			matched = true
+		}
+		for _, p := range s.patternProperties {
+			if p.pattern.MatchString(member.Key) {
+				violations = append(violations, p.schema.validate(member.Value, memberLocation)...)
+				matched = true
+			}
+		}
+		if matched || s.additionalProperties == nil {
+			continue
+		}
+		if b := s.additionalProperties.boolean; b != nil && !*b {
+			// name the property rather than reporting the false schema
+			violations = append(violations, s.violation(member.Value, memberLocation, "additionalProperties",
+				fmt.Sprintf("property %q is not allowed", member.Key)))
+			continue
+		}
+		violations = append(violations, s.additionalProperties.validate(member.Value, memberLocation)...)
+	}
+	return violations
+}
+
+// typeOf returns the JSON Schema type name of instance; integers are numbers.
+func typeOf(instance *parser.AstNode) string {
+	switch instance.Type {
+	case token.Null:
+		return "null"
+	case token.Boolean:
+		return "boolean"
+	case token.Object:
+		return "object"
+	case token.Array:
+		return "array"
+	case token.Number:
+		return "number"
+	default:
+		return "string"
+	}
+}
+
+// hasType checks if instance is of one of types; "integer" matches numbers without a fractional part, like 1.0.
+func hasType(instance *parser.AstNode, types []string) bool {
+	kind := typeOf(instance)
+	for _, t := range types {
+		if t == kind {
+			return true
+		}
+		if t == "integer" && kind == "number" {
+			if isInteger(instance) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// inEnum checks if instance equals an element of the enum array.
+func inEnum(instance, enum *parser.AstNode) bool {
+	for _, element := range enum.Value.([]*parser.AstNode) {
+		if parser.Equal(instance, element) {
+			return true
+		}
+	}
+	return false
+}
+
+// display returns the compact JSON of a value for messages.
+func display(value *parser.AstNode) string {
+	data, err := gojsonp.Marshal(value)
+	if err != nil {
+		return "<" + err.Error() + ">"
+	}
+	return string(data)
+}
@@ -0,0 +1,205 @@
+package schema
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// violations validates instance against schema and returns the keyword locations of the violations.
+func violations(t *testing.T, schema, instance string) []string {
+	t.Helper()
+	s, err := CompileJSON([]byte(schema))
+	if err != nil {
+		t.Fatalf("CompileJSON() error = %v", err)
+	}
+	err = s.ValidateJSON([]byte(instance))
+	if err == nil {
+		return nil
+	}
+	verr, ok := err.(*ValidationError)
+	if !ok {
+		t.Fatalf("ValidateJSON() error = %v, want a *ValidationError", err)
+	}
+	var locations []string
+	for _, v := range verr.Violations {
+		locations = append(locations, v.InstanceLocation.String()+" "+v.KeywordLocation.String())
+	}
+	return locations
+}
+
+// TestValidate tests each keyword with valid and invalid instances.
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		schema   string
+		instance string
+		want     []string
+	}{
+		{name: "True", schema: `true`, instance: `[1]`},
+		{name: "False", schema: `false`, instance: `null`, want: []string{" "}},
+		{name: "Empty", schema: `{}`, instance: `{"a": 1}`},
+		{name: "Type", schema: `{"type": "string"}`, instance: `1`, want: []string{" /type"}},
+		{name: "Type list", schema: `{"type": ["string", "null"]}`, instance: `null`},
+		{name: "Integer", schema: `{"type": "integer"}`, instance: `1.0`},
+		{name: "Integer fraction", schema: `{"type": "integer"}`, instance: `1.5`, want: []string{" /type"}},
+		{name: "Integer exponent", schema: `{"type": "integer"}`, instance: `1.50e1`},
+		{name: "Integer negative exponent", schema: `{"type": "integer"}`, instance: `15e-1`, want: []string{" /type"}},
+		{name: "Integer huge exponent", schema: `{"type": "integer"}`, instance: `1e5000000`},
+		{name: "Integer exponent beyond int", schema: `{"type": "integer"}`, instance: `-1.5e99999999999999999999`},
+		{name: "Integer tiny", schema: `{"type": "integer"}`, instance: `1e-99999999999999999999`, want: []string{" /type"}},
+		{name: "Integer zero", schema: `{"type": "integer"}`, instance: `0.0e-99999999999999999999`},
+		{name: "Number accepts integers", schema: `{"type": "number"}`, instance: `7`},
+		{name: "Enum", schema: `{"enum": ["a", 1, {"b": null}]}`, instance: `{"b": null}`},
+		{name: "Enum miss", schema: `{"enum": ["a", 1]}`, instance: `"b"`, want: []string{" /enum"}},
+		{name: "Const", schema: `{"const": 10}`, instance: `1e1`},
+		{name: "Const miss", schema: `{"const": null}`, instance: `false`, want: []string{" /const"}},
+		{name: "Maximum", schema: `{"maximum": 5}`, instance: `5`},
+		{name: "Maximum exceeded", schema: `{"maximum": 5}`, instance: `5.5`, want: []string{" /maximum"}},
+		{name: "Exclusive maximum", schema: `{"exclusiveMaximum": 5}`, instance: `5`, want: []string{" /exclusiveMaximum"}},
+		{name: "Minimum", schema: `{"minimum": -1}`, instance: `-2`, want: []string{" /minimum"}},
+		{name: "Exclusive minimum", schema: `{"exclusiveMinimum": 0}`, instance: `0.0001`},
+		{name: "Exclusive minimum equal", schema: `{"exclusiveMinimum": 0}`, instance: `0`, want: []string{" /exclusiveMinimum"}},
+		{name: "Multiple of decimal", schema: `{"multipleOf": 0.1}`, instance: `0.3`},
+		{name: "Multiple of miss", schema: `{"multipleOf": 2}`, instance: `7`, want: []string{" /multipleOf"}},
+		{name: "Multiple of huge exponent", schema: `{"multipleOf": 2}`, instance: `1e500000`, want: []string{" /multipleOf"}},
+		{name: "Big numbers", schema: `{"maximum": 18446744073709551615}`, instance: `18446744073709551616`, want: []string{" /maximum"}},
+		{name: "Numeric keywords skip strings", schema: `{"minimum": 5, "multipleOf": 2}`, instance: `"1"`},
+		{name: "Length in code points", schema: `{"maxLength": 2, "minLength": 2}`, instance: `"é😀"`},
+		{name: "Too long", schema: `{"maxLength": 1}`, instance: `"ab"`, want: []string{" /maxLength"}},
+		{name: "Too short", schema: `{"minLength": 3}`, instance: `"ab"`, want: []string{" /minLength"}},
+		{name: "Pattern is not anchored", schema: `{"pattern": "b+"}`, instance: `"abbc"`},
+		{name: "Pattern miss", schema: `{"pattern": "^[0-9]+$"}`, instance: `"12a"`, want: []string{" /pattern"}},
+		{name: "Items", schema: `{"minItems": 1, "maxItems": 2}`, instance: `[1, 2, 3]`, want: []string{" /maxItems"}},
+		{name: "Unique items", schema: `{"uniqueItems": true}`, instance: `[1, {"a": 2}, {"a": 2.0}]`, want: []string{" /uniqueItems"}},
+		{
+			name:     "Prefix items and items",
+			schema:   `{"prefixItems": [{"type": "string"}, {"type": "number"}], "items": {"type": "boolean"}}`,
+			instance: `["a", "b", true, 1]`,
+			want:     []string{"/1 /prefixItems/1/type", "/3 /items/type"},
+		},
+		{name: "Items false", schema: `{"prefixItems": [true], "items": false}`, instance: `[1, 2]`, want: []string{"/1 /items"}},
+		{
+			name:     "Required",
+			schema:   `{"required": ["a", "b", "c"]}`,
+			instance: `{"b": 1}`,
+			want:     []string{" /required", " /required"},
+		},
+		{name: "Properties count", schema: `{"minProperties": 2}`, instance: `{"a": 1}`, want: []string{" /minProperties"}},
+		{name: "Max properties", schema: `{"maxProperties": 0}`, instance: `{"a": 1}`, want: []string{" /maxProperties"}},
+		{
+			name:     "Properties",
+			schema:   `{"properties": {"a": {"type": "integer"}, "b/c": {"type": "string"}}}`,
+			instance: `{"a": "x", "b/c": 1, "d": null}`,
+			want:     []string{"/a /properties/a/type", "/b~1c /properties/b~1c/type"},
+		},
+		{
+			name:     "Pattern and additional properties",
+			schema:   `{"properties": {"id": true}, "patternProperties": {"^x-": {"type": "string"}}, "additionalProperties": false}`,
+			instance: `{"id": 1, "x-a": "ok", "x-b": 2, "other": true}`,
+			want:     []string{"/x-b /patternProperties/^x-/type", "/other /additionalProperties"},
+		},
+		{
+			name:     "Additional properties schema",
+			schema:   `{"additionalProperties": {"type": "number"}}`,
+			instance: `{"a": 1, "b": "2"}`,
+			want:     []string{"/b /additionalProperties/type"},
+		},
+		{
+			name:     "All of",
+			schema:   `{"allOf": [{"type": "number"}, {"minimum": 3}, {"maximum": 4}]}`,
+			instance: `"x"`,
+			want:     []string{" /allOf/0/type"},
+		},
+		{name: "All of each", schema: `{"allOf": [{"minimum": 3}, {"multipleOf": 2}]}`, instance: `1`, want: []string{" /allOf/0/minimum", " /allOf/1/multipleOf"}},
+		{name: "Any of", schema: `{"anyOf": [{"type": "string"}, {"minimum": 3}]}`, instance: `4`},
+		{name: "Any of none", schema: `{"anyOf": [{"type": "string"}, {"minimum": 3}]}`, instance: `2`, want: []string{" /anyOf"}},
+		{name: "One of", schema: `{"oneOf": [{"type": "string"}, {"type": "number", "minimum": 3}]}`, instance: `"a"`},
+		{name: "One of both", schema: `{"oneOf": [{"type": "integer"}, {"minimum": 3}]}`, instance: `4`, want: []string{" /oneOf"}},
+		{name: "One of none", schema: `{"oneOf": [{"type": "integer"}, {"minimum": 3}]}`, instance: `1.5`, want: []string{" /oneOf"}},
+		{name: "Not", schema: `{"not": {"type": "null"}}`, instance: `null`, want: []string{" /not"}},
+		{
+			name:     "Ref to defs",
+			schema:   `{"$defs": {"positive": {"exclusiveMinimum": 0}}, "properties": {"n": {"$ref": "#/$defs/positive"}}}`,
+			instance: `{"n": -1}`,
+			want:     []string{"/n /$defs/positive/exclusiveMinimum"},
+		},
+		{
+			name:     "Ref with siblings",
+			schema:   `{"$defs": {"s": {"type": "string"}}, "$ref": "#/$defs/s", "maxLength": 1}`,
+			instance: `"ab"`,
+			want:     []string{" /maxLength"},
+		},
+		{
+			name:     "Recursive ref",
+			schema:   `{"type": "object", "properties": {"children": {"type": "array", "items": {"$ref": "#"}}, "name": {"type": "string"}}}`,
+			instance: `{"name": "a", "children": [{"name": "b", "children": [{"name": 3}]}]}`,
+			want:     []string{"/children/0/children/0/name /properties/name/type"},
+		},
+		{
+			name:     "Anchor ref",
+			schema:   `{"$defs": {"id": {"$anchor": "id", "type": "integer"}}, "items": {"$ref": "#id"}}`,
+			instance: `[1, "2"]`,
+			want:     []string{"/1 /$defs/id/type"},
+		},
+		{
+			name:     "Ref with root id and escapes",
+			schema:   `{"$id": "https://example.com/s.json", "$defs": {"a b": {"type": "null"}}, "$ref": "https://example.com/s.json#/$defs/a%20b"}`,
+			instance: `0`,
+			want:     []string{" /$defs/a b/type"},
+		},
+		{name: "Unknown keywords are ignored", schema: `{"format": "email", "title": "x"}`, instance: `"not an email"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := violations(t, tt.schema, tt.instance); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() got = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestValidateHugeExponents tests that numbers with huge exponents are checked without building them:
+// converting 1e500000 to an exact fraction takes milliseconds, so a thousand of them would take seconds.
+func TestValidateHugeExponents(t *testing.T) {
+	s, err := CompileJSON([]byte(`{"items": {"type": "integer", "multipleOf": 3}}`))
+	if err != nil {
+		t.Fatalf("CompileJSON() error = %v", err)
+	}
+	instance := "[" + strings.Repeat("1e500000, ", 999) + "1e500000]"
+
+	start := time.Now()
+	err = s.ValidateJSON([]byte(instance))
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("ValidateJSON() took %v", elapsed)
+	}
+	verr, ok := err.(*ValidationError)
+	if !ok || len(verr.Violations) != 1000 || verr.Violations[0].KeywordLocation.String() != "/items/multipleOf" {
+		t.Errorf("ValidateJSON() error = %v, want a multipleOf violation for each item", err)
+	}
+
+	if _, err := CompileJSON([]byte(`{"multipleOf": 1e500000}`)); !errors.Is(err, ErrInvalidSchema) {
+		t.Errorf("CompileJSON() error = %v, want ErrInvalidSchema", err)
+	}
+}
+
+// TestValidateAll tests that every violation of a document is reported, not just the first one.
+func TestValidateAll(t *testing.T) {
+	schema := `{
+  "type": "object",
+  "required": ["name", "age"],
+  "properties": {
+    "name": {"type": "string", "minLength": 1},
+    "age": {"type": "integer", "minimum": 0},
+    "tags": {"type": "array", "items": {"type": "string"}, "uniqueItems": true}
+  }
+}`
+	instance := `{"name": "", "tags": ["a", 1, "a"]}`
+	want := []string{" /required", "/name /properties/name/minLength", "/tags /properties/tags/uniqueItems", "/tags/1 /properties/tags/items/type"}
+	if got := violations(t, schema, instance); !reflect.DeepEqual(got, want) {
+		t.Errorf("Validate() got = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,73 @@
+package token
+
+import (
+	"bytes"
+	"unicode/utf8"
+)
+
+// isComment checks if a comment, "//" or "/*", starts at the current position.
+func (l *Lexer) isComment() bool {
+	l.ensure(2)
+	return l.buf[l.pos] == '/' && l.pos+1 < len(l.buf) && (l.buf[l.pos+1] == '/' || l.buf[l.pos+1] == '*')
+}
+
+// readComment reads the comment at the current position into a Comment token holding its text with the delimiters.
+// A line comment ends before the line break, which is left to the whitespace loop;
+// a block comment ends after "*/" and may span lines.
+// Example: `/* port */ 8080` is tokenized as {Type: Comment, Val: "/* port */"}, followed by the number.
+func (l *Lexer) readComment() (Token, error) {
+	start := l.position(l.pos)
+	block := l.buf[l.pos+1] == '*'
+
+	// the text is copied as it is read, since fill may discard the start of a long comment
+	text := append(l.scratch[:0], l.buf[l.pos:l.pos+2]...)
+	l.pos += 2
+	for {
+		l.ensure(2)
+		if l.pos >= len(l.buf) {
+			if block {
+				return l.fail(NewSyntaxError(ErrUnclosedComment, start, "", "*/"))
+			}
+			break
+		}
+		if !block && (l.buf[l.pos] == '\n' || l.buf[l.pos] == '\r') {
+			break
+		}
+		if block && bytes.HasPrefix(l.buf[l.pos:], []byte("*/")) {
+			text = append(text, "*/"...)
+			l.pos += 2
+			break
+		}
+		text = append(text, l.buf[l.pos])
+		l.advance()
+	}
+	l.scratch = text
+	return Token{Type: Comment, Val: l.value(text), Start: start, End: l.position(l.pos)}, nil
+}
+
+// literalType returns the type of the true, false or null literal at the current position, or ILLEGAL.
+// Unlike determineTokenType it accepts a literal followed by a comment, or by any non-ASCII character in JSON5.
+func (l *Lexer) literalType() Type {
+	for _, literal := range []struct {
+		text string
+		t    Type
+	}{{"true", Boolean}, {"false", Boolean}, {"null", Null}} {
+		if bytes.HasPrefix(l.buf[l.pos:], []byte(literal.text)) && l.terminates(l.pos+len(literal.text)) {
+			return literal.t
+		}
+	}
+	return ILLEGAL
+}
+
+// terminates checks if the byte at index may follow a number or literal.
+// JSONC and JSON5 also allow a comment, and JSON5 any non-ASCII character, which is reported by the next call to Next
+// if it is not whitespace.
+func (l *Lexer) terminates(index int) bool {
+	if index >= len(l.buf) || isTerminatingCharacter(l.buf[index]) || l.isSpace(l.buf[index]) {
+		return true
+	}
+	if (l.options.JSONC || l.options.JSON5) && l.buf[index] == '/' {
+		return true
+	}
+	return l.options.JSON5 && l.buf[index] >= utf8.RuneSelf
+}
@@ -0,0 +1,157 @@
+package token
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// TestTokenizerJSONC tests that JSONC returns comments as tokens between the strict JSON tokens.
+func TestTokenizerJSONC(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		options  []Option
+		expected []Token
+	}{
+		{
+			name:    "Line and block comments",
+			input:   "// settings\n{\"tabSize\": 4, /* spaces */ \"wrap\": true // on\r\n}",
+			options: []Option{JSONC()},
+			expected: []Token{
+				{Type: Comment, Val: "// settings"}, {Type: LeftBrace, Val: "{"}, {Type: String, Val: "tabSize"},
+				{Type: Colon, Val: ":"}, {Type: Number, Val: "4"}, {Type: Comma, Val: ","}, {Type: Comment, Val: "/* spaces */"},
+				{Type: String, Val: "wrap"}, {Type: Colon, Val: ":"}, {Type: Boolean, Val: "true"}, {Type: Comment, Val: "// on"},
+				{Type: RightBrace, Val: "}"}, {Type: EOF},
+			},
+		},
+		{
+			name:    "Comments directly after values",
+			input:   "[1/**/,null// end\n]",
+			options: []Option{JSONC()},
+			expected: []Token{
+				{Type: LeftBracket, Val: "["}, {Type: Number, Val: "1"}, {Type: Comment, Val: "/**/"}, {Type: Comma, Val: ","},
+				{Type: Null, Val: "null"}, {Type: Comment, Val: "// end"}, {Type: RightBracket, Val: "]"}, {Type: EOF},
+			},
+		},
+		{
+			name:    "Multi-line block comment",
+			input:   "/* a\n * b\n */1",
+			options: []Option{JSONC()},
+			expected: []Token{
+				{Type: Comment, Val: "/* a\n * b\n */"}, {Type: Number, Val: "1"}, {Type: EOF},
+			},
+		},
+		{
+			name:    "Comment at the end of input",
+			input:   "1 // last",
+			options: []Option{JSONC()},
+			expected: []Token{
+				{Type: Number, Val: "1"}, {Type: Comment, Val: "// last"}, {Type: EOF},
+			},
+		},
+		{
+			name:    "With JSON5",
+			input:   "{a: 1, // one\n}",
+			options: []Option{JSON5(), JSONC()},
+			expected: []Token{
+				{Type: LeftBrace, Val: "{"}, {Type: String, Val: "a"}, {Type: Colon, Val: ":"}, {Type: Number, Val: "1"},
+				{Type: Comma, Val: ","}, {Type: Comment, Val: "// one"}, {Type: RightBrace, Val: "}"}, {Type: EOF},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := Tokenizer([]byte(tc.input), tc.options...)
+			if err != nil {
+				t.Fatalf("Tokenizer() error = %v", err)
+			}
+			if got := withoutPositions(result); !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("Expected %#v\n, got %#v", tc.expected, got)
+			}
+		})
+	}
+}
+
+// TestTokenizerJSONCIsStrict tests that JSONC accepts comments only and keeps the rest of strict JSON.
+func TestTokenizerJSONCIsStrict(t *testing.T) {
+	if _, err := Tokenizer([]byte("// c\n1")); !errors.Is(err, ErrInvalidCharacter) {
+		t.Errorf("Expected ErrInvalidCharacter without JSONC, got %v", err)
+	}
+	for _, input := range []string{`[1,]`, `{a: 1}`, `'a'`, `0x10`, "[1 / 2]"} {
+		if _, err := Tokenizer([]byte(input), JSONC()); err == nil {
+			t.Errorf("Input %q: expected an error in JSONC mode", input)
+		}
+	}
+}
+
+// TestTokenizerJSONCPositions tests the positions of comments and of the tokens after them.
+func TestTokenizerJSONCPositions(t *testing.T) {
+	result, err := Tokenizer([]byte("/* a\nb */ [1, // c\n2]"), JSONC())
+	if err != nil {
+		t.Fatalf("Tokenizer() error = %v", err)
+	}
+	expected := []struct{ start, end Position }{
+		{Position{Offset: 0, Line: 1, Column: 1}, Position{Offset: 9, Line: 2, Column: 5}},
+		{Position{Offset: 14, Line: 2, Column: 10}, Position{Offset: 18, Line: 2, Column: 14}},
+		{Position{Offset: 19, Line: 3, Column: 1}, Position{Offset: 20, Line: 3, Column: 2}},
+	}
+	for i, tk := range []Token{result[0], result[4], result[5]} {
+		if tk.Start != expected[i].start || tk.End != expected[i].end {
+			t.Errorf("Token %q: expected %#v-%#v, got %#v-%#v", tk.Val, expected[i].start, expected[i].end, tk.Start, tk.End)
+		}
+	}
+}
+
+// TestTokenizerJSONCUnclosedComment tests that an unterminated block comment is reported at its start.
+func TestTokenizerJSONCUnclosedComment(t *testing.T) {
+	_, err := Tokenizer([]byte("{\"a\": 1,\n  /* b: 2}"), JSONC())
+	var syntaxErr *SyntaxError
+	if !errors.As(err, &syntaxErr) || !errors.Is(err, ErrUnclosedComment) {
+		t.Fatalf("Expected a *SyntaxError of kind ErrUnclosedComment, got %v", err)
+	}
+	if syntaxErr.Pos != (Position{Offset: 11, Line: 2, Column: 3}) {
+		t.Errorf("Expected position 2:3, got %#v", syntaxErr.Pos)
+	}
+	if err.Error() != `2:3: unclosed block comment, expected */` {
+		t.Errorf("Unexpected message %q", err.Error())
+	}
+}
+
+// TestLexerMatchesTokenizerJSONC tests that a JSONC Lexer reading one byte at a time yields the same tokens and errors as Tokenizer.
+func TestLexerMatchesTokenizerJSONC(t *testing.T) {
+	inputs := []string{
+		"// c\n{\"a\": [true// t\n, 1/* n */]}",
+		"/*" + strings.Repeat("long comment ", 1000) + "*/ 1",
+		"[1, /* unclosed",
+	}
+
+	for _, input := range inputs {
+		expected, expectedErr := Tokenizer([]byte(input), JSONC())
+
+		lexer := NewLexer(iotest.OneByteReader(strings.NewReader(input)), JSONC())
+		var result []Token
+		var err error
+		for {
+			var tk Token
+			tk, err = lexer.Next()
+			if err != nil {
+				break
+			}
+			result = append(result, tk)
+			if tk.Type == EOF {
+				break
+			}
+		}
+
+		if !reflect.DeepEqual(err, expectedErr) {
+			t.Errorf("Input %.40q: expected error %v, got %v", input, expectedErr, err)
+		}
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("Input %.40q: expected %#v\n, got %#v\n", input, expected, result)
+		}
+	}
+}
@@ -0,0 +1,247 @@
+package token
+
+import (
+	"bytes"
+	"io"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Encoding is a Unicode encoding of JSON text.
+type Encoding int
+
+// Encodings JSON text may be written in; see RFC 4627, section 3.
+const (
+	UTF8 Encoding = iota
+	UTF16BE
+	UTF16LE
+	UTF32BE
+	UTF32LE
+)
+
+// String method for Encoding to get its name, e.g. "UTF-16LE".
+func (e Encoding) String() string {
+	switch e {
+	case UTF16BE:
+		return "UTF-16BE"
+	case UTF16LE:
+		return "UTF-16LE"
+	case UTF32BE:
+		return "UTF-32BE"
+	case UTF32LE:
+		return "UTF-32LE"
+	default:
+		return "UTF-8"
+	}
+}
+
+// unitSize returns the size of a code unit of the encoding in bytes.
+func (e Encoding) unitSize() int {
+	switch e {
+	case UTF16BE, UTF16LE:
+		return 2
+	case UTF32BE, UTF32LE:
+		return 4
+	default:
+		return 1
+	}
+}
+
+// DetectEncoding detects the encoding of JSON text from its first four bytes.
+// A byte order mark decides if there is one. Otherwise the pattern of null bytes does, as the first two characters
+// of JSON text are ASCII: "00 00 00 xx" is UTF-32BE, "00 xx 00 xx" UTF-16BE, "xx 00 00 00" UTF-32LE and "xx 00 xx 00" UTF-16LE.
+// Anything else is UTF-8.
+// Example: DetectEncoding([]byte{'[', 0, '1', 0}) returns UTF16LE.
+func DetectEncoding(prefix []byte) Encoding {
+	encoding, _ := detectEncoding(prefix)
+	return encoding
+}
+
+// detectEncoding detects the encoding like DetectEncoding and also returns the size of the byte order mark, if any.
+func detectEncoding(prefix []byte) (Encoding, int) {
+	switch {
+	case bytes.HasPrefix(prefix, []byte{0x00, 0x00, 0xFE, 0xFF}):
+		return UTF32BE, 4
+	case bytes.HasPrefix(prefix, []byte{0xFF, 0xFE, 0x00, 0x00}):
+		return UTF32LE, 4
+	case bytes.HasPrefix(prefix, []byte{0xFE, 0xFF}):
+		return UTF16BE, 2
+	case bytes.HasPrefix(prefix, []byte{0xFF, 0xFE}):
+		return UTF16LE, 2
+	case bytes.HasPrefix(prefix, bom):
+		return UTF8, len(bom)
+	}
+
+	// a single character, e.g. "1", has fewer than four bytes in UTF-16
+	if len(prefix) >= 4 {
+		switch {
+		case prefix[0] == 0 && prefix[1] == 0 && prefix[2] == 0 && prefix[3] != 0:
+			return UTF32BE, 0
+		case prefix[0] != 0 && prefix[1] == 0 && prefix[2] == 0 && prefix[3] == 0:
+			return UTF32LE, 0
+		}
+	}
+	if len(prefix) >= 2 {
+		switch {
+		case prefix[0] == 0 && prefix[1] != 0:
+			return UTF16BE, 0
+		case prefix[0] != 0 && prefix[1] == 0:
+			return UTF16LE, 0
+		}
+	}
+	return UTF8, 0
+}
+
+// Transcode converts JSON text in any encoding to UTF-8 and returns the detected encoding.
+// The byte order mark of UTF-16 and UTF-32 text is dropped; UTF-8 text is returned as it is, without copying.
+// Code units that do not encode a character, such as unpaired surrogates, become U+FFFD.
+// Example: Transcode([]byte{0xFF, 0xFE, '[', 0, ']', 0}) returns "[]" and UTF16LE.
+func Transcode(input []byte) ([]byte, Encoding) {
+	encoding, bomSize := detectEncoding(input)
+	if encoding == UTF8 {
+		return input, encoding
+	}
+	output, _ := decodeUnits(make([]byte, 0, len(input)), input[bomSize:], encoding, true)
+	return output, encoding
+}
+
+// decodeUnits appends the characters encoded in input to output as UTF-8.
+// Unless final is set, an incomplete code unit or surrogate pair at the end is not decoded but returned,
+// so it can be completed by the next input.
+func decodeUnits(output, input []byte, encoding Encoding, final bool) ([]byte, []byte) {
+	size := encoding.unitSize()
+	for len(input) >= size {
+		r := codeUnit(input, encoding)
+
+		// a high surrogate takes the next code unit with it if the two form a pair
+		if size == 2 && r >= 0xD800 && r < 0xDC00 {
+			if len(input) < 4 {
+				if !final {
+					break
+				}
+			} else if pair := utf16.DecodeRune(r, codeUnit(input[2:], encoding)); pair != utf8.RuneError {
+				output = utf8.AppendRune(output, pair)
+				input = input[4:]
+				continue
+			}
+		}
+
+		// invalid code points such as lone surrogates are encoded as U+FFFD
+		output = utf8.AppendRune(output, r)
+		input = input[size:]
+	}
+	if final && len(input) > 0 {
+		output = utf8.AppendRune(output, utf8.RuneError)
+		input = nil
+	}
+	return output, input
+}
+
+// codeUnit returns the value of the code unit at the start of input.
+func codeUnit(input []byte, encoding Encoding) rune {
+	switch encoding {
+	case UTF16BE:
+		return rune(input[0])<<8 | rune(input[1])
+	case UTF16LE:
+		return rune(input[1])<<8 | rune(input[0])
+	case UTF32BE:
+		return rune(uint32(input[0])<<24 | uint32(input[1])<<16 | uint32(input[2])<<8 | uint32(input[3]))
+	case UTF32LE:
+		return rune(uint32(input[3])<<24 | uint32(input[2])<<16 | uint32(input[1])<<8 | uint32(input[0]))
+	default:
+		return rune(input[0])
+	}
+}
+
+// UTF8Reader converts JSON text in any encoding to UTF-8 while it is read, like Transcode does for a whole input.
+// The encoding is detected from the first four bytes read.
+// Example:
+//
+//	reader := NewUTF8Reader(file)
+//	lexer := NewLexer(reader)
+type UTF8Reader struct {
+	reader io.Reader
+
+	// encoding is valid once detected is set
+	encoding Encoding
+	detected bool
+
+	// raw holds input not converted yet and decoded the converted input not returned by Read yet
+	raw     []byte
+	decoded []byte
+
+	// err is the error of the last read from reader, returned once raw and decoded are drained
+	err error
+
+	// buf is reused for reading from reader
+	buf []byte
+}
+
+// NewUTF8Reader creates a UTF8Reader reading from r.
+func NewUTF8Reader(r io.Reader) *UTF8Reader {
+	return &UTF8Reader{reader: r}
+}
+
+// Encoding returns the detected encoding, reading the first bytes of the input if no Read did so yet.
+func (u *UTF8Reader) Encoding() Encoding {
+	u.detect()
+	return u.encoding
+}
+
+// Read reads up to len(p) bytes of UTF-8 into p.
+func (u *UTF8Reader) Read(p []byte) (int, error) {
+	u.detect()
+	if u.encoding == UTF8 {
+		if len(u.raw) > 0 {
+			n := copy(p, u.raw)
+			u.raw = u.raw[n:]
+			return n, nil
+		}
+		if u.err != nil {
+			return 0, u.err
+		}
+		return u.reader.Read(p)
+	}
+
+	for len(u.decoded) == 0 {
+		u.decoded, u.raw = decodeUnits(u.decoded[:0], u.raw, u.encoding, u.err != nil)
+		if len(u.decoded) > 0 {
+			break
+		}
+		if u.err != nil {
+			return 0, u.err
+		}
+		u.read(chunkSize)
+	}
+	n := copy(p, u.decoded)
+	u.decoded = u.decoded[n:]
+	return n, nil
+}
+
+// detect reads the first four bytes, or as many as there are, and detects the encoding from them.
+func (u *UTF8Reader) detect() {
+	if u.detected {
+		return
+	}
+	for len(u.raw) < 4 && u.err == nil {
+		u.read(4 - len(u.raw))
+	}
+	var bomSize int
+	u.encoding, bomSize = detectEncoding(u.raw)
+	if u.encoding != UTF8 {
+		u.raw = u.raw[bomSize:]
+	}
+	u.detected = true
+}
+
+// read appends up to n bytes from reader to raw, recording the read error.
+func (u *UTF8Reader) read(n int) {
+	if cap(u.buf) < n {
+		u.buf = make([]byte, n)
+	}
+	n, err := u.reader.Read(u.buf[:n])
+	u.raw = append(u.raw, u.buf[:n]...)
+	if err != nil {
+		u.err = err
+	}
+}
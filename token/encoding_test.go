@@ -0,0 +1,163 @@
+package token
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"unicode/utf16"
+)
+
+// encode returns s in the given encoding, preceded by a byte order mark if withBOM is set.
+func encode(s string, encoding Encoding, withBOM bool) []byte {
+	if encoding == UTF8 {
+		if withBOM {
+			return append(append([]byte{}, bom...), s...)
+		}
+		return []byte(s)
+	}
+	units := []rune(s)
+	if withBOM {
+		units = append([]rune{0xFEFF}, units...)
+	}
+	var out []byte
+	for _, r := range units {
+		switch encoding {
+		case UTF16BE, UTF16LE:
+			for _, u := range utf16.Encode([]rune{r}) {
+				if encoding == UTF16BE {
+					out = append(out, byte(u>>8), byte(u))
+				} else {
+					out = append(out, byte(u), byte(u>>8))
+				}
+			}
+		case UTF32BE:
+			out = append(out, byte(r>>24), byte(r>>16), byte(r>>8), byte(r))
+		case UTF32LE:
+			out = append(out, byte(r), byte(r>>8), byte(r>>16), byte(r>>24))
+		}
+	}
+	return out
+}
+
+// TestDetectEncoding tests the detection of each encoding by byte order mark and by the pattern of null bytes.
+func TestDetectEncoding(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    []byte
+		expected Encoding
+	}{
+		{name: "UTF-8", input: []byte(`{"a": 1}`), expected: UTF8},
+		{name: "UTF-8 with BOM", input: encode(`{}`, UTF8, true), expected: UTF8},
+		{name: "UTF-16BE", input: encode(`{}`, UTF16BE, false), expected: UTF16BE},
+		{name: "UTF-16LE", input: encode(`{}`, UTF16LE, false), expected: UTF16LE},
+		{name: "UTF-32BE", input: encode(`{}`, UTF32BE, false), expected: UTF32BE},
+		{name: "UTF-32LE", input: encode(`{}`, UTF32LE, false), expected: UTF32LE},
+		{name: "UTF-16BE with BOM", input: encode(`é`, UTF16BE, true), expected: UTF16BE},
+		{name: "UTF-16LE with BOM", input: encode(`é`, UTF16LE, true), expected: UTF16LE},
+		{name: "UTF-32BE with BOM", input: encode(`é`, UTF32BE, true), expected: UTF32BE},
+		{name: "UTF-32LE with BOM", input: encode(`é`, UTF32LE, true), expected: UTF32LE},
+		{name: "Single character in UTF-16LE", input: encode(`1`, UTF16LE, false), expected: UTF16LE},
+		{name: "Single character in UTF-16BE", input: encode(`1`, UTF16BE, false), expected: UTF16BE},
+		{name: "Single byte", input: []byte(`1`), expected: UTF8},
+		{name: "Empty", input: nil, expected: UTF8},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := DetectEncoding(tc.input); got != tc.expected {
+				t.Errorf("Expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+// TestTranscode tests the conversion of each encoding to UTF-8.
+func TestTranscode(t *testing.T) {
+	text := `{"name": "café 😀", "n": [1, 2]}`
+	for _, encoding := range []Encoding{UTF8, UTF16BE, UTF16LE, UTF32BE, UTF32LE} {
+		for _, withBOM := range []bool{false, true} {
+			output, detected := Transcode(encode(text, encoding, withBOM))
+			expected := text
+			if withBOM && encoding == UTF8 {
+				expected = "\uFEFF" + text
+			}
+			if detected != encoding || string(output) != expected {
+				t.Errorf("%v (BOM %v): expected %q, got %q as %v", encoding, withBOM, expected, output, detected)
+			}
+		}
+	}
+
+	input := []byte(`{"a": 1}`)
+	if output, _ := Transcode(input); &output[0] != &input[0] {
+		t.Errorf("Expected UTF-8 input to be returned without copying")
+	}
+}
+
+// TestTranscodeInvalid tests that code units that do not encode a character become U+FFFD.
+func TestTranscodeInvalid(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    []byte
+		expected string
+	}{
+		{name: "Lone high surrogate", input: []byte{'"', 0, 0x3D, 0xD8, '"', 0}, expected: "\"\uFFFD\""},
+		{name: "Lone low surrogate", input: []byte{'"', 0, 0x00, 0xDE, '"', 0}, expected: "\"\uFFFD\""},
+		{name: "High surrogate at the end", input: []byte{'"', 0, 0x3D, 0xD8}, expected: "\"\uFFFD"},
+		{name: "Odd trailing byte", input: []byte{'1', 0, '2'}, expected: "1\uFFFD"},
+		{name: "Code point out of range", input: []byte{'"', 0, 0, 0, 0, 0, 0x11, 0}, expected: "\"\uFFFD"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if output, _ := Transcode(tc.input); string(output) != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, output)
+			}
+		})
+	}
+}
+
+// TestUTF8Reader tests that the streaming conversion matches Transcode, also with input read one byte at a time.
+func TestUTF8Reader(t *testing.T) {
+	text := `["surrogate pair 😀", "` + strings.Repeat("long ", 2000) + `"]`
+	for _, encoding := range []Encoding{UTF8, UTF16BE, UTF16LE, UTF32BE, UTF32LE} {
+		input := encode(text, encoding, encoding == UTF16LE)
+		expected, _ := Transcode(input)
+
+		reader := NewUTF8Reader(iotest.OneByteReader(bytes.NewReader(input)))
+		if got := reader.Encoding(); got != encoding {
+			t.Errorf("Expected encoding %v, got %v", encoding, got)
+		}
+		output, err := io.ReadAll(reader)
+		if err != nil || !bytes.Equal(output, expected) {
+			t.Errorf("%v: expected %d bytes matching Transcode, got %d bytes, error %v", encoding, len(expected), len(output), err)
+		}
+	}
+}
+
+// TestTokenizerAnyEncoding tests that AnyEncoding tokenizes UTF-16 input and reports its encoding, also when streaming.
+func TestTokenizerAnyEncoding(t *testing.T) {
+	input := encode(`{"name": "café"}`, UTF16LE, true)
+	expected, _ := Tokenizer([]byte(`{"name": "café"}`))
+
+	result, err := Tokenizer(input, AnyEncoding())
+	if err != nil || !reflect.DeepEqual(result, expected) {
+		t.Errorf("Tokenizer() got = %v, %v, want %v", result, err, expected)
+	}
+	if _, err := Tokenizer(input); err == nil {
+		t.Errorf("Expected UTF-16 input to be rejected without AnyEncoding")
+	}
+
+	lexer := NewLexer(iotest.OneByteReader(bytes.NewReader(input)), AnyEncoding())
+	if tk, err := lexer.Next(); err != nil || tk.Type != LeftBrace {
+		t.Errorf("Next() got = %v, %v, want {", tk, err)
+	}
+	if encoding := lexer.Encoding(); encoding != UTF16LE {
+		t.Errorf("Expected encoding UTF-16LE, got %v", encoding)
+	}
+	if encoding := NewLexer(strings.NewReader(`1`)).Encoding(); encoding != UTF8 {
+		t.Errorf("Expected encoding UTF-8 without AnyEncoding, got %v", encoding)
+	}
+}
@@ -0,0 +1,132 @@
+package token
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Kinds of syntax errors. A SyntaxError wraps exactly one of them, so callers can use errors.Is.
+// Example: errors.Is(err, token.ErrUnclosedString)
+var (
+	ErrUnexpectedToken  = errors.New("unexpected token")
+	ErrUnexpectedEOF    = errors.New("unexpected end of input")
+	ErrInvalidCharacter = errors.New("invalid character")
+	ErrInvalidLiteral   = errors.New("invalid literal")
+	ErrInvalidNumber    = errors.New("invalid number")
+	ErrUnclosedString   = errors.New("unclosed string literal")
+	ErrInvalidEscape    = errors.New("invalid escape sequence")
+	ErrInvalidUnicode   = errors.New("invalid unicode escape")
+	ErrControlCharacter = errors.New("invalid control character in string literal")
+	ErrUnclosedComment  = errors.New("unclosed block comment")
+	ErrInvalidUTF8      = errors.New("invalid UTF-8 in string literal")
+)
+
+// SyntaxError describes malformed input found by the tokenizer or the parser.
+type SyntaxError struct {
+
+	// Kind is one of the Err* sentinels above
+	Kind error
+
+	// Pos is the position of the offending input
+	Pos Position
+
+	// Found holds the offending bytes; it is empty at the end of input
+	Found string
+
+	// Expected describes what would have been valid, e.g. "string or }"; it may be empty
+	Expected string
+
+	// Msg is a human-readable description of the error
+	Msg string
+}
+
+// NewSyntaxError creates a SyntaxError and builds its message from the kind, found and expected values.
+// Example: NewSyntaxError(ErrUnexpectedToken, pos, ":", ", or }") reads `unexpected token ":", expected , or }`.
+func NewSyntaxError(kind error, pos Position, found, expected string) *SyntaxError {
+	msg := kind.Error()
+	if found != "" {
+		msg += fmt.Sprintf(" %q", found)
+	}
+	if expected != "" {
+		msg += ", expected " + expected
+	}
+	return &SyntaxError{Kind: kind, Pos: pos, Found: found, Expected: expected, Msg: msg}
+}
+
+// Error method for SyntaxError to get the "line:column: message" representation of the error.
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Pos, e.Msg)
+}
+
+// Unwrap returns the kind of the error, so errors.Is(err, ErrInvalidNumber) works.
+func (e *SyntaxError) Unwrap() error {
+	return e.Kind
+}
+
+// offendingBytes returns the input at index that caused an error.
+// Words such as `trues` are returned whole, anything else as a single character.
+func offendingBytes(input []byte, index int) string {
+	if index >= len(input) {
+		return ""
+	}
+	end := index
+	for end < len(input) && isWordCharacter(input[end]) {
+		end++
+	}
+	if end == index {
+		_, size := utf8.DecodeRune(input[index:])
+		end = index + size
+	}
+	return string(input[index:end])
+}
+
+// numberErrorBytes returns the invalid number starting at input[index] for an error message:
+// the bytes up to end, where reading the number stopped, and the bytes that continue it.
+// Example: For input "1.e5]" it returns "1.e5", and for "1." it returns "1.".
+func numberErrorBytes(input []byte, index, end int) string {
+	if end > len(input) {
+		end = len(input)
+	}
+	for end < len(input) && continuesNumber(input[end]) {
+		end++
+	}
+	if end == index {
+		return offendingBytes(input, index)
+	}
+	return string(input[index:end])
+}
+
+// continuesNumber checks if a byte following a number makes it invalid rather than starting the next token,
+// e.g. the 'a' of 123abc or the second '.' of 1.2.3.
+func continuesNumber(c byte) bool {
+	return isWordCharacter(c) || c == '.' || c == '+' || c == '-'
+}
+
+// isWordCharacter checks if a byte is an ASCII letter, digit or underscore.
+func isWordCharacter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || isDigit(c) || c == '_'
+}
+
+// describeTypes lists token types for an error message.
+// Example: For [String, Comma, RightBrace], it returns "string, , or }".
+func describeTypes(types []Type) string {
+	var names []string
+	for _, t := range types {
+		switch t {
+		case Quote:
+			continue
+		case String, Number, Boolean, Null:
+			names = append(names, strings.ToLower(t.String()))
+		case EOF:
+			names = append(names, "end of input")
+		default:
+			names = append(names, t.String())
+		}
+	}
+	if len(names) < 2 {
+		return strings.Join(names, "")
+	}
+	return strings.Join(names[:len(names)-1], ", ") + " or " + names[len(names)-1]
+}
@@ -0,0 +1,340 @@
+package token
+
+import (
+	"bytes"
+	"unicode"
+	"unicode/utf8"
+)
+
+// json5Sequences extends validSequences with the trailing commas JSON5 allows before '}' and ']'.
+var json5Sequences = func() map[state][]Type {
+	sequences := make(map[state][]Type, len(validSequences))
+	for s, next := range validSequences {
+		sequences[s] = next
+	}
+	sequences[stateElement] = validSequences[stateFirstElement]
+	sequences[stateKey] = validSequences[stateFirstKey]
+	return sequences
+}()
+
+// skipJSON5Space skips a non-ASCII whitespace character at the current position and reports whether it skipped one.
+// Example: U+00A0 and U+2028 are skipped.
+func (l *Lexer) skipJSON5Space() bool {
+	l.ensure(utf8.UTFMax)
+	if l.buf[l.pos] < utf8.RuneSelf {
+		return false
+	}
+	r, size := utf8.DecodeRune(l.buf[l.pos:])
+	if !isJSON5Space(r) {
+		return false
+	}
+	l.pos += size
+	return true
+}
+
+// isJSON5Space checks if a non-ASCII character is whitespace in JSON5:
+// a Unicode space separator, a line or paragraph separator, or the byte order mark.
+func isJSON5Space(r rune) bool {
+	return unicode.Is(unicode.Zs, r) || r == '\u2028' || r == '\u2029' || r == '\uFEFF'
+}
+
+// json5TokenType refines the type of the token at the current position for JSON5:
+// identifiers in key position are keys, a single quote starts a string,
+// and '+', '.', Infinity and NaN start numbers.
+func (l *Lexer) json5TokenType(char byte, t Type) Type {
+	if isKey(l.state) &&
+		(isIdentifierStart(rune(char)) || char == '\\' || char >= utf8.RuneSelf) {
+		return String
+	}
+	switch char {
+	case '\'':
+		return Quote
+	case '+', '.', 'I', 'N':
+		return Number
+	}
+	return t
+}
+
+// readJSON5String reads the single- or double-quoted JSON5 string at the current position.
+// Strings may span lines with escaped line breaks, so the position is tracked across them.
+// Like readString, it decodes the value as the input is read and checks MaxStringBytes after each read.
+func (l *Lexer) readJSON5String() (Token, error) {
+	start := l.position(l.pos)
+	quote := l.buf[l.pos]
+	value, end, err := continueJSON5String(l.scratch[:0], l.buf, l.pos+1, quote, l.options.ReplaceInvalidUTF8, !l.eof)
+	for err == ErrUnclosedString && !l.eof {
+		if err := l.checkString(len(value), start); err != nil {
+			return l.fail(err)
+		}
+		for l.pos < end {
+			l.advance()
+		}
+		l.fill()
+		value, end, err = continueJSON5String(value, l.buf, l.pos, quote, l.options.ReplaceInvalidUTF8, !l.eof)
+	}
+	l.scratch = value
+	if err == ErrUnclosedString {
+		return l.fail(NewSyntaxError(err, start, "", string(quote)))
+	} else if err == nil {
+		if err := l.checkString(len(value), start); err != nil {
+			return l.fail(err)
+		}
+	}
+
+	for l.pos < end {
+		l.advance()
+	}
+	if err != nil {
+		return l.fail(NewSyntaxError(err, l.position(end), stringErrorBytes(l.buf, end), ""))
+	}
+	l.pos++
+	return Token{Type: String, Val: l.value(value), Start: start, End: l.position(l.pos)}, nil
+}
+
+// continueJSON5String decodes a JSON5 string closed by quote, ' or ", from input[current], which is just after
+// its opening quote. Besides the JSON escapes it decodes \' \v \0 and \xHH, removes escaped line breaks,
+// and turns any other escaped character into itself; only raw line breaks and escaped digits are invalid.
+// It appends the decoded value to dst and returns it with the index of the closing quote, like continueString,
+// and treats invalid UTF-8 and partial input like continueString.
+// Example: For input `'it\'s \
+// fine'` and current 1, it appends "it's fine".
+func continueJSON5String(dst, input []byte, current int, quote byte, replace, partial bool) ([]byte, int, error) {
+	value := dst
+	for current < len(input) {
+		char := input[current]
+		switch {
+		case char == quote:
+			return value, current, nil
+		case char == '\n' || char == '\r':
+			return value, current, ErrControlCharacter
+		case char >= utf8.RuneSelf:
+			next, size, err := appendRune(value, input, current, replace)
+			if err != nil {
+				return value, current, err
+			}
+			value, current = next, current+size
+			continue
+		case char != '\\':
+			value = append(value, char)
+			current++
+			continue
+		case partial && len(input)-current < escapeLookahead:
+			return value, current, ErrUnclosedString
+		}
+
+		current++
+		if current >= len(input) {
+			return value, current - 1, ErrUnclosedString
+		}
+		switch char := input[current]; char {
+		case 'b':
+			value = append(value, '\b')
+		case 'f':
+			value = append(value, '\f')
+		case 'n':
+			value = append(value, '\n')
+		case 'r':
+			value = append(value, '\r')
+		case 't':
+			value = append(value, '\t')
+		case 'v':
+			value = append(value, '\v')
+		case '0':
+			if current+1 < len(input) && isDigit(input[current+1]) {
+				return value, current, ErrInvalidEscape
+			}
+			value = append(value, 0)
+		case '1', '2', '3', '4', '5', '6', '7', '8', '9':
+			return value, current, ErrInvalidEscape
+		case 'x':
+			hi, okHi := hexValue(input, current+1)
+			lo, okLo := hexValue(input, current+2)
+			if !okHi || !okLo {
+				return value, current, ErrInvalidEscape
+			}
+			value = utf8.AppendRune(value, rune(hi<<4|lo))
+			current += 2
+		case 'u':
+			r, end, ok := readUnicodeEscape(input, current)
+			if !ok {
+				return value, current, ErrInvalidUnicode
+			}
+			value = utf8.AppendRune(value, r)
+			current = end
+		case '\r':
+			// line continuation: the escaped line break is removed
+			if current+1 < len(input) && input[current+1] == '\n' {
+				current++
+			}
+		case '\n':
+		default:
+			// U+2028 and U+2029 continue the line as well; other characters stand for themselves
+			if r, size := utf8.DecodeRune(input[current:]); r == '\u2028' || r == '\u2029' {
+				current += size - 1
+			} else if char >= utf8.RuneSelf {
+				// read and check the character as if it was not escaped
+				continue
+			} else {
+				value = append(value, char)
+			}
+		}
+		current++
+	}
+	return value, current, ErrUnclosedString
+}
+
+// hexValue returns the value of the hexadecimal digit at input[index].
+func hexValue(input []byte, index int) (byte, bool) {
+	if index >= len(input) {
+		return 0, false
+	}
+	switch c := input[index]; {
+	case c >= '0' && c <= '9':
+		return c - '0', true
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10, true
+	case c >= 'A' && c <= 'F':
+		return c - 'A' + 10, true
+	}
+	return 0, false
+}
+
+// readJSON5Number reads a number starting at input[index] following the JSON5 grammar:
+//
+//	number  = [ "+" / "-" ] ( "Infinity" / "NaN" / hex / decimal )
+//	hex     = "0" ( "x" / "X" ) 1*HEXDIG
+//	decimal = ( int [ "." *DIGIT ] / "." 1*DIGIT ) [ exp ]
+//
+// where int and exp are as in JSON. It returns the index just past the last byte of the number.
+// Example: For input "+.5e1,", it returns 5.
+func readJSON5Number(input []byte, index int) (int, error) {
+	current := index
+	if current < len(input) && (input[current] == '+' || input[current] == '-') {
+		current++
+	}
+	for _, name := range []string{"Infinity", "NaN"} {
+		if bytes.HasPrefix(input[current:], []byte(name)) {
+			return current + len(name), nil
+		}
+		if current < len(input) && len(input)-current < len(name) && bytes.HasPrefix([]byte(name), input[current:]) {
+			// the input ends within the name
+			return len(input), ErrInvalidNumber
+		}
+	}
+
+	if current+1 < len(input) && input[current] == '0' && (input[current+1] == 'x' || input[current+1] == 'X') {
+		current += 2
+		start := current
+		for _, ok := hexValue(input, current); ok; _, ok = hexValue(input, current) {
+			current++
+		}
+		if current == start {
+			return current, ErrInvalidNumber
+		}
+		return current, nil
+	}
+
+	// integer part, which may be left out before a fraction
+	hasInt := true
+	switch {
+	case current < len(input) && input[current] == '0':
+		current++
+	case current < len(input) && isDigit(input[current]):
+		current = skipDigits(input, current)
+	default:
+		hasInt = false
+	}
+
+	// fraction, which may be empty after an integer part
+	if current < len(input) && input[current] == '.' {
+		current++
+		if !hasInt && (current >= len(input) || !isDigit(input[current])) {
+			return current, ErrInvalidNumber
+		}
+		current = skipDigits(input, current)
+	} else if !hasInt {
+		return current, ErrInvalidNumber
+	}
+
+	if current < len(input) && (input[current] == 'e' || input[current] == 'E') {
+		current++
+		if current < len(input) && (input[current] == '+' || input[current] == '-') {
+			current++
+		}
+		if current >= len(input) || !isDigit(input[current]) {
+			return current, ErrInvalidNumber
+		}
+		current = skipDigits(input, current)
+	}
+	return current, nil
+}
+
+// IsJSON5Number checks if s is exactly one number following the JSON5 number grammar,
+// which includes every JSON number.
+// Example: IsJSON5Number("0x1F") and IsJSON5Number("-Infinity") return true.
+func IsJSON5Number(s string) bool {
+	end, err := readJSON5Number([]byte(s), 0)
+	return err == nil && end == len(s)
+}
+
+// readIdentifier reads the unquoted key at the current position.
+// Identifiers are short in practice, so it decodes the name again after each read, but stops reading once
+// the name exceeds MaxStringBytes.
+func (l *Lexer) readIdentifier() (Token, error) {
+	name, length, err := readIdentifier(l.scratch[:0], l.buf[l.pos:], 0)
+	for length+escapeLookahead >= len(l.buf)-l.pos && l.checkString(len(name), l.position(l.pos)) == nil && l.fill() {
+		name, length, err = readIdentifier(l.scratch[:0], l.buf[l.pos:], 0)
+	}
+	l.scratch = name
+	if err != nil {
+		return l.fail(NewSyntaxError(err, l.position(l.pos+length), offendingBytes(l.buf, l.pos+length), ""))
+	}
+	if err := l.checkString(len(name), l.position(l.pos)); err != nil {
+		return l.fail(err)
+	}
+	return l.emit(String, l.value(name), length), nil
+}
+
+// readIdentifier reads an ECMAScript identifier name starting at input[index], as JSON5 allows for object keys.
+// Identifiers start with a letter, '$' or '_' and continue with those, digits, combining marks and connectors;
+// any of these characters may be written as a \uXXXX escape.
+// It appends the decoded name to dst and returns it with the index just past the name.
+// Example: For input "$id: 1", it appends "$id" and returns 3.
+func readIdentifier(dst, input []byte, index int) ([]byte, int, error) {
+	name := dst
+	current := index
+	for current < len(input) {
+		r, size := utf8.DecodeRune(input[current:])
+		end := current + size
+		if r == '\\' {
+			if current+1 >= len(input) || input[current+1] != 'u' {
+				return dst, current, ErrInvalidEscape
+			}
+			var ok bool
+			if r, end, ok = readUnicodeEscape(input, current+1); !ok {
+				return dst, current + 1, ErrInvalidUnicode
+			}
+			end++
+		}
+		if !isIdentifierPart(r) || (current == index && !isIdentifierStart(r)) {
+			if current == index {
+				return dst, current, ErrInvalidCharacter
+			}
+			break
+		}
+		name = utf8.AppendRune(name, r)
+		current = end
+	}
+	return name, current, nil
+}
+
+// isIdentifierStart checks if r may start an identifier.
+func isIdentifierStart(r rune) bool {
+	return unicode.IsLetter(r) || unicode.Is(unicode.Nl, r) || r == '$' || r == '_'
+}
+
+// isIdentifierPart checks if r may continue an identifier, including the zero-width joiner and non-joiner.
+func isIdentifierPart(r rune) bool {
+	return isIdentifierStart(r) || unicode.In(r, unicode.Mn, unicode.Mc, unicode.Nd, unicode.Pc) ||
+		r == '\u200C' || r == '\u200D'
+}
@@ -0,0 +1,249 @@
+package token
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// TestTokenizerJSON5 tests the tokens produced for the JSON5 extensions.
+func TestTokenizerJSON5(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected []Token
+	}{
+		{
+			name:  "Comments",
+			input: "// header\n{/* inline */\"a\": 1 // trailing\n}/**/",
+			expected: []Token{
+				{Type: LeftBrace, Val: "{"}, {Type: String, Val: "a"}, {Type: Colon, Val: ":"}, {Type: Number, Val: "1"},
+				{Type: RightBrace, Val: "}"}, {Type: EOF},
+			},
+		},
+		{
+			name:  "Trailing commas",
+			input: `{"a": [1, 2,],}`,
+			expected: []Token{
+				{Type: LeftBrace, Val: "{"}, {Type: String, Val: "a"}, {Type: Colon, Val: ":"}, {Type: LeftBracket, Val: "["},
+				{Type: Number, Val: "1"}, {Type: Comma, Val: ","}, {Type: Number, Val: "2"}, {Type: Comma, Val: ","},
+				{Type: RightBracket, Val: "]"}, {Type: Comma, Val: ","}, {Type: RightBrace, Val: "}"}, {Type: EOF},
+			},
+		},
+		{
+			name:  "Identifier keys",
+			input: `{name: 1, $id_2: 2, null: 3, café: 4, a\u200Db: 5}`,
+			expected: []Token{
+				{Type: LeftBrace, Val: "{"},
+				{Type: String, Val: "name"}, {Type: Colon, Val: ":"}, {Type: Number, Val: "1"}, {Type: Comma, Val: ","},
+				{Type: String, Val: "$id_2"}, {Type: Colon, Val: ":"}, {Type: Number, Val: "2"}, {Type: Comma, Val: ","},
+				{Type: String, Val: "null"}, {Type: Colon, Val: ":"}, {Type: Number, Val: "3"}, {Type: Comma, Val: ","},
+				{Type: String, Val: "café"}, {Type: Colon, Val: ":"}, {Type: Number, Val: "4"}, {Type: Comma, Val: ","},
+				{Type: String, Val: "a\u200Db"}, {Type: Colon, Val: ":"}, {Type: Number, Val: "5"},
+				{Type: RightBrace, Val: "}"}, {Type: EOF},
+			},
+		},
+		{
+			name:  "Single-quoted strings",
+			input: `['it\'s', '"quoted"', "it's"]`,
+			expected: []Token{
+				{Type: LeftBracket, Val: "["}, {Type: String, Val: "it's"}, {Type: Comma, Val: ","},
+				{Type: String, Val: `"quoted"`}, {Type: Comma, Val: ","}, {Type: String, Val: "it's"},
+				{Type: RightBracket, Val: "]"}, {Type: EOF},
+			},
+		},
+		{
+			name:  "Numbers",
+			input: `[0x1F, -0XaB, +1, .5, 5., -.5e2, Infinity, -Infinity, +NaN, NaN]`,
+			expected: []Token{
+				{Type: LeftBracket, Val: "["},
+				{Type: Number, Val: "0x1F"}, {Type: Comma, Val: ","}, {Type: Number, Val: "-0XaB"}, {Type: Comma, Val: ","},
+				{Type: Number, Val: "+1"}, {Type: Comma, Val: ","}, {Type: Number, Val: ".5"}, {Type: Comma, Val: ","},
+				{Type: Number, Val: "5."}, {Type: Comma, Val: ","}, {Type: Number, Val: "-.5e2"}, {Type: Comma, Val: ","},
+				{Type: Number, Val: "Infinity"}, {Type: Comma, Val: ","}, {Type: Number, Val: "-Infinity"}, {Type: Comma, Val: ","},
+				{Type: Number, Val: "+NaN"}, {Type: Comma, Val: ","}, {Type: Number, Val: "NaN"},
+				{Type: RightBracket, Val: "]"}, {Type: EOF},
+			},
+		},
+		{
+			name:  "Literals followed by comments",
+			input: "[true/**/, null// end\n]",
+			expected: []Token{
+				{Type: LeftBracket, Val: "["}, {Type: Boolean, Val: "true"}, {Type: Comma, Val: ","}, {Type: Null, Val: "null"},
+				{Type: RightBracket, Val: "]"}, {Type: EOF},
+			},
+		},
+		{
+			name:     "Unicode whitespace",
+			input:    "\u00a0\ufeff[\u2028 1\u3000]\u2029",
+			expected: []Token{{Type: LeftBracket, Val: "["}, {Type: Number, Val: "1"}, {Type: RightBracket, Val: "]"}, {Type: EOF}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := Tokenizer([]byte(tc.input), JSON5())
+			if err != nil {
+				t.Fatalf("Tokenizer() error = %v", err)
+			}
+			if got := withoutPositions(result); !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("Expected %#v\n, got %#v", tc.expected, got)
+			}
+		})
+	}
+}
+
+// TestTokenizerJSON5IsOptIn tests that strict mode still rejects the JSON5 extensions.
+func TestTokenizerJSON5IsOptIn(t *testing.T) {
+	inputs := []string{`// c` + "\n1", `[1,]`, `{a: 1}`, `'a'`, `0x10`, `+1`, `.5`, `Infinity`, "[1\u00a0]"}
+	for _, input := range inputs {
+		if _, err := Tokenizer([]byte(input)); err == nil {
+			t.Errorf("Input %q: expected an error in strict mode", input)
+		}
+		if _, err := Tokenizer([]byte(input), JSON5()); err != nil {
+			t.Errorf("Input %q: unexpected error in JSON5 mode: %v", input, err)
+		}
+	}
+}
+
+// TestContinueJSON5String tests the escapes and line continuations of JSON5 strings.
+func TestContinueJSON5String(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected string
+		end      int
+		err      error
+	}{
+		{name: "Single quotes", input: `'a"b'`, expected: `a"b`, end: 4},
+		{name: "JSON escapes", input: `"\"\\\/\b\f\n\r\té"`, expected: "\"\\/\b\f\n\r\té", end: 19},
+		{name: "JavaScript escapes", input: `'\'\v\0\x41'`, expected: "'\v\x00A", end: 11},
+		{name: "Identity escapes", input: `'\a\ \é'`, expected: "a é", end: 8},
+		{name: "Line continuation", input: "'a\\\nb\\\r\nc\\\rd'", expected: "abcd", end: 12},
+		{name: "Separator continuation", input: "'a\\\u2028b'", expected: "ab", end: 7},
+		{name: "Raw separators and tabs", input: "'\u2028\t'", expected: "\u2028\t", end: 5},
+		{name: "Raw line break", input: "'a\nb'", err: ErrControlCharacter},
+		{name: "Escaped digit", input: `'\1'`, err: ErrInvalidEscape},
+		{name: "Octal-like zero", input: `'\01'`, err: ErrInvalidEscape},
+		{name: "Short hex escape", input: `'\x4'`, err: ErrInvalidEscape},
+		{name: "Mismatched quotes", input: `'a"`, err: ErrUnclosedString},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			value, end, err := continueJSON5String(nil, []byte(tc.input), 1, tc.input[0], false, false)
+			if err != tc.err {
+				t.Fatalf("Expected error %v, got %v", tc.err, err)
+			}
+			if err == nil && (string(value) != tc.expected || end != tc.end) {
+				t.Errorf("Expected (%q, %d), got (%q, %d)", tc.expected, tc.end, value, end)
+			}
+		})
+	}
+}
+
+// TestIsJSON5Number tests the JSON5 number grammar.
+func TestIsJSON5Number(t *testing.T) {
+	valid := []string{"0", "-1.5e3", "+1", ".5", "5.", "+.5E-1", "0x0", "-0xFf", "Infinity", "+Infinity", "-NaN"}
+	invalid := []string{"", "+", ".", "0x", "0xg", "01", "1e", "infinity", "Inf", "--1", "1.2.3"}
+	for _, s := range valid {
+		if !IsJSON5Number(s) {
+			t.Errorf("IsJSON5Number(%q) = false, want true", s)
+		}
+	}
+	for _, s := range invalid {
+		if IsJSON5Number(s) {
+			t.Errorf("IsJSON5Number(%q) = true, want false", s)
+		}
+	}
+}
+
+// TestTokenizerJSON5Errors tests the syntax errors specific to JSON5 and their positions.
+func TestTokenizerJSON5Errors(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input string
+		kind  error
+		pos   Position
+	}{
+		{name: "Unclosed block comment", input: "[1,\n /* note", kind: ErrUnclosedComment, pos: Position{Offset: 5, Line: 2, Column: 2}},
+		{name: "Single slash", input: "[1 / 2]", kind: ErrInvalidCharacter, pos: Position{Offset: 3, Line: 1, Column: 4}},
+		{name: "Double trailing comma", input: "[1,,]", kind: ErrUnexpectedToken, pos: Position{Offset: 3, Line: 1, Column: 4}},
+		{name: "Leading comma", input: "[,1]", kind: ErrUnexpectedToken, pos: Position{Offset: 1, Line: 1, Column: 2}},
+		{name: "Identifier value", input: "{a: b}", kind: ErrInvalidLiteral, pos: Position{Offset: 4, Line: 1, Column: 5}},
+		{name: "Invalid key character", input: "{€: 1}", kind: ErrInvalidCharacter, pos: Position{Offset: 1, Line: 1, Column: 2}},
+		{name: "Invalid key escape", input: `{\x: 1}`, kind: ErrInvalidEscape, pos: Position{Offset: 1, Line: 1, Column: 2}},
+		{name: "Invalid number", input: "[0x]", kind: ErrInvalidNumber, pos: Position{Offset: 1, Line: 1, Column: 2}},
+		{name: "Error after multi-line string", input: "['a\\\nb', '\\1']", kind: ErrInvalidEscape, pos: Position{Offset: 11, Line: 2, Column: 7}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Tokenizer([]byte(tc.input), JSON5())
+			var syntaxErr *SyntaxError
+			if !errors.As(err, &syntaxErr) || !errors.Is(err, tc.kind) {
+				t.Fatalf("Expected a *SyntaxError of kind %v, got %v", tc.kind, err)
+			}
+			if syntaxErr.Pos != tc.pos {
+				t.Errorf("Expected position %#v, got %#v", tc.pos, syntaxErr.Pos)
+			}
+		})
+	}
+}
+
+// TestTokenizerJSON5Positions tests that positions stay correct across comments and multi-line strings.
+func TestTokenizerJSON5Positions(t *testing.T) {
+	result, err := Tokenizer([]byte("/* a\nb */ {k: 'x\\\ny', z: 1}"), JSON5())
+	if err != nil {
+		t.Fatalf("Tokenizer() error = %v", err)
+	}
+	expected := []Position{{Offset: 10, Line: 2, Column: 6}, {Offset: 11, Line: 2, Column: 7}, {Offset: 14, Line: 2, Column: 10}, {Offset: 22, Line: 3, Column: 5}}
+	for i, tk := range []Token{result[0], result[1], result[3], result[5]} {
+		if tk.Start != expected[i] {
+			t.Errorf("Token %q: expected start %#v, got %#v", tk.Val, expected[i], tk.Start)
+		}
+	}
+	if end := result[3].End; end != (Position{Offset: 20, Line: 3, Column: 3}) {
+		t.Errorf("Expected the string to end at 3:3, got %#v", end)
+	}
+}
+
+// TestLexerMatchesTokenizerJSON5 tests that a JSON5 Lexer reading one byte at a time yields the same tokens and errors as Tokenizer.
+func TestLexerMatchesTokenizerJSON5(t *testing.T) {
+	inputs := []string{
+		"// c\n{a: 'b', /* long\ncomment */ 'c': [0x1F, .5, +Infinity,],}",
+		"{key\\u0041: 1, é : 2}",
+		"'multi\\\nline'",
+		"[1, /* unclosed",
+		"/*" + strings.Repeat("long comment ", 1000) + "*/ 1",
+		"{a: 'bad \\1'}",
+	}
+
+	for _, input := range inputs {
+		expected, expectedErr := Tokenizer([]byte(input), JSON5())
+
+		lexer := NewLexer(iotest.OneByteReader(strings.NewReader(input)), JSON5())
+		var result []Token
+		var err error
+		for {
+			var tk Token
+			tk, err = lexer.Next()
+			if err != nil {
+				break
+			}
+			result = append(result, tk)
+			if tk.Type == EOF {
+				break
+			}
+		}
+
+		if !reflect.DeepEqual(err, expectedErr) {
+			t.Errorf("Input %.40q: expected error %v, got %v", input, expectedErr, err)
+		}
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("Input %.40q: expected %#v\n, got %#v\n", input, expected, result)
+		}
+	}
+}
@@ -0,0 +1,465 @@
+package token
+
+import (
+	"bytes"
+	"io"
+)
+
+// chunkSize is the number of bytes the Lexer asks its reader for at a time.
+const chunkSize = 4096
+
+// literalLookahead is the number of bytes needed to recognize a literal and its terminator, e.g. "false,".
+const literalLookahead = 6
+
+// bom is the UTF-8 encoding of the byte order mark U+FEFF.
+var bom = []byte{0xEF, 0xBB, 0xBF}
+
+// escapeLookahead is the number of bytes a string escape may look ahead, e.g. a surrogate pair "\ud83d\ude00".
+const escapeLookahead = 12
+
+// Lexer reads tokens one at a time from an io.Reader.
+// Only the current token and the unread part of the last read are kept in memory,
+// so the input can be much larger than the available memory.
+// Example:
+//
+//	lexer := NewLexer(os.Stdin)
+//	for {
+//		tk, err := lexer.Next()
+//		if err != nil || tk.Type == EOF {
+//			break
+//		}
+//	}
+type Lexer struct {
+	reader io.Reader
+
+	// transcoder converts the input of a Lexer with the AnyEncoding option, and encoding is the encoding of an in-memory input
+	transcoder *UTF8Reader
+	encoding   Encoding
+
+	// buf holds buffered input; buf[pos:] has not been tokenized yet
+	buf []byte
+	pos int
+
+	// offset is the input offset of buf[0]
+	offset int
+
+	// eof is set once the reader is exhausted, err holds a read error other than io.EOF
+	eof bool
+	err error
+
+	// failed holds the error returned by Next, which is returned again on every later call
+	failed error
+
+	// stack holds the open objects and arrays, and state what may come next in the innermost one
+	stack *Stack
+	state state
+
+	// tokens counts the tokens returned so far, for Limits.MaxTokens
+	tokens int
+
+	// limitStart is the input offset MaxInputBytes counts from; held is the number of bytes
+	// cut off the buffer at the limit, and readEOF and readErr the state of the reader before the cut
+	limitStart int
+	held       int
+	readEOF    bool
+	readErr    error
+
+	// options configures the accepted syntax and sequences lists the token types that may come next in each state
+	options   Options
+	sequences map[state][]Type
+
+	// line and lineStart track the line of the current character for token positions
+	line      int
+	lineStart int
+
+	// scratch is reused to decode strings and copy comments, so only the final Val is allocated
+	scratch []byte
+
+	// raw leaves Val empty, for RawTokenizer which only needs the positions
+	raw bool
+}
+
+// NewLexer creates a Lexer reading from r.
+// Example: NewLexer(r, JSON5())
+func NewLexer(r io.Reader, opts ...Option) *Lexer {
+	l := &Lexer{reader: r}
+	l.init(opts)
+	if l.options.AnyEncoding {
+		l.transcoder = NewUTF8Reader(r)
+		l.reader = l.transcoder
+	}
+	return l
+}
+
+// newBytesLexer creates a Lexer over an in-memory input without copying it, unless it has to be converted to UTF-8.
+func newBytesLexer(input []byte, opts []Option) *Lexer {
+	l := &Lexer{eof: true}
+	l.init(opts)
+	l.buf = input
+	if l.options.AnyEncoding {
+		l.buf, l.encoding = Transcode(input)
+	}
+	l.limitInput()
+	return l
+}
+
+// Encoding returns the encoding of the input, which is UTF8 unless the AnyEncoding option detected another one.
+func (l *Lexer) Encoding() Encoding {
+	if l.transcoder != nil {
+		return l.transcoder.Encoding()
+	}
+	return l.encoding
+}
+
+// init sets up the state shared by every Lexer.
+func (l *Lexer) init(opts []Option) {
+	l.stack, l.state, l.line = NewStack(), stateValue, 1
+	l.options = newOptions(opts)
+	l.sequences = validSequences
+	if l.options.JSON5 {
+		l.sequences = json5Sequences
+	}
+}
+
+// Next returns the next token.
+// After the last token it returns an EOF token on every call.
+// On malformed input it returns a *SyntaxError, on input exceeding the Limits a *LimitError,
+// and read errors are returned as they are.
+func (l *Lexer) Next() (Token, error) {
+	tk, err := l.next()
+	if err != nil || tk.Type == EOF || tk.Type == Comment {
+		return tk, err
+	}
+	l.tokens++
+	if max := l.options.Limits.MaxTokens; max > 0 && l.tokens > max {
+		return l.fail(&LimitError{Kind: ErrMaxTokens, Limit: max, Pos: tk.Start})
+	}
+	if l.held > 0 && tk.End.Offset == l.offset+len(l.buf) && (tk.Type == Number || tk.Type == Boolean || tk.Type == Null) {
+		// the number or literal may continue past the limit, so it is not known to be complete
+		return l.fail(l.err)
+	}
+	return tk, nil
+}
+
+// ResetLimits starts counting MaxInputBytes and MaxTokens again from the current position,
+// so that they bound each value of a sequence of values rather than the whole input.
+// Input cut off at the previous limit is read again, unless the Lexer already returned the LimitError.
+func (l *Lexer) ResetLimits() {
+	l.tokens, l.limitStart = 0, l.offset+l.pos
+	if l.held > 0 && l.failed == nil {
+		l.buf = l.buf[:len(l.buf)+l.held]
+		l.eof, l.err, l.held = l.readEOF, l.readErr, 0
+		l.limitInput()
+	}
+}
+
+// next reads the next token for Next, which counts it.
+func (l *Lexer) next() (Token, error) {
+	if l.failed != nil {
+		return Token{}, l.failed
+	}
+
+	// A UTF-8 byte order mark may precede the input
+	if l.offset+l.pos == 0 {
+		l.skipBOM()
+	}
+
+	// Skip whitespace, and comments in JSON5; JSONC returns comments as tokens
+	for {
+		if l.pos >= len(l.buf) && !l.fill() {
+			return l.end()
+		}
+		if l.isSpace(l.buf[l.pos]) {
+			l.advance()
+			continue
+		}
+		if (l.options.JSONC || l.options.JSON5) && l.isComment() {
+			tk, err := l.readComment()
+			if err != nil || l.options.JSONC {
+				return tk, err
+			}
+			continue
+		}
+		if !l.options.JSON5 || !l.skipJSON5Space() {
+			break
+		}
+	}
+	l.ensure(literalLookahead)
+
+	char := l.buf[l.pos]
+
+	// Determine token type based on the current character
+	currentTokenType := determineTokenType(char, l.buf, l.pos)
+	if currentTokenType == ILLEGAL && (l.options.JSONC || l.options.JSON5) {
+		currentTokenType = l.literalType()
+	}
+	if l.options.JSON5 {
+		currentTokenType = l.json5TokenType(char, currentTokenType)
+	}
+
+	// A complete top-level value may be followed by another top-level value
+	if l.state == stateEnd && ContainsInArrays(valueStart, currentTokenType) {
+		l.state = stateValue
+	}
+
+	// Handle illegal characters and token sequences
+	if currentTokenType == ILLEGAL {
+		kind := ErrInvalidCharacter
+		if isWordCharacter(char) {
+			kind = ErrInvalidLiteral
+		}
+		return l.fail(NewSyntaxError(kind, l.position(l.pos), offendingBytes(l.buf, l.pos), ""))
+	}
+	if !isValidSequences(l.sequences, l.state, currentTokenType) {
+		expected := describeTypes(l.sequences[l.state])
+		return l.fail(NewSyntaxError(ErrUnexpectedToken, l.position(l.pos), l.offendingToken(currentTokenType), expected))
+	}
+
+	// Switch based on the current character to determine token type, and move to the state after it
+	switch currentTokenType {
+
+	// Example case: '{' is tokenized as {Type: LeftBrace, Val: "{"}
+	case LeftBrace:
+		if err := l.push(LeftBrace); err != nil {
+			return l.fail(err)
+		}
+		l.state = stateFirstKey
+	// Example case: '}' is tokenized as {Type: RightBrace, Val: "}"}
+	// The sequence check made sure that it closes an object.
+	case RightBrace:
+		l.stack.Pop()
+		l.state = l.afterValue()
+	// Example case: '[' is tokenized as {Type: LeftBracket, Val: "["}
+	case LeftBracket:
+		if err := l.push(LeftBracket); err != nil {
+			return l.fail(err)
+		}
+		l.state = stateFirstElement
+	// Example case: ']' is tokenized as {Type: RightBracket, Val: "]"}
+	case RightBracket:
+		l.stack.Pop()
+		l.state = l.afterValue()
+	// Example case: ',' is tokenized as {Type: Comma, Val: ","}
+	case Comma:
+		l.state = stateElement
+		if l.stack.Peek() == LeftBrace {
+			l.state = stateKey
+		}
+	// Example case: ':' is tokenized as {Type: Colon, Val: ":"}
+	case Colon:
+		l.state = stateValue
+	// Example case: in JSON5, the key of '{name: "John"}' is tokenized as {Type: String, Val: "name"}
+	case String:
+		l.state = stateColon
+		return l.readIdentifier()
+	// Example case: '"name"' is tokenized as {Type: String, Val: "name"}
+	// Escape sequences such as \" and \u00e9 are decoded into the value.
+	case Quote:
+		if isKey(l.state) {
+			l.state = stateColon
+		} else {
+			l.state = l.afterValue()
+		}
+		return l.readString()
+	case Number:
+		l.state = l.afterValue()
+		return l.readNumber()
+	// Example case: 'true' is tokenized as {Type: Boolean, Val: "true"}
+	case Boolean:
+		l.state = l.afterValue()
+		length := 4
+		if !bytes.HasPrefix(l.buf[l.pos:], []byte("true")) {
+			length = 5
+		}
+		return l.emit(Boolean, l.text(length), length), nil
+	// Example case: 'null' is tokenized as {Type: Null, Val: "null"}
+	case Null:
+		l.state = l.afterValue()
+		return l.emit(Null, l.text(4), 4), nil
+	}
+
+	return l.emit(currentTokenType, l.text(1), 1), nil
+}
+
+// isSpace checks if c is whitespace: one of the four JSON whitespace characters,
+// or in JSON5 also a vertical tab or form feed. Non-ASCII whitespace is left to skipJSON5Space.
+func (l *Lexer) isSpace(c byte) bool {
+	return isWhitespace(c) || (l.options.JSON5 && (c == '\v' || c == '\f'))
+}
+
+// skipBOM skips a UTF-8 byte order mark at the start of the input.
+// Columns are counted from after it, as editors do not show it.
+func (l *Lexer) skipBOM() {
+	l.ensure(len(bom))
+	if bytes.HasPrefix(l.buf, bom) {
+		l.pos, l.lineStart = len(bom), len(bom)
+	}
+}
+
+// readString reads the string literal at the current position.
+// The value is decoded as the input is read, so a long string is neither decoded again after each read
+// nor kept in the buffer, which only holds the input from the first byte not yet decoded.
+// MaxStringBytes is checked after each read, so a string over the limit is not read to its end.
+func (l *Lexer) readString() (Token, error) {
+	if l.options.JSON5 {
+		return l.readJSON5String()
+	}
+	start := l.position(l.pos)
+	value, end, err := continueString(l.scratch[:0], l.buf, l.pos+1, l.options.ReplaceInvalidUTF8, !l.eof)
+	for err == ErrUnclosedString && !l.eof {
+		// strict strings hold no line breaks, so pos can move past the decoded bytes without tracking lines
+		if err := l.checkString(len(value), start); err != nil {
+			return l.fail(err)
+		}
+		l.pos = end
+		l.fill()
+		value, end, err = continueString(value, l.buf, l.pos, l.options.ReplaceInvalidUTF8, !l.eof)
+	}
+	l.scratch = value
+	if err == ErrUnclosedString {
+		return l.fail(NewSyntaxError(err, start, "", `"`))
+	} else if err != nil {
+		return l.fail(NewSyntaxError(err, l.position(end), stringErrorBytes(l.buf, end), ""))
+	}
+	if err := l.checkString(len(value), start); err != nil {
+		return l.fail(err)
+	}
+	l.pos = end + 1
+	return Token{Type: String, Val: l.value(value), Start: start, End: l.position(l.pos)}, nil
+}
+
+// readNumber reads the number at the current position.
+func (l *Lexer) readNumber() (Token, error) {
+	// read the longest valid number: optional minus, integer, fraction and exponent
+	read := readNumber
+	if l.options.JSON5 {
+		read = readJSON5Number
+	}
+	// the length is kept relative to pos, as fill may discard bytes before it even when it reads nothing
+	length, err := read(l.buf[l.pos:], 0)
+	for length >= len(l.buf)-l.pos && l.fill() {
+		length, err = read(l.buf[l.pos:], 0)
+	}
+
+	// example not valid number: 123abc, 01, 1., -
+	// A valid number followed by another token, as in '{1:2}', is left to the sequence check of the next token.
+	end := l.pos + length
+	if err != nil || end < len(l.buf) && continuesNumber(l.buf[end]) {
+		return l.fail(NewSyntaxError(ErrInvalidNumber, l.position(l.pos), numberErrorBytes(l.buf, l.pos, end), ""))
+	}
+	return l.emit(Number, l.text(length), length), nil
+}
+
+// offendingToken returns the source text of the unexpected token of type t at the current position for an error message:
+// a whole number such as "-1", and what offendingBytes returns for any other token.
+func (l *Lexer) offendingToken(t Type) string {
+	if t == Number {
+		read := readNumber
+		if l.options.JSON5 {
+			read = readJSON5Number
+		}
+		if length, err := read(l.buf[l.pos:], 0); err == nil {
+			return string(l.buf[l.pos : l.pos+length])
+		}
+	}
+	return offendingBytes(l.buf, l.pos)
+}
+
+// end is called once the input is exhausted; it makes sure every object and array was closed.
+func (l *Lexer) end() (Token, error) {
+	if l.err != nil {
+		return l.fail(l.err)
+	}
+	if len(l.stack.TokenTypes) > 0 {
+		expected := RightBrace.String()
+		if l.stack.Peek() == LeftBracket {
+			expected = RightBracket.String()
+		}
+		return l.fail(NewSyntaxError(ErrUnexpectedEOF, l.position(l.pos), "", expected))
+	}
+	return Token{Type: EOF, Val: "", Start: l.position(l.pos), End: l.position(l.pos)}, nil
+}
+
+// emit returns a token of the given length at the current position and moves past it.
+func (l *Lexer) emit(t Type, val string, length int) Token {
+	tk := Token{Type: t, Val: val, Start: l.position(l.pos), End: l.position(l.pos + length)}
+	l.pos += length
+	return tk
+}
+
+// text returns the value of a token whose value is its source text of the given length at the current position.
+func (l *Lexer) text(length int) string {
+	return l.value(l.buf[l.pos : l.pos+length])
+}
+
+// value converts a decoded token value to a string; in raw mode the value is not needed and left empty.
+func (l *Lexer) value(b []byte) string {
+	if l.raw {
+		return ""
+	}
+	return string(b)
+}
+
+// fail records err so that every later call to Next returns it too.
+// A read error takes precedence, as the syntax error was likely caused by the truncated input.
+func (l *Lexer) fail(err error) (Token, error) {
+	if l.err != nil {
+		err = l.err
+	}
+	l.failed = err
+	return Token{}, err
+}
+
+// advance moves past the current byte, keeping track of lines.
+func (l *Lexer) advance() {
+	if l.buf[l.pos] == '\n' {
+		l.line, l.lineStart = l.line+1, l.offset+l.pos+1
+	}
+	l.pos++
+}
+
+// position converts an index into the buffer to a Position.
+func (l *Lexer) position(index int) Position {
+	offset := l.offset + index
+	return Position{Offset: offset, Line: l.line, Column: offset - l.lineStart + 1}
+}
+
+// ensure reads until at least n bytes after the current position are buffered or the input is exhausted.
+func (l *Lexer) ensure(n int) {
+	for len(l.buf)-l.pos < n && l.fill() {
+	}
+}
+
+// fill reads more input into the buffer and reports whether any bytes were added.
+// Bytes before the current position are discarded first, so indices into the buffer
+// must be recomputed from pos after calling fill.
+func (l *Lexer) fill() bool {
+	for !l.eof {
+		if l.pos > 0 {
+			n := copy(l.buf, l.buf[l.pos:])
+			l.buf = l.buf[:n]
+			l.offset += l.pos
+			l.pos = 0
+		}
+		if len(l.buf) == cap(l.buf) {
+			grown := make([]byte, len(l.buf), 2*cap(l.buf)+chunkSize)
+			copy(grown, l.buf)
+			l.buf = grown
+		}
+
+		read := len(l.buf)
+		n, err := l.reader.Read(l.buf[read:cap(l.buf)])
+		l.buf = l.buf[:read+n]
+		if err != nil {
+			l.eof = true
+			if err != io.EOF {
+				l.err = err
+			}
+		}
+		l.limitInput()
+		if len(l.buf) > read {
+			return true
+		}
+	}
+	return false
+}
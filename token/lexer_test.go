@@ -0,0 +1,176 @@
+package token
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// TestLexerMatchesTokenizer tests that a Lexer reading one byte at a time yields the same tokens and errors as Tokenizer.
+func TestLexerMatchesTokenizer(t *testing.T) {
+	inputs := []string{
+		``,
+		`{"name": "John", "age": 30, "tags": ["a", "b"], "active": true, "spouse": null}`,
+		"{\n  \"a\": [1, -2.5e+10, false],\n  \"b\": {}\n}\n",
+		`"say \"hi\" \ud83d\ude00 caf\u00e9"`,
+		`[true,false,null]`,
+		`{}[]`,
+		`123`,
+		`{"a": 1,, "b": 2}`,
+		`{"a": "unclosed`,
+		`{"a": "bad \x escape"}`,
+		`[1, 2`,
+		`[01]`,
+		`{"a": trues}`,
+		`"` + strings.Repeat("long string ", 1000) + `"`,
+		`"` + strings.Repeat(`caf\u00e9 \ud83d\ude00 é€ \"`, 500) + `"`,
+		`["` + strings.Repeat("a", 100) + "\n\"]",
+		`"` + strings.Repeat("a", 100) + `\ud83d\u12zz"`,
+	}
+
+	for _, input := range inputs {
+		expected, expectedErr := Tokenizer([]byte(input))
+
+		lexer := NewLexer(iotest.OneByteReader(strings.NewReader(input)))
+		var result []Token
+		var err error
+		for {
+			var tk Token
+			tk, err = lexer.Next()
+			if err != nil {
+				break
+			}
+			result = append(result, tk)
+			if tk.Type == EOF {
+				break
+			}
+		}
+
+		if !reflect.DeepEqual(err, expectedErr) {
+			t.Errorf("Input %.40q: expected error %v, got %v", input, expectedErr, err)
+		}
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("Input %.40q: expected %#v\n, got %#v\n", input, expected, result)
+		}
+	}
+}
+
+// TestLexerRepeatsLastResult tests that Next keeps returning EOF, or the first error, once the input is done.
+func TestLexerRepeatsLastResult(t *testing.T) {
+	lexer := NewLexer(strings.NewReader(`1`))
+	lexer.Next()
+	for i := 0; i < 2; i++ {
+		if tk, err := lexer.Next(); err != nil || tk.Type != EOF {
+			t.Errorf("Expected EOF, got %v, %v", tk, err)
+		}
+	}
+
+	lexer = NewLexer(strings.NewReader(`[,`))
+	lexer.Next()
+	_, first := lexer.Next()
+	if _, err := lexer.Next(); first == nil || err != first {
+		t.Errorf("Expected the error %v again, got %v", first, err)
+	}
+}
+
+// TestLexerBoundedBuffer tests that the Lexer does not buffer the whole input.
+func TestLexerBoundedBuffer(t *testing.T) {
+	const elements = 200000
+	input := io.MultiReader(
+		strings.NewReader("["),
+		&repeatReader{chunk: []byte("12345, "), count: elements},
+		strings.NewReader("0]"),
+	)
+
+	lexer := NewLexer(input)
+	numbers := 0
+	for {
+		tk, err := lexer.Next()
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		if tk.Type == Number {
+			numbers++
+		}
+		if tk.Type == EOF {
+			break
+		}
+		if cap(lexer.buf) > 2*chunkSize {
+			t.Fatalf("Buffer grew to %d bytes at %v", cap(lexer.buf), tk.Start)
+		}
+	}
+	if numbers != elements+1 {
+		t.Errorf("Expected %d numbers, got %d", elements+1, numbers)
+	}
+}
+
+// TestLexerLongString tests that a long string read in small chunks is decoded in linear time,
+// without keeping its source text in the buffer.
+func TestLexerLongString(t *testing.T) {
+	const length = 1 << 20
+	input := io.MultiReader(
+		strings.NewReader(`"`),
+		&repeatReader{chunk: []byte("a\\n"), count: length / 2},
+		strings.NewReader(`"`),
+	)
+
+	lexer := NewLexer(iotest.OneByteReader(input))
+	tk, err := lexer.Next()
+	if err != nil {
+		t.Fatalf("Next() error = %v", err)
+	}
+	if len(tk.Val) != length || tk.Val[:4] != "a\na\n" {
+		t.Errorf("Expected a string of %d bytes, got %d bytes starting with %.4q", length, len(tk.Val), tk.Val)
+	}
+	if tk.End.Offset != 3*length/2+2 {
+		t.Errorf("Expected the string to end at %d, got %d", 3*length/2+2, tk.End.Offset)
+	}
+	if cap(lexer.buf) > 2*chunkSize {
+		t.Errorf("Buffer grew to %d bytes", cap(lexer.buf))
+	}
+}
+
+// TestLexerReadError tests that a read error is returned instead of a syntax error for the truncated input.
+func TestLexerReadError(t *testing.T) {
+	errRead := errors.New("connection reset")
+	lexer := NewLexer(io.MultiReader(strings.NewReader(`{"name": "Jo`), iotest.ErrReader(errRead)))
+
+	var err error
+	for err == nil {
+		_, err = lexer.Next()
+	}
+	if err != errRead {
+		t.Errorf("Expected %v, got %v", errRead, err)
+	}
+}
+
+// repeatReader is an io.Reader returning chunk count times.
+type repeatReader struct {
+	chunk []byte
+	count int
+	rest  []byte
+}
+
+// Read method for repeatReader to fill p with repetitions of the chunk.
+func (r *repeatReader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if len(r.rest) == 0 {
+			if r.count == 0 {
+				break
+			}
+			r.rest = r.chunk
+			r.count--
+		}
+		copied := copy(p[n:], r.rest)
+		r.rest = r.rest[copied:]
+		n += copied
+	}
+	if n == 0 {
+		return 0, io.EOF
+	}
+	return n, nil
+}
@@ -0,0 +1,102 @@
+package token
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Kinds of limit errors. A LimitError wraps exactly one of them, so callers can use errors.Is.
+// Example: errors.Is(err, token.ErrMaxInputBytes) to answer 413 Payload Too Large
+var (
+	ErrMaxDepth       = errors.New("maximum nesting depth exceeded")
+	ErrMaxInputBytes  = errors.New("maximum input size exceeded")
+	ErrMaxTokens      = errors.New("maximum number of tokens exceeded")
+	ErrMaxStringBytes = errors.New("maximum string length exceeded")
+	ErrMaxMembers     = errors.New("maximum number of object members exceeded")
+	ErrMaxArrayLength = errors.New("maximum array length exceeded")
+)
+
+// Limits bounds the resources spent on untrusted input; a zero field means no limit.
+// The Lexer enforces MaxDepth, MaxInputBytes, MaxTokens and MaxStringBytes.
+// The parser enforces MaxDepth, MaxMembers and MaxArrayLength, so tokens that did not come from a limited Lexer are bounded too.
+type Limits struct {
+
+	// MaxDepth is the number of objects and arrays that may be nested, e.g. 1 allows [1] but not [[1]]
+	MaxDepth int
+
+	// MaxInputBytes is the size of the input; for a Lexer that reads several values, of all of them together
+	// unless ResetLimits is called between them, as a Decoder does
+	MaxInputBytes int
+
+	// MaxTokens is the number of tokens, not counting comments or the final EOF; it counts across values like MaxInputBytes
+	MaxTokens int
+
+	// MaxStringBytes is the length of a string or key after decoding its escapes
+	MaxStringBytes int
+
+	// MaxMembers is the number of members of a single object
+	MaxMembers int
+
+	// MaxArrayLength is the number of elements of a single array
+	MaxArrayLength int
+}
+
+// LimitError describes input that exceeds one of the Limits.
+// Unlike a SyntaxError it does not mean that the input is malformed, only that it is larger than allowed.
+type LimitError struct {
+
+	// Kind is one of the ErrMax* sentinels above
+	Kind error
+
+	// Limit is the value of the exceeded limit
+	Limit int
+
+	// Pos is the position where the limit was exceeded; for MaxInputBytes only its Offset is known
+	Pos Position
+}
+
+// Error method for LimitError to get a human-readable description, e.g.
+// "3:7: maximum nesting depth exceeded (limit 2)". The position is left out if it is unknown.
+func (e *LimitError) Error() string {
+	msg := fmt.Sprintf("%s (limit %d)", e.Kind, e.Limit)
+	if e.Pos.Line > 0 {
+		msg = e.Pos.String() + ": " + msg
+	}
+	return msg
+}
+
+// Unwrap returns the kind of the error, so errors.Is(err, ErrMaxDepth) works.
+func (e *LimitError) Unwrap() error {
+	return e.Kind
+}
+
+// limitInput cuts the buffer at MaxInputBytes and records a LimitError, which is returned like a read error
+// once the tokens before the limit have been read. The cut bytes stay in the array of the buffer,
+// which is not read into again until ResetLimits restores them.
+func (l *Lexer) limitInput() {
+	max := l.options.Limits.MaxInputBytes
+	if limit := l.limitStart + max; max > 0 && l.offset+len(l.buf) > limit {
+		l.held = l.offset + len(l.buf) - limit
+		l.readEOF, l.readErr = l.eof, l.err
+		l.buf = l.buf[:limit-l.offset]
+		l.eof = true
+		l.err = &LimitError{Kind: ErrMaxInputBytes, Limit: max, Pos: Position{Offset: limit}}
+	}
+}
+
+// push opens an object or array at the current position, checking MaxDepth.
+func (l *Lexer) push(t Type) error {
+	if max := l.options.Limits.MaxDepth; max > 0 && len(l.stack.TokenTypes) >= max {
+		return &LimitError{Kind: ErrMaxDepth, Limit: max, Pos: l.position(l.pos)}
+	}
+	l.stack.Push(t)
+	return nil
+}
+
+// checkString checks the decoded length of the string starting at pos against MaxStringBytes.
+func (l *Lexer) checkString(length int, pos Position) error {
+	if max := l.options.Limits.MaxStringBytes; max > 0 && length > max {
+		return &LimitError{Kind: ErrMaxStringBytes, Limit: max, Pos: pos}
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package token
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// TestTokenizerLimits tests that each limit enforced by the Lexer is reported as a *LimitError at the right position.
+func TestTokenizerLimits(t *testing.T) {
+	testCases := []struct {
+		name    string
+		input   string
+		limits  Limits
+		options []Option
+		kind    error
+		pos     Position
+	}{
+		{name: "Depth", input: `{"a": [[1]]}`, limits: Limits{MaxDepth: 2}, kind: ErrMaxDepth, pos: Position{Offset: 7, Line: 1, Column: 8}},
+		{name: "Input size", input: `[1, 2, 3]`, limits: Limits{MaxInputBytes: 8}, kind: ErrMaxInputBytes, pos: Position{Offset: 8}},
+		{name: "Input size of a valid prefix", input: `1    `, limits: Limits{MaxInputBytes: 2}, kind: ErrMaxInputBytes, pos: Position{Offset: 2}},
+		{name: "Tokens", input: "[1,\n2, 3]", limits: Limits{MaxTokens: 4}, kind: ErrMaxTokens, pos: Position{Offset: 5, Line: 2, Column: 2}},
+		{name: "String", input: `["abc", "abcd"]`, limits: Limits{MaxStringBytes: 3}, kind: ErrMaxStringBytes, pos: Position{Offset: 8, Line: 1, Column: 9}},
+		{name: "Key", input: `{"abcd": 1}`, limits: Limits{MaxStringBytes: 3}, kind: ErrMaxStringBytes, pos: Position{Offset: 1, Line: 1, Column: 2}},
+		{name: "Decoded string", input: `"éé"`, limits: Limits{MaxStringBytes: 3}, kind: ErrMaxStringBytes, pos: Position{Offset: 0, Line: 1, Column: 1}},
+		{name: "JSON5 string", input: `['abcd']`, limits: Limits{MaxStringBytes: 3}, options: []Option{JSON5()}, kind: ErrMaxStringBytes, pos: Position{Offset: 1, Line: 1, Column: 2}},
+		{name: "JSON5 identifier", input: `{abcd: 1}`, limits: Limits{MaxStringBytes: 3}, options: []Option{JSON5()}, kind: ErrMaxStringBytes, pos: Position{Offset: 1, Line: 1, Column: 2}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Tokenizer([]byte(tc.input), append(tc.options, WithLimits(tc.limits))...)
+			var limitErr *LimitError
+			if !errors.As(err, &limitErr) || !errors.Is(err, tc.kind) {
+				t.Fatalf("Expected a *LimitError of kind %v, got %v", tc.kind, err)
+			}
+			if limitErr.Pos != tc.pos {
+				t.Errorf("Expected position %#v, got %#v", tc.pos, limitErr.Pos)
+			}
+		})
+	}
+}
+
+// TestTokenizerWithinLimits tests that input at the limits is accepted and comments are not counted as tokens.
+func TestTokenizerWithinLimits(t *testing.T) {
+	input := `{"abc": [[1, 2]]} // end`
+	limits := Limits{MaxDepth: 3, MaxInputBytes: len(input), MaxTokens: 11, MaxStringBytes: 3}
+	if _, err := Tokenizer([]byte(input), JSONC(), WithLimits(limits)); err != nil {
+		t.Errorf("Tokenizer() error = %v", err)
+	}
+}
+
+// TestLimitErrorMessage tests the message of a LimitError with and without a position.
+func TestLimitErrorMessage(t *testing.T) {
+	_, err := Tokenizer([]byte("[\n  [[]]]"), WithLimits(Limits{MaxDepth: 2}))
+	if err == nil || err.Error() != "2:4: maximum nesting depth exceeded (limit 2)" {
+		t.Errorf("Unexpected error %v", err)
+	}
+	_, err = Tokenizer([]byte("[1, 2]"), WithLimits(Limits{MaxInputBytes: 4}))
+	if err == nil || err.Error() != "maximum input size exceeded (limit 4)" {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+// TestLexerLimitsInputSize tests that a streaming Lexer stops reading at MaxInputBytes and matches Tokenizer.
+func TestLexerLimitsInputSize(t *testing.T) {
+	input := "[" + strings.Repeat(`"padding", `, 1000) + "1]"
+	limits := WithLimits(Limits{MaxInputBytes: 100})
+	expected, expectedErr := Tokenizer([]byte(input), limits)
+
+	reader := strings.NewReader(input)
+	lexer := NewLexer(iotest.OneByteReader(reader), limits)
+	var result []Token
+	var err error
+	for {
+		var tk Token
+		tk, err = lexer.Next()
+		if err != nil {
+			break
+		}
+		result = append(result, tk)
+	}
+
+	if !errors.Is(err, ErrMaxInputBytes) || !reflect.DeepEqual(err, expectedErr) {
+		t.Errorf("Expected error %v, got %v", expectedErr, err)
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %#v\n, got %#v", expected, result)
+	}
+	if read := len(input) - reader.Len(); read > 101 {
+		t.Errorf("Expected the Lexer to stop reading after the limit, read %d bytes", read)
+	}
+}
+
+// TestLexerResetLimits tests that ResetLimits counts MaxInputBytes and MaxTokens again for each value,
+// reading the input cut off at the previous limit, while a number reaching the limit is not split.
+func TestLexerResetLimits(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		limits   Limits
+		expected []string
+		kind     error
+	}{
+		{name: "Input size", input: "[1] [2,3]\n[4]", limits: Limits{MaxInputBytes: 6}, expected: []string{"[1]", "[2,3]", "[4]"}},
+		{name: "Tokens", input: "[1] [2,3] [4]", limits: Limits{MaxTokens: 5}, expected: []string{"[1]", "[2,3]", "[4]"}},
+		{name: "Input size of one value", input: "[1] [2,3,4]", limits: Limits{MaxInputBytes: 6}, expected: []string{"[1]"}, kind: ErrMaxInputBytes},
+		{name: "Tokens of one value", input: "[1] [2,3,4]", limits: Limits{MaxTokens: 5}, expected: []string{"[1]"}, kind: ErrMaxTokens},
+		{name: "Number at the limit", input: "1 23456", limits: Limits{MaxInputBytes: 4}, expected: []string{"1"}, kind: ErrMaxInputBytes},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			lexer := NewLexer(iotest.OneByteReader(strings.NewReader(tc.input)), WithLimits(tc.limits))
+			var values []string
+			var value strings.Builder
+			var err error
+			for {
+				var tk Token
+				if tk, err = lexer.Next(); err != nil || tk.Type == EOF {
+					break
+				}
+				value.WriteString(tk.Val)
+				if len(lexer.stack.TokenTypes) == 0 {
+					values = append(values, value.String())
+					value.Reset()
+					lexer.ResetLimits()
+				}
+			}
+			if !reflect.DeepEqual(values, tc.expected) {
+				t.Errorf("Expected values %q, got %q", tc.expected, values)
+			}
+			if tc.kind == nil && err != nil || tc.kind != nil && !errors.Is(err, tc.kind) {
+				t.Errorf("Expected error %v, got %v", tc.kind, err)
+			}
+		})
+	}
+}
+
+// TestLexerLimitsStringLength tests that a streaming Lexer fails on a string over MaxStringBytes
+// without reading the rest of the string.
+func TestLexerLimitsStringLength(t *testing.T) {
+	const limit = 1 << 16
+	for _, tc := range []struct {
+		name    string
+		prefix  string
+		options []Option
+	}{
+		{name: "String", prefix: `["`},
+		{name: "JSON5 string", prefix: `['`, options: []Option{JSON5()}},
+		{name: "JSON5 identifier", prefix: `{`, options: []Option{JSON5()}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// a gigabyte long string
+			chunk := &repeatReader{chunk: []byte(strings.Repeat("a", 1024)), count: 1 << 20}
+			options := append(tc.options, WithLimits(Limits{MaxStringBytes: limit}))
+			lexer := NewLexer(io.MultiReader(strings.NewReader(tc.prefix), chunk), options...)
+
+			var err error
+			for err == nil {
+				_, err = lexer.Next()
+			}
+			if !errors.Is(err, ErrMaxStringBytes) {
+				t.Fatalf("Expected %v, got %v", ErrMaxStringBytes, err)
+			}
+			if read := (1<<20 - chunk.count) * 1024; read > 2*limit {
+				t.Errorf("Expected the Lexer to stop reading after the limit, read %d bytes", read)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package token
+
+// isNumberStart checks if a byte can start a number: a minus sign or a digit.
+func isNumberStart(c byte) bool {
+	return c == '-' || isDigit(c)
+}
+
+// readNumber reads a number starting at input[index] following the RFC 8259 grammar:
+//
+//	number = [ "-" ] int [ frac ] [ exp ]
+//	int    = "0" / ( digit1-9 *DIGIT )
+//	frac   = "." 1*DIGIT
+//	exp    = ( "e" / "E" ) [ "-" / "+" ] 1*DIGIT
+//
+// It returns the index just past the last byte of the number.
+// Example: For input "-2.5E-3,", it returns 7.
+func readNumber(input []byte, index int) (int, error) {
+	current := index
+
+	// optional minus sign
+	if current < len(input) && input[current] == '-' {
+		current++
+	}
+
+	// integer part: a single zero or a non-zero digit followed by digits
+	switch {
+	case current < len(input) && input[current] == '0':
+		current++
+	case current < len(input) && isDigit(input[current]):
+		current = skipDigits(input, current)
+	default:
+		return current, ErrInvalidNumber
+	}
+
+	// optional fraction: '.' followed by at least one digit
+	if current < len(input) && input[current] == '.' {
+		current++
+		if current >= len(input) || !isDigit(input[current]) {
+			return current, ErrInvalidNumber
+		}
+		current = skipDigits(input, current)
+	}
+
+	// optional exponent: 'e' or 'E', an optional sign and at least one digit
+	if current < len(input) && (input[current] == 'e' || input[current] == 'E') {
+		current++
+		if current < len(input) && (input[current] == '+' || input[current] == '-') {
+			current++
+		}
+		if current >= len(input) || !isDigit(input[current]) {
+			return current, ErrInvalidNumber
+		}
+		current = skipDigits(input, current)
+	}
+
+	return current, nil
+}
+
+// skipDigits returns the index of the first non-digit byte at or after index.
+func skipDigits(input []byte, index int) int {
+	for index < len(input) && isDigit(input[index]) {
+		index++
+	}
+	return index
+}
+
+// IsNumber checks if s is exactly one number following the JSON number grammar.
+// Example: IsNumber("1e10") returns true, IsNumber("01") returns false.
+func IsNumber(s string) bool {
+	end, err := readNumber([]byte(s), 0)
+	return err == nil && end == len(s)
+}
@@ -0,0 +1,125 @@
+package token
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// TestIsNumber tests the IsNumber function against the JSON number grammar.
+func TestIsNumber(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected bool
+	}{
+		{input: "0", expected: true},
+		{input: "-0", expected: true},
+		{input: "7", expected: true},
+		{input: "123", expected: true},
+		{input: "-5", expected: true},
+		{input: "3.14", expected: true},
+		{input: "-0.5", expected: true},
+		{input: "1e10", expected: true},
+		{input: "1E10", expected: true},
+		{input: "2.5E-3", expected: true},
+		{input: "2.5e+3", expected: true},
+		{input: "0e0", expected: true},
+		{input: "", expected: false},
+		{input: "-", expected: false},
+		{input: "01", expected: false},
+		{input: "-01", expected: false},
+		{input: "00", expected: false},
+		{input: "1.", expected: false},
+		{input: ".5", expected: false},
+		{input: "1.e5", expected: false},
+		{input: "1e", expected: false},
+		{input: "1e+", expected: false},
+		{input: "1e-", expected: false},
+		{input: "+1", expected: false},
+		{input: "--1", expected: false},
+		{input: "1.2.3", expected: false},
+		{input: "0x10", expected: false},
+		{input: "Infinity", expected: false},
+		{input: "1_000", expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			if result := IsNumber(tc.input); result != tc.expected {
+				t.Errorf("IsNumber(%q) = %v, expected %v", tc.input, result, tc.expected)
+			}
+		})
+	}
+}
+
+// TestTokenizerNumbers tests how the Tokenizer splits numbers from the surrounding input.
+func TestTokenizerNumbers(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected []Token
+		err      error
+	}{
+		{
+			name:     "Negative fraction with exponent",
+			input:    `-2.5E-3`,
+			expected: []Token{{Type: Number, Val: "-2.5E-3"}, {Type: EOF, Val: ""}},
+		},
+		{
+			name:  "Numbers in array",
+			input: `[-5,3.14,1e10]`,
+			expected: []Token{
+				{Type: LeftBracket, Val: "["},
+				{Type: Number, Val: "-5"},
+				{Type: Comma, Val: ","},
+				{Type: Number, Val: "3.14"},
+				{Type: Comma, Val: ","},
+				{Type: Number, Val: "1e10"},
+				{Type: RightBracket, Val: "]"},
+				{Type: EOF, Val: ""},
+			},
+		},
+		{
+			name:     "Leading zero",
+			input:    `[01]`,
+			expected: []Token{{Type: LeftBracket, Val: "["}},
+			err:      ErrInvalidNumber,
+		},
+		{
+			name:     "Missing fraction digits",
+			input:    `[1.]`,
+			expected: []Token{{Type: LeftBracket, Val: "["}},
+			err:      ErrInvalidNumber,
+		},
+		{
+			name:     "Missing exponent digits",
+			input:    `1e+`,
+			expected: []Token{},
+			err:      ErrInvalidNumber,
+		},
+		{
+			name:     "Lonely minus",
+			input:    `-`,
+			expected: []Token{},
+			err:      ErrInvalidNumber,
+		},
+		{
+			name:     "Leading plus",
+			input:    `+1`,
+			expected: []Token{},
+			err:      ErrInvalidCharacter,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := Tokenizer([]byte(tc.input))
+			if !errors.Is(err, tc.err) {
+				t.Errorf("Test %s failed. Expected error %v, got %v", tc.name, tc.err, err)
+			}
+			if result = withoutPositions(result); !reflect.DeepEqual(result, tc.expected) {
+				t.Errorf("Test %s failed. Expected %#v\n, got %#v'\n", tc.name, tc.expected, result)
+			}
+		})
+	}
+}
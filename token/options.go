@@ -0,0 +1,80 @@
+package token
+
+// Options configures the Lexer and Tokenizer.
+type Options struct {
+
+	// JSON5 accepts the extensions of JSON5 (https://spec.json5.org) on top of strict JSON
+	JSON5 bool
+
+	// JSONC accepts comments and returns them as Comment tokens
+	JSONC bool
+
+	// AnyEncoding detects UTF-16 and UTF-32 input and converts it to UTF-8
+	AnyEncoding bool
+
+	// ReplaceInvalidUTF8 replaces invalid UTF-8 in strings with U+FFFD instead of failing
+	ReplaceInvalidUTF8 bool
+
+	// Limits bounds the resources spent on the input
+	Limits Limits
+}
+
+// Option sets a field of Options; pass options to NewLexer or Tokenizer.
+// Example: Tokenizer(input, JSON5())
+type Option func(*Options)
+
+// JSON5 enables the relaxed syntax of JSON5 for files edited by humans:
+// comments, trailing commas, unquoted identifier keys, single-quoted and multi-line strings,
+// hexadecimal numbers, numbers with a leading '+' or a leading or trailing decimal point,
+// Infinity and NaN, and additional Unicode whitespace. Strict JSON is the default.
+func JSON5() Option {
+	return func(o *Options) {
+		o.JSON5 = true
+	}
+}
+
+// JSONC enables JSON with comments, as used by VS Code settings files: "//" line comments and "/* */"
+// block comments are accepted wherever whitespace is and returned as Comment tokens, so tools can keep them.
+// The rest of the syntax is strict JSON; combined with JSON5, comments are returned rather than skipped.
+// Example: Tokenizer([]byte("{\"tabSize\": 4 // spaces\n}"), JSONC())
+func JSONC() Option {
+	return func(o *Options) {
+		o.JSONC = true
+	}
+}
+
+// AnyEncoding accepts JSON text encoded in UTF-16 or UTF-32, big or little endian, with or without a byte order mark,
+// as well as UTF-8. The encoding is detected as DetectEncoding does, and the input converted to UTF-8 before it is tokenized,
+// so token positions count the bytes of the UTF-8 text. Lexer.Encoding reports the detected encoding.
+// Example: NewLexer(file, AnyEncoding())
+func AnyEncoding() Option {
+	return func(o *Options) {
+		o.AnyEncoding = true
+	}
+}
+
+// ReplaceInvalidUTF8 replaces each byte of invalid UTF-8 in a string with U+FFFD, the replacement character,
+// instead of reporting ErrInvalidUTF8. Use it for input from sources known to mangle encodings.
+// Example: Tokenizer(input, ReplaceInvalidUTF8())
+func ReplaceInvalidUTF8() Option {
+	return func(o *Options) {
+		o.ReplaceInvalidUTF8 = true
+	}
+}
+
+// WithLimits bounds the resources spent on untrusted input; exceeding a limit returns a *LimitError.
+// Example: Tokenizer(body, WithLimits(Limits{MaxDepth: 64, MaxInputBytes: 1 << 20}))
+func WithLimits(limits Limits) Option {
+	return func(o *Options) {
+		o.Limits = limits
+	}
+}
+
+// newOptions applies opts to the default Options.
+func newOptions(opts []Option) Options {
+	var options Options
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
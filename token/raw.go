@@ -0,0 +1,72 @@
+package token
+
+// RawToken is a token without its value: its Type and the byte offsets of its source text in the input.
+// Unlike a Token it holds no string, so tokenizing into RawTokens allocates nothing per token;
+// Val decodes the value only when it is needed.
+// Example: in `{"a": 1}`, the key is {Type: String, Start: 1, End: 4}, including its quotes.
+type RawToken struct {
+
+	// Type of the token; string tokens have the type String, as in Token
+	Type Type
+
+	// Start is the offset of the first byte of the token
+	Start int
+
+	// End is the offset just past the last byte of the token
+	End int
+}
+
+// Bytes returns the source text of the token, e.g. `"a\n"` with its quotes and escapes, without copying it.
+func (t RawToken) Bytes(input []byte) []byte {
+	return input[t.Start:t.End]
+}
+
+// Val returns the value of the token, as Token.Val holds it: strings and keys are decoded, and other tokens
+// are their source text. input must be the input the token was read from; it allocates only the returned string.
+// Example: for the key of `{"a\n": 1}`, Val(input) returns "a\n".
+func (t RawToken) Val(input []byte) string {
+	if t.Type != String {
+		return string(t.Bytes(input))
+	}
+
+	// the token was validated by the Lexer, so decoding cannot fail; invalid UTF-8 was either rejected or is replaced
+	var value []byte
+	if c := input[t.Start]; c == '"' || c == '\'' {
+		value, _, _ = continueJSON5String(nil, input, t.Start+1, c, true, false)
+	} else {
+		value, _, _ = readIdentifier(nil, input, t.Start)
+	}
+	return string(value)
+}
+
+// RawTokenizer tokenizes the input like Tokenizer, with the same options and errors, into RawTokens.
+// Use it when most values are skipped or compared as bytes, e.g. to route or validate documents.
+// It checks the same grammar as Tokenizer and so rejects malformed documents,
+// but like Tokenizer it accepts several top-level values in a row, as in "{}[]".
+// With AnyEncoding, the offsets refer to the UTF-8 text returned by Transcode rather than to the input.
+// Example:
+//
+//	tokens, err := RawTokenizer(input)
+//	name := tokens[1].Val(input)
+func RawTokenizer(input []byte, opts ...Option) ([]RawToken, error) {
+	return AppendRawTokens(nil, input, opts...)
+}
+
+// AppendRawTokens appends the RawTokens of the input to dst and returns the extended slice, like RawTokenizer.
+// Reusing dst across documents avoids allocating the slice, so tokenizing allocates only the Lexer's
+// fixed state and scratch space.
+// Example: tokens, err = AppendRawTokens(tokens[:0], input)
+func AppendRawTokens(dst []RawToken, input []byte, opts ...Option) ([]RawToken, error) {
+	lexer := newBytesLexer(input, opts)
+	lexer.raw = true
+	for {
+		tk, err := lexer.Next()
+		if err != nil {
+			return dst, err
+		}
+		dst = append(dst, RawToken{Type: tk.Type, Start: tk.Start.Offset, End: tk.End.Offset})
+		if tk.Type == EOF {
+			return dst, nil
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package token
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// benchmarkInput is a document with the usual mix of keys, strings, numbers and literals.
+var benchmarkInput = []byte(`{"users": [` + strings.Repeat(
+	`{"id": 12345, "name": "John \"JD\" Doe", "email": "john@example.com", "active": true, "score": -1.5e3, "tags": ["a", "b"], "manager": null}, `,
+	99) + `{"id": 0}]}`)
+
+// TestRawTokenizer tests that RawTokens have the types and offsets of the Tokenizer's tokens and that Val returns their values.
+func TestRawTokenizer(t *testing.T) {
+	testCases := []struct {
+		name    string
+		input   string
+		options []Option
+	}{
+		{name: "JSON", input: `{"a\n\u00e9": [1.5e3, true, false, null, "x\ud83d\ude00"]}`},
+		{name: "Multi-line", input: "[\n  \"a\",\n  -0\n]"},
+		{name: "JSON5", input: "{$key: 'it\\'s', \"\\x41\": +.5, b: Infinity, c: 0x1F,} // end", options: []Option{JSON5()}},
+		{name: "Escaped identifier", input: `{\u0061b: 1}`, options: []Option{JSON5()}},
+		{name: "JSONC", input: "/* a */ [1, // b\n 2]", options: []Option{JSONC()}},
+		{name: "Replaced invalid UTF-8", input: "[\"a\xffb\"]", options: []Option{ReplaceInvalidUTF8()}},
+		{name: "Byte order mark", input: "\uFEFF[1]"},
+		{name: "Empty", input: ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			input := []byte(tc.input)
+			expected, err := Tokenizer(input, tc.options...)
+			if err != nil {
+				t.Fatalf("Tokenizer() error = %v", err)
+			}
+			result, err := RawTokenizer(input, tc.options...)
+			if err != nil {
+				t.Fatalf("RawTokenizer() error = %v", err)
+			}
+			if len(result) != len(expected) {
+				t.Fatalf("Expected %d tokens, got %d", len(expected), len(result))
+			}
+			for i, tk := range result {
+				want := RawToken{Type: expected[i].Type, Start: expected[i].Start.Offset, End: expected[i].End.Offset}
+				if tk != want {
+					t.Errorf("Token %d: expected %#v, got %#v", i, want, tk)
+				}
+				if val := tk.Val(input); val != expected[i].Val {
+					t.Errorf("Token %d: expected value %q, got %q", i, expected[i].Val, val)
+				}
+			}
+		})
+	}
+}
+
+// TestRawTokenBytes tests that Bytes returns the source text of a token, including quotes and escapes.
+func TestRawTokenBytes(t *testing.T) {
+	input := []byte(`{"a\"b": 10}`)
+	tokens, err := RawTokenizer(input)
+	if err != nil {
+		t.Fatalf("RawTokenizer() error = %v", err)
+	}
+	if got := string(tokens[1].Bytes(input)); got != `"a\"b"` {
+		t.Errorf("Expected %q, got %q", `"a\"b"`, got)
+	}
+	if got := string(tokens[3].Bytes(input)); got != "10" {
+		t.Errorf("Expected %q, got %q", "10", got)
+	}
+}
+
+// TestRawTokenizerErrors tests that RawTokenizer returns the errors of Tokenizer along with the tokens read before them.
+func TestRawTokenizerErrors(t *testing.T) {
+	testCases := []struct {
+		name    string
+		input   string
+		options []Option
+	}{
+		{name: "Syntax error", input: `{"a": tru}`},
+		{name: "Unclosed string", input: `["abc`},
+		{name: "Mismatched closer", input: `[1}]`},
+		{name: "Missing value", input: `{"a"}`},
+		{name: "Invalid UTF-8", input: "[\"a\xffb\"]"},
+		{name: "Limit", input: `[[1]]`, options: []Option{WithLimits(Limits{MaxDepth: 1})}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			expected, expectedErr := Tokenizer([]byte(tc.input), tc.options...)
+			result, err := RawTokenizer([]byte(tc.input), tc.options...)
+			if err == nil || !reflect.DeepEqual(err, expectedErr) {
+				t.Errorf("Expected error %v, got %v", expectedErr, err)
+			}
+			if len(result) != len(expected) {
+				t.Errorf("Expected %d tokens before the error, got %d", len(expected), len(result))
+			}
+		})
+	}
+}
+
+// TestAppendRawTokensAllocations tests that tokenizing into a reused slice allocates a small constant amount, whatever the input size.
+func TestAppendRawTokensAllocations(t *testing.T) {
+	tokens, err := AppendRawTokens(nil, benchmarkInput)
+	if err != nil {
+		t.Fatalf("AppendRawTokens() error = %v", err)
+	}
+	allocs := testing.AllocsPerRun(100, func() {
+		tokens, err = AppendRawTokens(tokens[:0], benchmarkInput)
+	})
+	if err != nil {
+		t.Fatalf("AppendRawTokens() error = %v", err)
+	}
+	if allocs > 8 {
+		t.Errorf("Expected at most 8 allocations for %d tokens, got %v", len(tokens), allocs)
+	}
+}
+
+// TestRawTokenValDoesNotAllocate tests that Val allocates nothing for tokens whose value is a single byte.
+func TestRawTokenValDoesNotAllocate(t *testing.T) {
+	input := []byte(`{"a": 1}`)
+	tokens, err := RawTokenizer(input)
+	if err != nil {
+		t.Fatalf("RawTokenizer() error = %v", err)
+	}
+	allocs := testing.AllocsPerRun(100, func() {
+		for _, tk := range tokens {
+			if tk.Type != String {
+				tk.Val(input)
+			}
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("Expected no allocations, got %v", allocs)
+	}
+}
+
+// BenchmarkTokenizer measures Tokenizer, which allocates a string for every token, as a baseline for RawTokenizer.
+func BenchmarkTokenizer(b *testing.B) {
+	b.ReportAllocs()
+	b.SetBytes(int64(len(benchmarkInput)))
+	for i := 0; i < b.N; i++ {
+		if _, err := Tokenizer(benchmarkInput); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkRawTokenizer measures RawTokenizer, which allocates only the growing slice of tokens.
+func BenchmarkRawTokenizer(b *testing.B) {
+	b.ReportAllocs()
+	b.SetBytes(int64(len(benchmarkInput)))
+	for i := 0; i < b.N; i++ {
+		if _, err := RawTokenizer(benchmarkInput); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkAppendRawTokens measures AppendRawTokens with a reused slice, the allocation-free way to tokenize many documents.
+func BenchmarkAppendRawTokens(b *testing.B) {
+	b.ReportAllocs()
+	b.SetBytes(int64(len(benchmarkInput)))
+	var tokens []RawToken
+	var err error
+	for i := 0; i < b.N; i++ {
+		if tokens, err = AppendRawTokens(tokens[:0], benchmarkInput); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkRawTokenVal measures decoding the values of all tokens on demand.
+func BenchmarkRawTokenVal(b *testing.B) {
+	tokens, err := RawTokenizer(benchmarkInput)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.SetBytes(int64(len(benchmarkInput)))
+	for i := 0; i < b.N; i++ {
+		for _, tk := range tokens {
+			tk.Val(benchmarkInput)
+		}
+	}
+}
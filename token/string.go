@@ -0,0 +1,186 @@
+package token
+
+import (
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// continueString decodes a string literal from input[current], which is just after its opening quote,
+// and appends its decoded value to dst, so a buffer can be reused across strings.
+// Escape sequences are decoded, including \uXXXX escapes and UTF-16 surrogate pairs.
+// Invalid UTF-8 is an error, or replaced with U+FFFD if replace is set.
+// It returns the extended dst and the index of the closing quote,
+// or one of the Err* kinds and the index of the offending byte.
+// If partial is set, more input may follow, so it stops before an escape sequence or character that may be cut off
+// at the end of input; it then returns the value so far, the index to continue from and ErrUnclosedString.
+// This lets a Lexer decode a long string across reads without decoding the start again.
+// Example: For input `"say \"hi\""` and current 1, it appends `say "hi"`.
+func continueString(dst, input []byte, current int, replace, partial bool) ([]byte, int, error) {
+	start := current
+
+	// fast path: no escapes and valid UTF-8, the value is a plain slice of the input
+	for current < len(input) && input[current] != '"' && input[current] != '\\' {
+		if input[current] < 0x20 {
+			return dst, current, ErrControlCharacter
+		}
+		if input[current] < utf8.RuneSelf {
+			current++
+			continue
+		}
+		size, err := checkRune(input, current)
+		if err == ErrInvalidUTF8 && replace || err == ErrUnclosedString {
+			break
+		} else if err != nil {
+			return dst, current, err
+		}
+		current += size
+	}
+	value := append(dst, input[start:current]...)
+	if current < len(input) && input[current] == '"' {
+		return value, current, nil
+	}
+
+	for current < len(input) {
+		char := input[current]
+		switch {
+		case char == '"':
+			return value, current, nil
+		case char < 0x20:
+			return value, current, ErrControlCharacter
+		case char >= utf8.RuneSelf:
+			next, size, err := appendRune(value, input, current, replace)
+			if err != nil {
+				return value, current, err
+			}
+			value, current = next, current+size
+			continue
+		case char != '\\':
+			value = append(value, char)
+			current++
+			continue
+		case partial && len(input)-current < escapeLookahead:
+			return value, current, ErrUnclosedString
+		}
+
+		// escape sequence: '\' followed by one of "\/bfnrtu
+		current++
+		if current >= len(input) {
+			return value, current - 1, ErrUnclosedString
+		}
+		switch input[current] {
+		case '"', '\\', '/':
+			value = append(value, input[current])
+		case 'b':
+			value = append(value, '\b')
+		case 'f':
+			value = append(value, '\f')
+		case 'n':
+			value = append(value, '\n')
+		case 'r':
+			value = append(value, '\r')
+		case 't':
+			value = append(value, '\t')
+		case 'u':
+			r, end, ok := readUnicodeEscape(input, current)
+			if !ok {
+				return value, current, ErrInvalidUnicode
+			}
+			value = utf8.AppendRune(value, r)
+			current = end
+		default:
+			return value, current, ErrInvalidEscape
+		}
+		current++
+	}
+
+	return value, current, ErrUnclosedString
+}
+
+// checkRune checks the UTF-8 encoded character at input[index] and returns its size.
+// It returns ErrUnclosedString if the input ends within the character, and ErrInvalidUTF8 if it is not valid UTF-8,
+// including encoded surrogates and overlong encodings.
+func checkRune(input []byte, index int) (int, error) {
+	if !utf8.FullRune(input[index:]) {
+		return 0, ErrUnclosedString
+	}
+	r, size := utf8.DecodeRune(input[index:])
+	if r == utf8.RuneError && size == 1 {
+		return 0, ErrInvalidUTF8
+	}
+	return size, nil
+}
+
+// appendRune appends the UTF-8 encoded character at input[index] to dst and also returns its size.
+// An invalid byte is an error, or appended as U+FFFD if replace is set.
+func appendRune(dst, input []byte, index int, replace bool) ([]byte, int, error) {
+	size, err := checkRune(input, index)
+	if err == ErrInvalidUTF8 && replace {
+		return utf8.AppendRune(dst, utf8.RuneError), 1, nil
+	} else if err != nil {
+		return dst, 0, err
+	}
+	return append(dst, input[index:index+size]...), size, nil
+}
+
+// readUnicodeEscape decodes the \uXXXX escape whose 'u' is at input[index], combining a UTF-16 surrogate pair
+// into one code point. Lone surrogates decode to U+FFFD. It returns the index of the last byte of the escape.
+// Example: For input `u00e9`, it returns 'é' and 4.
+func readUnicodeEscape(input []byte, index int) (rune, int, bool) {
+	r, ok := readHex4(input, index+1)
+	if !ok {
+		return 0, index, false
+	}
+	end := index + 4
+
+	// a high surrogate must be followed by a low surrogate to form one code point
+	if utf16.IsSurrogate(r) {
+		r2, ok := rune(-1), false
+		if end+2 < len(input) && input[end+1] == '\\' && input[end+2] == 'u' {
+			r2, ok = readHex4(input, end+3)
+		}
+		if combined := utf16.DecodeRune(r, r2); ok && combined != utf8.RuneError {
+			return combined, end + 6, true
+		}
+		return utf8.RuneError, end, true
+	}
+	return r, end, true
+}
+
+// readHex4 reads four hexadecimal digits starting at index.
+// Example: For input "00e9", it returns 'é'.
+func readHex4(input []byte, index int) (rune, bool) {
+	if index+4 > len(input) {
+		return 0, false
+	}
+	var r rune
+	for _, c := range input[index : index+4] {
+		switch {
+		case c >= '0' && c <= '9':
+			c -= '0'
+		case c >= 'a' && c <= 'f':
+			c = c - 'a' + 10
+		case c >= 'A' && c <= 'F':
+			c = c - 'A' + 10
+		default:
+			return 0, false
+		}
+		r = r<<4 | rune(c)
+	}
+	return r, true
+}
+
+// stringErrorBytes returns the offending bytes of a string literal error at index.
+// Example: For an invalid escape it returns `\x`, for a bad unicode escape `\u12zz`.
+func stringErrorBytes(input []byte, index int) string {
+	if input[index] < 0x20 || input[index] >= utf8.RuneSelf {
+		return string(input[index : index+1])
+	}
+	end := index + 1
+	if input[index] == 'u' {
+		end = index + 5
+		if end > len(input) {
+			end = len(input)
+		}
+	}
+	return string(input[index-1 : end])
+}
@@ -0,0 +1,91 @@
+package token
+
+import (
+	"testing"
+)
+
+// TestContinueString tests the continueString function for escape sequences and invalid strings.
+func TestContinueString(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected string
+		end      int
+		err      error
+	}{
+		{name: "Plain string", input: `"hello"`, expected: "hello", end: 6},
+		{name: "Empty string", input: `""`, expected: "", end: 1},
+		{name: "Escaped quotes", input: `"say \"hi\""`, expected: `say "hi"`, end: 11},
+		{name: "Escaped backslash and solidus", input: `"a\\b\/c"`, expected: `a\b/c`, end: 8},
+		{name: "Control escapes", input: `"\b\f\n\r\t"`, expected: "\b\f\n\r\t", end: 11},
+		{name: "Unicode escape", input: `"caf\u00e9"`, expected: "café", end: 10},
+		{name: "Unicode escape upper case hex", input: `"\u00E9"`, expected: "é", end: 7},
+		{name: "Surrogate pair", input: `"\ud83d\ude00"`, expected: "😀", end: 13},
+		{name: "Lone high surrogate", input: `"\ud83dx"`, expected: "\uFFFDx", end: 8},
+		{name: "Lone low surrogate", input: `"\ude00"`, expected: "\uFFFD", end: 7},
+		{name: "High surrogate followed by non-surrogate escape", input: `"\ud83d\u0041"`, expected: "\uFFFDA", end: 13},
+		{name: "Raw UTF-8", input: `"héllo"`, expected: "héllo", end: 7},
+		{name: "Stops at closing quote", input: `"a", "b"`, expected: "a", end: 2},
+		{name: "Unclosed string", input: `"abc`, err: ErrUnclosedString},
+		{name: "Unclosed after escape", input: `"abc\"`, err: ErrUnclosedString},
+		{name: "Dangling backslash", input: `"abc\`, err: ErrUnclosedString},
+		{name: "Invalid escape", input: `"\x"`, err: ErrInvalidEscape},
+		{name: "Short unicode escape", input: `"\u12"`, err: ErrInvalidUnicode},
+		{name: "Invalid unicode hex", input: `"\u12zz"`, err: ErrInvalidUnicode},
+		{name: "Raw newline", input: "\"a\nb\"", err: ErrControlCharacter},
+		{name: "Raw tab after escape", input: "\"\\n\t\"", err: ErrControlCharacter},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			value, end, err := continueString(nil, []byte(tc.input), 1, false, false)
+			if err != tc.err {
+				t.Fatalf("Test %s failed. Expected error %v, got %v", tc.name, tc.err, err)
+			}
+			if err != nil {
+				return
+			}
+			if string(value) != tc.expected || end != tc.end {
+				t.Errorf("Test %s failed. Expected (%q, %d), got (%q, %d)", tc.name, tc.expected, tc.end, value, end)
+			}
+		})
+	}
+}
+
+// TestContinueStringUTF8 tests that invalid UTF-8 in strings is rejected, or replaced with U+FFFD when asked to.
+func TestContinueStringUTF8(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		replace  bool
+		expected string
+		end      int
+		err      error
+	}{
+		{name: "Valid multi-byte characters", input: "\"é€😀\"", expected: "é€😀", end: 10},
+		{name: "Invalid byte", input: "\"a\xffb\"", err: ErrInvalidUTF8, end: 2},
+		{name: "Truncated sequence", input: "\"a\xe2\x82b\"", err: ErrInvalidUTF8, end: 2},
+		{name: "Encoded surrogate", input: "\"\xed\xa0\x80\"", err: ErrInvalidUTF8, end: 1},
+		{name: "Overlong encoding", input: "\"\xc0\xaf\"", err: ErrInvalidUTF8, end: 1},
+		{name: "Invalid byte after escape", input: "\"\\n\xff\"", err: ErrInvalidUTF8, end: 3},
+		{name: "Input ends within a character", input: "\"a\xe2\x82", err: ErrUnclosedString},
+		{name: "Replace invalid byte", input: "\"a\xffb\"", replace: true, expected: "a\uFFFDb", end: 4},
+		{name: "Replace each byte of a truncated sequence", input: "\"\xe2\x82é\"", replace: true, expected: "\uFFFD\uFFFDé", end: 5},
+		{name: "Replace after escape", input: "\"\\t\xc0\xaf\"", replace: true, expected: "\t\uFFFD\uFFFD", end: 5},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			value, end, err := continueString(nil, []byte(tc.input), 1, tc.replace, false)
+			if err != tc.err {
+				t.Fatalf("Expected error %v, got %v", tc.err, err)
+			}
+			if (err == nil || tc.end > 0) && end != tc.end {
+				t.Errorf("Expected end %d, got %d", tc.end, end)
+			}
+			if err == nil && string(value) != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, value)
+			}
+		})
+	}
+}
@@ -3,7 +3,6 @@ package token
 import (
 	"bytes"
 	"fmt"
-	"unicode"
 )
 
 //Overview of the Code Structure
@@ -39,6 +38,13 @@ const (
 	Number  Type = "NUMBER"
 	Boolean Type = "BOOLEAN"
 	Null    Type = "NULL"
+
+	// Comment is a "//" or "/* */" comment, produced in JSONC mode and skipped by the parser
+	Comment Type = "COMMENT"
+
+	// Composite types in JSON, produced by the parser
+	Object Type = "OBJECT"
+	Array  Type = "ARRAY"
 )
 
 // String method for Type to get the string representation of the Type.
@@ -46,6 +52,24 @@ func (t Type) String() string {
 	return string(t)
 }
 
+// Position describes a location in the input.
+type Position struct {
+
+	// Offset is the byte offset, starting at 0
+	Offset int
+
+	// Line is the line number, starting at 1
+	Line int
+
+	// Column is the byte offset within the line, starting at 1
+	Column int
+}
+
+// String method for Position to get the "line:column" representation of the Position.
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
 // Token represents a single token with its Type and value.
 type Token struct {
 
@@ -54,164 +78,49 @@ type Token struct {
 
 	// The actual value of the token as a string
 	Val string
-}
 
-// Tokenizer takes a string input and tokenizes it into a slice of Token.
-// The tokens are used by the parser to build the AST
-// The tokenizer is a simple state machine that iterates over the input string and returns a slice of tokens
-// lexer is another name for tokenizer
+	// Start is the position of the first byte of the token
+	Start Position
 
-// Tokenizer It is a simple state machine iterating over the input and categorizing characters into tokens.
+	// End is the position just past the last byte of the token
+	End Position
+}
+
+// Tokenizer takes the input and tokenizes it into a slice of Token, ending with an EOF token.
+// The tokens are used by the parser to build the AST.
+// It collects the tokens of a Lexer, which checks the grammar as it goes: keys, colons, values and commas must
+// follow each other as in JSON, and each '}' or ']' must close the innermost object or array.
+// Several top-level values may follow one another, as in "{}[]", so that a stream can hold a sequence of values.
+// On malformed input it returns the tokens read so far and a *SyntaxError, and a *LimitError on input exceeding the Limits.
+// Options such as JSON5 relax the accepted syntax, and JSONC adds the comments to the tokens.
+// Use a Lexer instead to read tokens one at a time from an io.Reader, and RawTokenizer to skip allocating their values.
 // Example Input: `{"name": "John"}`
 // Example Output: [{Type: LeftBrace, Val: "{"}, {Type: String, Val: "name"}, ...]
-func Tokenizer(input []byte) []Token {
-	current := 0
+func Tokenizer(input []byte, opts ...Option) ([]Token, error) {
+	lexer := newBytesLexer(input, opts)
 	var tokens []Token
-	stack := NewStack()
-	var prevTokenType = ILLEGAL
-	for current < len(input) {
-		char := input[current]
-
-		// Determine token type based on the current character
-		currentTokenType := determineTokenType(char, input, current)
-
-		// Skip whitespace
-		if unicode.IsSpace(rune(char)) {
-			current++
-			continue
-		}
-
-		// Handle illegal token sequences
-		if !isValidSequences(prevTokenType, currentTokenType) {
-			errorToken := Token{
-				Type: ILLEGAL,
-				Val:  fmt.Sprintf("Invalid token sequence"),
-			}
-			tokens = append(tokens, errorToken)
-			return tokens
+	for {
+		tk, err := lexer.Next()
+		if err != nil {
+			return tokens, err
 		}
-
-		// Switch based on the current character to determine token type
-		switch currentTokenType {
-
-		// Example case: '{' is tokenized as {Type: LeftBrace, Val: "{"}
-		case LeftBrace:
-			stack.Push(LeftBrace)
-			tokens = append(tokens, Token{Type: LeftBrace, Val: string(char)})
-		// Example case: '}' is tokenized as {Type: RightBrace, Val: "}"}
-		case RightBrace:
-			if stack.Peek() == LeftBrace {
-				stack.Pop()
-			}
-			tokens = append(tokens, Token{Type: RightBrace, Val: string(char)})
-		// Example case: '[' is tokenized as {Type: LeftBracket, Val: "["}
-		case LeftBracket:
-			stack.Push(LeftBracket)
-			tokens = append(tokens, Token{Type: LeftBracket, Val: string(char)})
-		// Example case: ']' is tokenized as {Type: RightBracket, Val: "]"}
-		case RightBracket:
-			if stack.Peek() == LeftBracket {
-				stack.Pop()
-			}
-			tokens = append(tokens, Token{Type: RightBracket, Val: string(char)})
-		// Example case: ',' is tokenized as {Type: Comma, Val: ","}
-		case Comma:
-			tokens = append(tokens, Token{Type: Comma, Val: string(char)})
-		// Example case: ':' is tokenized as {Type: Colon, Val: ":"}
-		case Colon:
-			tokens = append(tokens, Token{Type: Colon, Val: string(char)})
-		// Example case: '"' is tokenized as {Type: LeftQuote, Val: '"'}
-		case Quote:
-			current++ // skip opening quote: '"'
-			start := current
-
-			// iterate until we find the closing quote: '"'
-			for current < len(input) && input[current] != '"' {
-				current++
-			}
-
-			// check quote is closed
-			if current < len(input) {
-				value := input[start:current]
-				tokens = append(tokens, Token{Type: String, Val: string(value)})
-			} else {
-				tokens = append(tokens, Token{Type: ILLEGAL, Val: "Unclosed string literal"})
-				return tokens
-			}
-		default:
-			if unicode.IsDigit(rune(char)) {
-				start := current
-
-				// iterate until we find the closing quote: '"'
-				for current < len(input) && isDigit(input[current]) {
-					current++
-				}
-
-				// example not valid digit:
-				if current != len(input) && !isTerminatingCharacter(input[current]) {
-					tokens = append(tokens, Token{Type: ILLEGAL, Val: "Invalid number format"})
-					return tokens
-				} else {
-					prevTokenType = Number
-					value := input[start:current]
-					tokens = append(tokens, Token{Type: Number, Val: string(value)})
-				}
-				continue
-			} else if char == 't' || char == 'f' {
-				if isBoolean(input, current) {
-					var length int
-					if bytes.Equal(input[current:current+4], []byte("true")) {
-						length = 4
-					} else {
-						length = 5
-					}
-
-					prevTokenType = Boolean
-					value := input[current : current+length] // true or false
-					tokens = append(tokens, Token{Type: Boolean, Val: string(value)})
-
-					current += length
-					continue
-
-				} else {
-					tokens = append(tokens, Token{Type: ILLEGAL, Val: "Invalid boolean literal"})
-					return tokens
-				}
-			} else if char == 'n' {
-				if isNull(input, current) {
-					if bytes.Equal(input[current:current+4], []byte("null")) {
-						value := input[current : current+4] // null
-
-						tokens = append(tokens, Token{Type: Null, Val: string(value)})
-						current += 4
-						prevTokenType = Null
-						continue
-					}
-				} else {
-					current++
-					prevTokenType = Null
-					continue
-				}
-			}
+		tokens = append(tokens, tk)
+		if tk.Type == EOF {
+			return tokens, nil
 		}
-		prevTokenType = currentTokenType
-		current++
-	}
-
-	if len(stack.TokenTypes) > 0 {
-		tokens = append(tokens, Token{Type: ILLEGAL, Val: "Unclosed token"})
-		return tokens
 	}
-
-	tokens = append(tokens, Token{Type: EOF, Val: ""})
-
-	return tokens
 }
 
-// isTerminatingCharacter checks if a character is a valid terminating character for a number.
-// Valid terminating characters are ',', '}', ']', and EOF.
+// isTerminatingCharacter checks if a character is a valid terminating character for a number or literal.
+// Valid terminating characters are ',', '}', ']' and whitespace; the end of input is checked by the caller.
 func isTerminatingCharacter(c byte) bool {
-	return c == ',' || c == '}' || c == ']' || string(c) == EOF.String()
+	return c == ',' || c == '}' || c == ']' || isWhitespace(c)
+}
+
+// isWhitespace checks if a byte is one of the four whitespace characters of JSON:
+// space, horizontal tab, line feed and carriage return.
+func isWhitespace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
 }
 
 // isDigit checks if a byte is a digit (0-9).
@@ -219,55 +128,25 @@ func isDigit(c byte) bool {
 	return c >= '0' && c <= '9'
 }
 
+// isLiteral checks if the input at index starts with literal and the literal is properly terminated.
+// Example: For input "true}" and literal "true", it returns true; for "trues" it returns false.
+func isLiteral(input []byte, index int, literal []byte) bool {
+	end := index + len(literal)
+	if end > len(input) || !bytes.Equal(input[index:end], literal) {
+		return false
+	}
+	return end == len(input) || isTerminatingCharacter(input[end])
+}
+
 // isBoolean checks if a substring represents a boolean value ('true' or 'false').
 // Example: For input "true", it returns true.
 func isBoolean(input []byte, index int) bool {
-	trueLiteral := []byte("true")
-	falseLiteral := []byte("false")
-
-	if len(input)-index >= len(trueLiteral) {
-
-		if !bytes.Equal(input[index:index+len(trueLiteral)], trueLiteral) {
-			return false
-		}
-
-		afterTrueLiteral := input[index+len(trueLiteral)]
-		if afterTrueLiteral == ',' || afterTrueLiteral == '}' || afterTrueLiteral == ']' {
-			return true
-		}
-	}
-
-	if len(input)-index >= len(falseLiteral) {
-
-		if !bytes.Equal(input[index:index+len(falseLiteral)], falseLiteral) {
-			return false
-		}
-
-		afterTrueLiteral := input[index+len(trueLiteral)]
-		if afterTrueLiteral == ',' || afterTrueLiteral == '}' || afterTrueLiteral == ']' {
-			return true
-		}
-	}
-
-	return false
+	return isLiteral(input, index, []byte("true")) || isLiteral(input, index, []byte("false"))
 }
 
 // isNull checks if a substring represents a null value ('null').
 func isNull(input []byte, index int) bool {
-	nullLiteral := []byte("null")
-	if len(input)-index >= len(nullLiteral) {
-
-		if !bytes.Equal(input[index:index+len(nullLiteral)], nullLiteral) {
-			return false
-		}
-
-		afterTrueLiteral := input[index+len(nullLiteral)]
-		if afterTrueLiteral == ',' || afterTrueLiteral == '}' || afterTrueLiteral == ']' {
-			return true
-		}
-	}
-
-	return false
+	return isLiteral(input, index, []byte("null"))
 }
 
 // determineTokenType returns the type of token based on the input character
@@ -288,7 +167,7 @@ func determineTokenType(char byte, input []byte, currentIndex int) Type {
 	case '"':
 		return Quote // or String, if you're immediately recognizing the string token
 	default:
-		if unicode.IsDigit(rune(char)) {
+		if isNumberStart(char) {
 			return Number
 		} else if char == 't' || char == 'f' {
 			if isBoolean(input, currentIndex) {
@@ -317,8 +196,11 @@ func (s *Stack) Push(t Type) {
 	s.TokenTypes = append(s.TokenTypes, t)
 }
 
-// Peek returns the top token type in the stack
+// Peek returns the top token type in the stack, or ILLEGAL if the stack is empty
 func (s *Stack) Peek() Type {
+	if len(s.TokenTypes) == 0 {
+		return ILLEGAL
+	}
 	return s.TokenTypes[len(s.TokenTypes)-1]
 }
 
@@ -327,30 +209,79 @@ func (s *Stack) Pop() {
 	s.TokenTypes = s.TokenTypes[:len(s.TokenTypes)-1]
 }
 
-// isValidSequences checks if the current token is a valid next token for the previous token.
-// For example, a colon (:) can only be followed by a string, number, boolean, null, left brace, or left bracket.
-// Example: For input ":", it returns true.
-func isValidSequences(prevToken, currentToken Type) bool {
-	validSequences := map[Type][]Type{
-		ILLEGAL:      {String, Number, Boolean, Null, LeftBrace, LeftBracket, Quote},
-		LeftBrace:    {String, Number, Boolean, Null, LeftBrace, LeftBracket, RightBrace, RightBracket, Quote},
-		RightBrace:   {Comma, EOF},
-		LeftBracket:  {String, Number, Boolean, Null, LeftBrace, LeftBracket, RightBracket, Quote},
-		RightBracket: {Comma, EOF},
-		Comma:        {String, Number, Boolean, Null, LeftBrace, LeftBracket, Quote},
-		Colon:        {String, Number, Boolean, Null, LeftBrace, LeftBracket, Quote},
-		String:       {Comma, RightBrace, RightBracket, Colon},
-		Number:       {Comma, RightBrace, RightBracket},
-		Boolean:      {Comma, RightBrace, RightBracket},
-		Null:         {Comma, RightBrace, RightBracket},
-		Quote:        {Comma, RightBrace, RightBracket, Colon},
-	}
+// state is the place of the Lexer in the grammar of the current object or array, which decides the token types
+// that may come next. The state of the enclosing containers follows from the stack once an object or array is closed.
+type state int
 
-	// Check if the currentToken is in the valid next tokens for prevToken.
-	if nextTokens, ok := validSequences[prevToken]; ok {
-		return ContainsInArrays(nextTokens, currentToken)
+const (
+	// stateValue expects a value: at the start of the input or after a colon
+	stateValue state = iota
+
+	// stateFirstElement expects the first element of an array or ']'
+	stateFirstElement
+
+	// stateElement expects an element after a comma in an array
+	stateElement
+
+	// stateArrayNext expects a comma or ']' after an element
+	stateArrayNext
+
+	// stateFirstKey expects the first key of an object or '}'
+	stateFirstKey
+
+	// stateKey expects a key after a comma in an object
+	stateKey
+
+	// stateColon expects the colon after a key
+	stateColon
+
+	// stateObjectNext expects a comma or '}' after a member
+	stateObjectNext
+
+	// stateEnd follows a complete top-level value; the Lexer also accepts the start of another value,
+	// so a stream may hold several values
+	stateEnd
+)
+
+// valueStart lists the token types that may start a value.
+var valueStart = []Type{String, Number, Boolean, Null, LeftBrace, LeftBracket, Quote}
+
+// validSequences maps each state to the token types that may come next.
+// Keys have the type String only in JSON5, where they may be identifiers.
+var validSequences = map[state][]Type{
+	stateValue:        valueStart,
+	stateFirstElement: append([]Type{RightBracket}, valueStart...),
+	stateElement:      valueStart,
+	stateArrayNext:    {Comma, RightBracket},
+	stateFirstKey:     {String, Quote, RightBrace},
+	stateKey:          {String, Quote},
+	stateColon:        {Colon},
+	stateObjectNext:   {Comma, RightBrace},
+	stateEnd:          {EOF},
+}
+
+// isValidSequences checks if a token of type currentToken may come next in state,
+// according to sequences, which is validSequences or json5Sequences.
+// For example, after a key only a colon (:) may follow.
+// Example: For stateColon and Colon, it returns true.
+func isValidSequences(sequences map[state][]Type, current state, currentToken Type) bool {
+	return ContainsInArrays(sequences[current], currentToken)
+}
+
+// isKey checks if a string read in state s is the key of an object member.
+func isKey(s state) bool {
+	return s == stateFirstKey || s == stateKey
+}
+
+// afterValue returns the state after a complete value, which depends on the enclosing container.
+func (l *Lexer) afterValue() state {
+	switch l.stack.Peek() {
+	case LeftBrace:
+		return stateObjectNext
+	case LeftBracket:
+		return stateArrayNext
 	}
-	return false
+	return stateEnd
 }
 
 // ContainsInArrays checks if the specified array contains the given value.
@@ -1,9 +1,12 @@
 package token
 
 import (
+	"errors"
 	"reflect"
 	"strconv"
+	"strings"
 	"testing"
+	"testing/iotest"
 )
 
 // TestTokenizer tests the Tokenizer function for various cases.
@@ -12,6 +15,7 @@ func TestTokenizer(t *testing.T) {
 		name     string
 		input    string
 		expected []Token
+		err      error
 	}{
 		{
 			name:  "Empty string",
@@ -26,15 +30,16 @@ func TestTokenizer(t *testing.T) {
 			expected: []Token{
 				{Type: LeftBrace, Val: "{"},
 				{Type: RightBrace, Val: "}"},
-				{Type: ILLEGAL, Val: "Invalid token sequence"},
+				{Type: LeftBracket, Val: "["},
+				{Type: RightBracket, Val: "]"},
+				{Type: EOF, Val: ""},
 			},
 		},
 		{
-			name:  "Comma and colon",
-			input: ",:",
-			expected: []Token{
-				{Type: ILLEGAL, Val: "Invalid token sequence"},
-			},
+			name:     "Comma and colon",
+			input:    ",:",
+			expected: []Token{},
+			err:      ErrUnexpectedToken,
 		},
 
 		{
@@ -96,8 +101,8 @@ func TestTokenizer(t *testing.T) {
 				{Type: LeftBrace, Val: "{"},
 				{Type: String, Val: "isActive"},
 				{Type: Colon, Val: ":"},
-				{Type: ILLEGAL, Val: "Invalid token sequence"},
 			},
+			err: ErrInvalidLiteral,
 		},
 
 		{
@@ -107,8 +112,8 @@ func TestTokenizer(t *testing.T) {
 				{Type: LeftBrace, Val: "{"},
 				{Type: String, Val: "isActive"},
 				{Type: Colon, Val: ":"},
-				{Type: ILLEGAL, Val: "Invalid token sequence"},
 			},
+			err: ErrInvalidLiteral,
 		},
 
 		{
@@ -131,8 +136,8 @@ func TestTokenizer(t *testing.T) {
 				{Type: LeftBrace, Val: "{"},
 				{Type: String, Val: "isActive"},
 				{Type: Colon, Val: ":"},
-				{Type: ILLEGAL, Val: "Invalid token sequence"},
 			},
+			err: ErrInvalidLiteral,
 		},
 
 		{
@@ -142,8 +147,50 @@ func TestTokenizer(t *testing.T) {
 				{Type: LeftBrace, Val: "{"},
 				{Type: String, Val: "isActive"},
 				{Type: Colon, Val: ":"},
-				{Type: ILLEGAL, Val: "Invalid token sequence"},
 			},
+			err: ErrInvalidLiteral,
+		},
+
+		{
+			name:  "Nested object and array",
+			input: `{"a":{"b":[1]}}`,
+			expected: []Token{
+				{Type: LeftBrace, Val: "{"},
+				{Type: String, Val: "a"},
+				{Type: Colon, Val: ":"},
+				{Type: LeftBrace, Val: "{"},
+				{Type: String, Val: "b"},
+				{Type: Colon, Val: ":"},
+				{Type: LeftBracket, Val: "["},
+				{Type: Number, Val: "1"},
+				{Type: RightBracket, Val: "]"},
+				{Type: RightBrace, Val: "}"},
+				{Type: RightBrace, Val: "}"},
+				{Type: EOF, Val: ""},
+			},
+		},
+
+		{
+			name:  "String literal with escaped quotes",
+			input: `{"quote": "say \"hi\""}`,
+			expected: []Token{
+				{Type: LeftBrace, Val: "{"},
+				{Type: String, Val: "quote"},
+				{Type: Colon, Val: ":"},
+				{Type: String, Val: `say "hi"`},
+				{Type: RightBrace, Val: "}"},
+				{Type: EOF, Val: ""},
+			},
+		},
+		{
+			name:  "Invalid escape sequence",
+			input: `{"a": "\q"}`,
+			expected: []Token{
+				{Type: LeftBrace, Val: "{"},
+				{Type: String, Val: "a"},
+				{Type: Colon, Val: ":"},
+			},
+			err: ErrInvalidEscape,
 		},
 
 		{
@@ -153,8 +200,8 @@ func TestTokenizer(t *testing.T) {
 				{Type: LeftBrace, Val: "{"},
 				{Type: String, Val: "name"},
 				{Type: Colon, Val: ":"},
-				{Type: ILLEGAL, Val: "Unclosed string literal"},
 			},
+			err: ErrUnclosedString,
 		},
 
 		{
@@ -167,8 +214,8 @@ func TestTokenizer(t *testing.T) {
 				{Type: String, Val: "Alice"},
 				{Type: Comma, Val: ","},
 				{Type: String, Val: "age"},
-				{Type: ILLEGAL, Val: "Unclosed token"},
 			},
+			err: ErrUnexpectedEOF,
 		},
 		{
 			name:  "Invalid Number Format",
@@ -177,8 +224,8 @@ func TestTokenizer(t *testing.T) {
 				{Type: LeftBrace, Val: "{"},
 				{Type: String, Val: "age"},
 				{Type: Colon, Val: ":"},
-				{Type: ILLEGAL, Val: "Invalid number format"},
 			},
+			err: ErrInvalidNumber,
 		},
 		{
 			name:  "Misplaced comma",
@@ -189,8 +236,8 @@ func TestTokenizer(t *testing.T) {
 				{Type: Colon, Val: ":"},
 				{Type: String, Val: "Alice"},
 				{Type: Comma, Val: ","},
-				{Type: ILLEGAL, Val: "Invalid token sequence"},
 			},
+			err: ErrUnexpectedToken,
 		},
 
 		{
@@ -200,8 +247,8 @@ func TestTokenizer(t *testing.T) {
 				{Type: LeftBrace, Val: "{"},
 				{Type: String, Val: "name"},
 				{Type: Colon, Val: ":"},
-				{Type: ILLEGAL, Val: "Invalid token sequence"},
 			},
+			err: ErrUnexpectedToken,
 		},
 		{
 			name:  "Extra Characters After Close",
@@ -212,8 +259,8 @@ func TestTokenizer(t *testing.T) {
 				{Type: Colon, Val: ":"},
 				{Type: String, Val: "Alice"},
 				{Type: RightBrace, Val: "}"},
-				{Type: ILLEGAL, Val: "Invalid token sequence"},
 			},
+			err: ErrInvalidLiteral,
 		},
 		{
 			name:  "No Colon Separator",
@@ -221,16 +268,16 @@ func TestTokenizer(t *testing.T) {
 			expected: []Token{
 				{Type: LeftBrace, Val: "{"},
 				{Type: String, Val: "name"},
-				{Type: ILLEGAL, Val: "Invalid token sequence"},
 			},
+			err: ErrUnexpectedToken,
 		},
 		{
 			name:  "Malformed structure",
 			input: `{name: "Alice", "age": 30}`,
 			expected: []Token{
 				{Type: LeftBrace, Val: "{"},
-				{Type: ILLEGAL, Val: "Invalid token sequence"},
 			},
+			err: ErrInvalidLiteral,
 		},
 		{
 			name:  "Numbers as Strings",
@@ -252,17 +299,303 @@ func TestTokenizer(t *testing.T) {
 				{Type: LeftBrace, Val: "{"},
 				{Type: String, Val: "age"},
 				{Type: Colon, Val: ":"},
-				{Type: ILLEGAL, Val: "Invalid number format"},
+				{Type: Number, Val: "30"},
 			},
+			err: ErrInvalidLiteral,
+		},
+		{
+			name:     "Top-level string",
+			input:    `"hello"`,
+			expected: []Token{{Type: String, Val: "hello"}, {Type: EOF, Val: ""}},
+		},
+		{
+			name:     "Top-level number",
+			input:    `42`,
+			expected: []Token{{Type: Number, Val: "42"}, {Type: EOF, Val: ""}},
+		},
+		{
+			name:     "Top-level true",
+			input:    `true`,
+			expected: []Token{{Type: Boolean, Val: "true"}, {Type: EOF, Val: ""}},
+		},
+		{
+			name:     "Top-level false with whitespace",
+			input:    " false\n",
+			expected: []Token{{Type: Boolean, Val: "false"}, {Type: EOF, Val: ""}},
+		},
+		{
+			name:     "Top-level null",
+			input:    `null`,
+			expected: []Token{{Type: Null, Val: "null"}, {Type: EOF, Val: ""}},
+		},
+		{
+			name:  "Top-level array with whitespace",
+			input: `[ 1 , false ]`,
+			expected: []Token{
+				{Type: LeftBracket, Val: "["},
+				{Type: Number, Val: "1"},
+				{Type: Comma, Val: ","},
+				{Type: Boolean, Val: "false"},
+				{Type: RightBracket, Val: "]"},
+				{Type: EOF, Val: ""},
+			},
+		},
+		{
+			name:  "Closing bracket after top-level value",
+			input: `{}]`,
+			expected: []Token{
+				{Type: LeftBrace, Val: "{"},
+				{Type: RightBrace, Val: "}"},
+			},
+			err: ErrUnexpectedToken,
+		},
+		{
+			name:  "Colon after top-level string",
+			input: `"a":1`,
+			expected: []Token{
+				{Type: String, Val: "a"},
+			},
+			err: ErrUnexpectedToken,
+		},
+		{
+			name:  "Brace closing an array",
+			input: `[1}]`,
+			expected: []Token{
+				{Type: LeftBracket, Val: "["},
+				{Type: Number, Val: "1"},
+			},
+			err: ErrUnexpectedToken,
+		},
+		{
+			name:  "Colon in an array",
+			input: `["a":1]`,
+			expected: []Token{
+				{Type: LeftBracket, Val: "["},
+				{Type: String, Val: "a"},
+			},
+			err: ErrUnexpectedToken,
+		},
+		{
+			name:  "Comma after a key",
+			input: `{"a","b"}`,
+			expected: []Token{
+				{Type: LeftBrace, Val: "{"},
+				{Type: String, Val: "a"},
+			},
+			err: ErrUnexpectedToken,
+		},
+		{
+			name:  "Key without a value",
+			input: `{"a"}`,
+			expected: []Token{
+				{Type: LeftBrace, Val: "{"},
+				{Type: String, Val: "a"},
+			},
+			err: ErrUnexpectedToken,
 		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			result := Tokenizer([]byte(tc.input))
-			if !reflect.DeepEqual(result, tc.expected) {
+			result, err := Tokenizer([]byte(tc.input))
+			if !errors.Is(err, tc.err) {
+				t.Errorf("Test %s failed. Expected error %v, got %v", tc.name, tc.err, err)
+			}
+			if result = withoutPositions(result); !reflect.DeepEqual(result, tc.expected) {
 				t.Errorf("Test %s failed. Expected %#v\n, got %#v'\n", tc.name, tc.expected, result)
 			}
 		})
 	}
 }
+
+// TestTokenizerPositions tests the start and end positions of tokens across lines.
+func TestTokenizerPositions(t *testing.T) {
+	input := "{\n  \"name\": \"John\",\n  \"age\": -12\n}"
+	expected := []Token{
+		{Type: LeftBrace, Val: "{", Start: Position{Offset: 0, Line: 1, Column: 1}, End: Position{Offset: 1, Line: 1, Column: 2}},
+		{Type: String, Val: "name", Start: Position{Offset: 4, Line: 2, Column: 3}, End: Position{Offset: 10, Line: 2, Column: 9}},
+		{Type: Colon, Val: ":", Start: Position{Offset: 10, Line: 2, Column: 9}, End: Position{Offset: 11, Line: 2, Column: 10}},
+		{Type: String, Val: "John", Start: Position{Offset: 12, Line: 2, Column: 11}, End: Position{Offset: 18, Line: 2, Column: 17}},
+		{Type: Comma, Val: ",", Start: Position{Offset: 18, Line: 2, Column: 17}, End: Position{Offset: 19, Line: 2, Column: 18}},
+		{Type: String, Val: "age", Start: Position{Offset: 22, Line: 3, Column: 3}, End: Position{Offset: 27, Line: 3, Column: 8}},
+		{Type: Colon, Val: ":", Start: Position{Offset: 27, Line: 3, Column: 8}, End: Position{Offset: 28, Line: 3, Column: 9}},
+		{Type: Number, Val: "-12", Start: Position{Offset: 29, Line: 3, Column: 10}, End: Position{Offset: 32, Line: 3, Column: 13}},
+		{Type: RightBrace, Val: "}", Start: Position{Offset: 33, Line: 4, Column: 1}, End: Position{Offset: 34, Line: 4, Column: 2}},
+		{Type: EOF, Val: "", Start: Position{Offset: 34, Line: 4, Column: 2}, End: Position{Offset: 34, Line: 4, Column: 2}},
+	}
+
+	result, err := Tokenizer([]byte(input))
+	if err != nil {
+		t.Fatalf("Tokenizer() error = %v", err)
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %#v\n, got %#v\n", expected, result)
+	}
+}
+
+// TestTokenizerSyntaxError tests the fields and message of the SyntaxError returned for malformed input.
+func TestTokenizerSyntaxError(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected SyntaxError
+		message  string
+	}{
+		{
+			name:     "Misplaced comma",
+			input:    "{\n  \"a\": 1,,\n}",
+			expected: SyntaxError{Kind: ErrUnexpectedToken, Pos: Position{Offset: 11, Line: 2, Column: 10}, Found: ",", Expected: "string"},
+			message:  `2:10: unexpected token ",", expected string`,
+		},
+		{
+			name:     "Invalid literal",
+			input:    `[trues]`,
+			expected: SyntaxError{Kind: ErrInvalidLiteral, Pos: Position{Offset: 1, Line: 1, Column: 2}, Found: "trues"},
+			message:  `1:2: invalid literal "trues"`,
+		},
+		{
+			name:     "Invalid character",
+			input:    `[1, @]`,
+			expected: SyntaxError{Kind: ErrInvalidCharacter, Pos: Position{Offset: 4, Line: 1, Column: 5}, Found: "@"},
+			message:  `1:5: invalid character "@"`,
+		},
+		{
+			name:     "Unclosed string points at the opening quote",
+			input:    `{"a": "bc`,
+			expected: SyntaxError{Kind: ErrUnclosedString, Pos: Position{Offset: 6, Line: 1, Column: 7}, Expected: `"`},
+			message:  `1:7: unclosed string literal, expected "`,
+		},
+		{
+			name:     "Invalid unicode escape",
+			input:    `"\u12zz"`,
+			expected: SyntaxError{Kind: ErrInvalidUnicode, Pos: Position{Offset: 2, Line: 1, Column: 3}, Found: `\u12zz`},
+			message:  `1:3: invalid unicode escape "\\u12zz"`,
+		},
+		{
+			name:     "Invalid UTF-8",
+			input:    "[\"ok\",\n \"a\xffb\"]",
+			expected: SyntaxError{Kind: ErrInvalidUTF8, Pos: Position{Offset: 10, Line: 2, Column: 4}, Found: "\xff"},
+			message:  `2:4: invalid UTF-8 in string literal "\xff"`,
+		},
+		{
+			name:     "Invalid number",
+			input:    `[01]`,
+			expected: SyntaxError{Kind: ErrInvalidNumber, Pos: Position{Offset: 1, Line: 1, Column: 2}, Found: "01"},
+			message:  `1:2: invalid number "01"`,
+		},
+		{
+			name:     "Number as a key",
+			input:    `{-1:2}`,
+			expected: SyntaxError{Kind: ErrUnexpectedToken, Pos: Position{Offset: 1, Line: 1, Column: 2}, Found: "-1", Expected: "string or }"},
+			message:  `1:2: unexpected token "-1", expected string or }`,
+		},
+		{
+			name:     "Number without fraction digits",
+			input:    `1.`,
+			expected: SyntaxError{Kind: ErrInvalidNumber, Pos: Position{Offset: 0, Line: 1, Column: 1}, Found: "1."},
+			message:  `1:1: invalid number "1."`,
+		},
+		{
+			name:     "Missing colon after a key",
+			input:    `{"a" "b"}`,
+			expected: SyntaxError{Kind: ErrUnexpectedToken, Pos: Position{Offset: 5, Line: 1, Column: 6}, Found: `"`, Expected: ":"},
+			message:  `1:6: unexpected token "\"", expected :`,
+		},
+		{
+			name:     "Closing bracket after the top-level value",
+			input:    `[1]]`,
+			expected: SyntaxError{Kind: ErrUnexpectedToken, Pos: Position{Offset: 3, Line: 1, Column: 4}, Found: "]", Expected: "end of input"},
+			message:  `1:4: unexpected token "]", expected end of input`,
+		},
+		{
+			name:     "Unexpected end of input",
+			input:    `[{"a": 1}`,
+			expected: SyntaxError{Kind: ErrUnexpectedEOF, Pos: Position{Offset: 9, Line: 1, Column: 10}, Expected: "]"},
+			message:  `1:10: unexpected end of input, expected ]`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Tokenizer([]byte(tc.input))
+			var syntaxErr *SyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("Expected *SyntaxError, got %#v", err)
+			}
+			tc.expected.Msg = syntaxErr.Msg
+			if !reflect.DeepEqual(*syntaxErr, tc.expected) {
+				t.Errorf("Expected %#v\n, got %#v", tc.expected, *syntaxErr)
+			}
+			if err.Error() != tc.message {
+				t.Errorf("Expected message %q, got %q", tc.message, err.Error())
+			}
+			if !errors.Is(err, tc.expected.Kind) {
+				t.Errorf("Expected errors.Is(err, %v)", tc.expected.Kind)
+			}
+		})
+	}
+}
+
+// withoutPositions returns a copy of tokens with Start and End cleared, so tests can focus on types and values.
+func withoutPositions(tokens []Token) []Token {
+	result := make([]Token, len(tokens))
+	for i, tk := range tokens {
+		result[i] = Token{Type: tk.Type, Val: tk.Val}
+	}
+	return result
+}
+
+// TestTokenizerWhitespace tests that only the four JSON whitespace characters separate tokens in strict mode.
+func TestTokenizerWhitespace(t *testing.T) {
+	if _, err := Tokenizer([]byte(" \t\r\n[1,\r\n2]\t ")); err != nil {
+		t.Errorf("Tokenizer() error = %v", err)
+	}
+	for _, input := range []string{"[1,\v2]", "[1\f]", "[1,\u00a02]", "[1\u00a0]", "\u2028[]", "[1,\x852]"} {
+		if _, err := Tokenizer([]byte(input)); !errors.Is(err, ErrInvalidCharacter) && !errors.Is(err, ErrInvalidNumber) {
+			t.Errorf("Input %q: expected an invalid character or number, got %v", input, err)
+		}
+	}
+}
+
+// TestTokenizerBOM tests that a UTF-8 byte order mark is skipped at the start of the input only.
+func TestTokenizerBOM(t *testing.T) {
+	result, err := Tokenizer([]byte("\xef\xbb\xbf{\"a\": 1}"))
+	if err != nil {
+		t.Fatalf("Tokenizer() error = %v", err)
+	}
+	if start := result[0].Start; start != (Position{Offset: 3, Line: 1, Column: 1}) {
+		t.Errorf("Expected the first token at offset 3, column 1, got %#v", start)
+	}
+
+	lexer := NewLexer(iotest.OneByteReader(strings.NewReader("\xef\xbb\xbf[]")))
+	if tk, err := lexer.Next(); err != nil || tk.Type != LeftBracket {
+		t.Errorf("Next() got = %v, %v, want [", tk, err)
+	}
+
+	if _, err := Tokenizer([]byte("[\xef\xbb\xbf]")); !errors.Is(err, ErrInvalidCharacter) {
+		t.Errorf("Expected ErrInvalidCharacter for a byte order mark after the start, got %v", err)
+	}
+}
+
+// TestTokenizerReplaceInvalidUTF8 tests that ReplaceInvalidUTF8 reaches both string readers, also when streaming.
+func TestTokenizerReplaceInvalidUTF8(t *testing.T) {
+	input := "[\"a\xff\", \"\xe2\x82\xac\"]"
+	expected := []string{"a\uFFFD", "€"}
+	for _, opts := range [][]Option{{ReplaceInvalidUTF8()}, {ReplaceInvalidUTF8(), JSON5()}} {
+		result, err := Tokenizer([]byte(input), opts...)
+		if err != nil {
+			t.Fatalf("Tokenizer() error = %v", err)
+		}
+		if result[1].Val != expected[0] || result[3].Val != expected[1] {
+			t.Errorf("Expected %q and %q, got %q and %q", expected[0], expected[1], result[1].Val, result[3].Val)
+		}
+	}
+
+	lexer := NewLexer(iotest.OneByteReader(strings.NewReader(input)), ReplaceInvalidUTF8())
+	lexer.Next()
+	lexer.Next()
+	lexer.Next()
+	if tk, err := lexer.Next(); err != nil || tk.Val != "€" {
+		t.Errorf("Next() got = %q, %v, want a character split across reads to be kept", tk.Val, err)
+	}
+}
@@ -0,0 +1,367 @@
+package gojsonp
+
+import (
+	"fmt"
+	"github.com/onerciller/gojsonp/parser"
+	"github.com/onerciller/gojsonp/token"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// numberType, bigIntType and bigFloatType are the number types read and written as literal text.
+var (
+	numberType   = reflect.TypeOf(Number(""))
+	bigIntType   = reflect.TypeOf(big.Int{})
+	bigFloatType = reflect.TypeOf(big.Float{})
+)
+
+// UnmarshalTypeError describes a JSON value that cannot be stored in the Go value it was decoded into.
+type UnmarshalTypeError struct {
+
+	// Value describes the JSON value, e.g. "string" or "number 1.5"
+	Value string
+
+	// Type is the Go type the value could not be assigned to
+	Type reflect.Type
+
+	// Path is the location of the value in the document, e.g. "$.items[0].price"
+	Path string
+
+	// Pos is the position of the value in the input
+	Pos token.Position
+}
+
+// Error method for UnmarshalTypeError to get a human-readable description of the error.
+func (e *UnmarshalTypeError) Error() string {
+	return fmt.Sprintf("%s: cannot unmarshal %s into Go value of type %s at %s", e.Pos, e.Value, e.Type, e.Path)
+}
+
+// InvalidUnmarshalError describes an invalid argument passed to Unmarshal: it must be a non-nil pointer.
+type InvalidUnmarshalError struct {
+	Type reflect.Type
+}
+
+// Error method for InvalidUnmarshalError to get a human-readable description of the error.
+func (e *InvalidUnmarshalError) Error() string {
+	if e.Type == nil {
+		return "gojsonp: Unmarshal(nil)"
+	}
+	if e.Type.Kind() != reflect.Ptr {
+		return "gojsonp: Unmarshal(non-pointer " + e.Type.String() + ")"
+	}
+	return "gojsonp: Unmarshal(nil " + e.Type.String() + ")"
+}
+
+// Unmarshal function to parse JSON data and store the result in the value pointed to by v.
+// Objects are stored in structs or maps, arrays in slices or arrays, and pointers are allocated as needed.
+// Struct fields are matched by their `json:"name"` tag or name, first exactly and then case-insensitively;
+// fields of embedded structs are promoted, and the ",string" option reads numbers and booleans from strings.
+// Numbers are parsed from their literal text, so int64 and uint64 fields get large integers exactly;
+// Number, big.Int and big.Float fields keep every digit, while interface{} values get a float64.
+// Example:
+//
+//	var user struct {
+//		Name string `json:"name"`
+//	}
+//	err := Unmarshal([]byte(`{"name": "John"}`), &user)
+func Unmarshal(data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return &InvalidUnmarshalError{Type: reflect.TypeOf(v)}
+	}
+
+	tokens, err := token.Tokenizer(data)
+	if err != nil {
+		return err
+	}
+	// numbers are kept as written, so integers beyond 2^53 reach int64 and uint64 fields intact
+	node, err := parser.Parse(tokens, parser.Numbers(parser.NumberString))
+	if err != nil {
+		return err
+	}
+	return unmarshalNode(node, rv, "$")
+}
+
+// unmarshalNode stores node in v; path is the location of node used in error messages.
+func unmarshalNode(node *parser.AstNode, v reflect.Value, path string) error {
+	// null only clears pointers, maps, slices and interfaces, like a missing value
+	if node.Type == token.Null {
+		switch v.Kind() {
+		case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+			v.Set(reflect.Zero(v.Type()))
+		}
+		return nil
+	}
+
+	// allocate pointers on the way to the value
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return unmarshalNode(node, v.Elem(), path)
+	}
+
+	if v.Kind() == reflect.Interface {
+		if v.NumMethod() != 0 {
+			return typeError(node, v.Type(), path)
+		}
+		v.Set(reflect.ValueOf(parser.NodeToValue(node)))
+		return nil
+	}
+
+	switch node.Type {
+	case token.Object:
+		return unmarshalObject(node, v, path)
+	case token.Array:
+		return unmarshalArray(node, v, path)
+	case token.String:
+		if v.Kind() != reflect.String {
+			return typeError(node, v.Type(), path)
+		}
+		v.SetString(node.Value.(string))
+	case token.Boolean:
+		if v.Kind() != reflect.Bool {
+			return typeError(node, v.Type(), path)
+		}
+		v.SetBool(node.Value.(bool))
+	case token.Number:
+		return unmarshalNumber(node, v, path)
+	default:
+		return typeError(node, v.Type(), path)
+	}
+	return nil
+}
+
+// unmarshalObject stores an Object node in a struct or a map.
+func unmarshalObject(node *parser.AstNode, v reflect.Value, path string) error {
+	members := node.Value.(*parser.Object).Members()
+
+	switch v.Kind() {
+	case reflect.Struct:
+		fields := cachedFields(v.Type())
+		for _, member := range members {
+			f, ok := findField(fields, member.Key)
+			if !ok {
+				continue
+			}
+			fv, err := fieldByIndex(v, f.index)
+			if err != nil {
+				return err
+			}
+			memberPath := path + "." + member.Key
+			if f.asString {
+				err = unmarshalQuoted(member.Value, fv, memberPath)
+			} else {
+				err = unmarshalNode(member.Value, fv, memberPath)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Map:
+		t := v.Type()
+		if !isMapKeyKind(t.Key().Kind()) {
+			return typeError(node, t, path)
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMapWithSize(t, len(members)))
+		}
+		for _, member := range members {
+			key, err := mapKey(member.Key, t.Key())
+			if err != nil {
+				return &UnmarshalTypeError{Value: "object key " + strconv.Quote(member.Key), Type: t.Key(), Path: path, Pos: member.KeyStart}
+			}
+			elem := reflect.New(t.Elem()).Elem()
+			if err := unmarshalNode(member.Value, elem, path+"."+member.Key); err != nil {
+				return err
+			}
+			v.SetMapIndex(key, elem)
+		}
+		return nil
+	default:
+		return typeError(node, v.Type(), path)
+	}
+}
+
+// unmarshalArray stores an Array node in a slice or an array.
+// Elements of a Go array beyond the length of the JSON array are zeroed, extra JSON elements are dropped.
+func unmarshalArray(node *parser.AstNode, v reflect.Value, path string) error {
+	elements := node.Value.([]*parser.AstNode)
+
+	switch v.Kind() {
+	case reflect.Slice:
+		slice := reflect.MakeSlice(v.Type(), len(elements), len(elements))
+		for i, element := range elements {
+			if err := unmarshalNode(element, slice.Index(i), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+		return nil
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if i >= len(elements) {
+				v.Index(i).Set(reflect.Zero(v.Type().Elem()))
+				continue
+			}
+			if err := unmarshalNode(elements[i], v.Index(i), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return typeError(node, v.Type(), path)
+	}
+}
+
+// unmarshalNumber stores a Number node in an integer, floating-point, Number or big number value,
+// checking for overflow. The literal text of the number is parsed directly, so no precision is lost.
+func unmarshalNumber(node *parser.AstNode, v reflect.Value, path string) error {
+	literal := string(node.Value.(parser.Number))
+
+	switch v.Type() {
+	case numberType:
+		v.SetString(literal)
+		return nil
+	case bigIntType, bigFloatType:
+		// NumberBig gives a *big.Int for integer literals and a *big.Float for the others
+		switch number := parser.NodeToValue(node, parser.Numbers(parser.NumberBig)).(type) {
+		case *big.Int:
+			if v.Type() == bigIntType {
+				v.Set(reflect.ValueOf(number).Elem())
+			} else {
+				v.Set(reflect.ValueOf(new(big.Float).SetInt(number)).Elem())
+			}
+			return nil
+		case *big.Float:
+			if v.Type() == bigFloatType {
+				v.Set(reflect.ValueOf(number).Elem())
+				return nil
+			}
+		}
+		return typeError(node, v.Type(), path)
+	}
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(literal, 10, 64)
+		if err != nil || v.OverflowInt(n) {
+			return typeError(node, v.Type(), path)
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(literal, 10, 64)
+		if err != nil || v.OverflowUint(n) {
+			return typeError(node, v.Type(), path)
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(literal, v.Type().Bits())
+		if err != nil {
+			return typeError(node, v.Type(), path)
+		}
+		v.SetFloat(f)
+	default:
+		return typeError(node, v.Type(), path)
+	}
+	return nil
+}
+
+// unmarshalQuoted stores a field with the ",string" tag option: the JSON value is a string
+// holding the encoded scalar, e.g. "42" for an int or "\"text\"" for a string.
+func unmarshalQuoted(node *parser.AstNode, v reflect.Value, path string) error {
+	if node.Type == token.Null {
+		return unmarshalNode(node, v, path)
+	}
+	if node.Type != token.String {
+		return typeError(node, v.Type(), path)
+	}
+	tokens, err := token.Tokenizer([]byte(node.Value.(string)))
+	if err != nil {
+		return typeError(node, v.Type(), path)
+	}
+	inner, err := parser.Parse(tokens, parser.Numbers(parser.NumberString))
+	if err != nil || inner.Type == token.Object || inner.Type == token.Array {
+		return typeError(node, v.Type(), path)
+	}
+	inner.Start, inner.End = node.Start, node.End
+	return unmarshalNode(inner, v, path)
+}
+
+// findField returns the field for key, preferring an exact match over a case-insensitive one.
+func findField(fields []field, key string) (field, bool) {
+	for _, f := range fields {
+		if f.name == key {
+			return f, true
+		}
+	}
+	for _, f := range fields {
+		if strings.EqualFold(f.name, key) {
+			return f, true
+		}
+	}
+	return field{}, false
+}
+
+// fieldByIndex returns the struct field at index, allocating nil embedded struct pointers on the way.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, error) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return v, fmt.Errorf("gojsonp: cannot set embedded pointer to unexported struct: %v", v.Type().Elem())
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, nil
+}
+
+// isMapKeyKind reports whether map keys of kind k can be decoded from object keys.
+func isMapKeyKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
+}
+
+// mapKey converts an object key to a map key of type t.
+// Example: For key "42" and type int, it returns 42.
+func mapKey(key string, t reflect.Type) (reflect.Value, error) {
+	kv := reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.String:
+		kv.SetString(key)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(key, 10, 64)
+		if err != nil || kv.OverflowInt(n) {
+			return kv, strconv.ErrSyntax
+		}
+		kv.SetInt(n)
+	default:
+		n, err := strconv.ParseUint(key, 10, 64)
+		if err != nil || kv.OverflowUint(n) {
+			return kv, strconv.ErrSyntax
+		}
+		kv.SetUint(n)
+	}
+	return kv, nil
+}
+
+// typeError creates an UnmarshalTypeError for node.
+func typeError(node *parser.AstNode, t reflect.Type, path string) error {
+	value := strings.ToLower(node.Type.String())
+	if node.Type == token.Number {
+		value += " " + fmt.Sprint(node.Value)
+	}
+	return &UnmarshalTypeError{Value: value, Type: t, Path: path, Pos: node.Start}
+}
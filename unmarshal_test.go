@@ -0,0 +1,240 @@
+package gojsonp
+
+import (
+	"errors"
+	"github.com/onerciller/gojsonp/token"
+	"math"
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+type unmarshalAddress struct {
+	Street string `json:"street"`
+	Zip    *int   `json:"zip,omitempty"`
+}
+
+type unmarshalBase struct {
+	ID      int64 `json:"id,string"`
+	Created string
+}
+
+type unmarshalUser struct {
+	unmarshalBase
+	Name     string                 `json:"name"`
+	Age      uint8                  `json:"age"`
+	Score    float64                `json:"score"`
+	Active   bool                   `json:"active,string"`
+	Tags     []string               `json:"tags"`
+	Pair     [2]int                 `json:"pair"`
+	Address  *unmarshalAddress      `json:"address"`
+	Labels   map[string]int         `json:"labels"`
+	ByID     map[int]string         `json:"by_id"`
+	Extra    interface{}            `json:"extra"`
+	Meta     map[string]interface{} `json:"meta"`
+	Ignored  string                 `json:"-"`
+	internal string
+}
+
+// TestUnmarshal tests the Unmarshal function for structs, tags, embedded structs and collections.
+func TestUnmarshal(t *testing.T) {
+	input := `{
+		"id": "4242",
+		"created": "today",
+		"NAME": "John",
+		"age": 42,
+		"score": 9.5,
+		"active": "true",
+		"tags": ["a", "b"],
+		"pair": [1],
+		"address": {"street": "Main", "zip": 12345},
+		"labels": {"x": 1, "y": 2},
+		"by_id": {"7": "seven"},
+		"extra": [1, "two", null],
+		"meta": null,
+		"Ignored": "no",
+		"internal": "no",
+		"unknown": {"deep": [1, 2, 3]}
+	}`
+
+	zip := 12345
+	want := unmarshalUser{
+		unmarshalBase: unmarshalBase{ID: 4242, Created: "today"},
+		Name:          "John",
+		Age:           42,
+		Score:         9.5,
+		Active:        true,
+		Tags:          []string{"a", "b"},
+		Pair:          [2]int{1, 0},
+		Address:       &unmarshalAddress{Street: "Main", Zip: &zip},
+		Labels:        map[string]int{"x": 1, "y": 2},
+		ByID:          map[int]string{7: "seven"},
+		Extra:         []interface{}{float64(1), "two", nil},
+	}
+
+	got := unmarshalUser{Pair: [2]int{5, 5}, Meta: map[string]interface{}{"old": true}}
+	if err := Unmarshal([]byte(input), &got); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmarshal() got = %+v, want %+v", got, want)
+	}
+}
+
+// TestUnmarshalValues tests the Unmarshal function for top-level values of basic types.
+func TestUnmarshalValues(t *testing.T) {
+	var s string
+	var n int
+	var f float32
+	var b bool
+	var p *int
+	var i interface{}
+	var list []map[string]bool
+	tests := []struct {
+		input string
+		ptr   interface{}
+		want  interface{}
+	}{
+		{input: `"hi"`, ptr: &s, want: "hi"},
+		{input: `-12`, ptr: &n, want: -12},
+		{input: `1.5`, ptr: &f, want: float32(1.5)},
+		{input: `true`, ptr: &b, want: true},
+		{input: `7`, ptr: &p, want: intPtr(7)},
+		{input: `{"a": [true]}`, ptr: &i, want: map[string]interface{}{"a": []interface{}{true}}},
+		{input: `[{"x": true}, {}]`, ptr: &list, want: []map[string]bool{{"x": true}, {}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if err := Unmarshal([]byte(tt.input), tt.ptr); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if got := reflect.ValueOf(tt.ptr).Elem().Interface(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Unmarshal() got = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestUnmarshalTypeError tests that type mismatches are reported with the JSON path and position of the value.
+func TestUnmarshalTypeError(t *testing.T) {
+	type item struct {
+		Price int `json:"price"`
+	}
+	type order struct {
+		Items []item `json:"items"`
+	}
+	tests := []struct {
+		name  string
+		input string
+		ptr   interface{}
+		want  UnmarshalTypeError
+	}{
+		{
+			name:  "String into int",
+			input: `{"items": [{"price": 1}, {"price": "2"}]}`,
+			ptr:   &order{},
+			want:  UnmarshalTypeError{Value: "string", Type: reflect.TypeOf(0), Path: "$.items[1].price", Pos: token.Position{Offset: 35, Line: 1, Column: 36}},
+		},
+		{
+			name:  "Fraction into int",
+			input: `{"items": [{"price": 1.5}]}`,
+			ptr:   &order{},
+			want:  UnmarshalTypeError{Value: "number 1.5", Type: reflect.TypeOf(0), Path: "$.items[0].price", Pos: token.Position{Offset: 21, Line: 1, Column: 22}},
+		},
+		{
+			name:  "Overflow",
+			input: `[300]`,
+			ptr:   &[]uint8{},
+			want:  UnmarshalTypeError{Value: "number 300", Type: reflect.TypeOf(uint8(0)), Path: "$[0]", Pos: token.Position{Offset: 1, Line: 1, Column: 2}},
+		},
+		{
+			name:  "Array into struct",
+			input: `[]`,
+			ptr:   &order{},
+			want:  UnmarshalTypeError{Value: "array", Type: reflect.TypeOf(order{}), Path: "$", Pos: token.Position{Offset: 0, Line: 1, Column: 1}},
+		},
+		{
+			name:  "Invalid map key",
+			input: `{"x": "y"}`,
+			ptr:   &map[int]string{},
+			want:  UnmarshalTypeError{Value: `object key "x"`, Type: reflect.TypeOf(0), Path: "$", Pos: token.Position{Offset: 1, Line: 1, Column: 2}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Unmarshal([]byte(tt.input), tt.ptr)
+			var typeErr *UnmarshalTypeError
+			if !errors.As(err, &typeErr) {
+				t.Fatalf("Unmarshal() error = %v, want *UnmarshalTypeError", err)
+			}
+			if !reflect.DeepEqual(*typeErr, tt.want) {
+				t.Errorf("Unmarshal() error = %+v, want %+v", *typeErr, tt.want)
+			}
+		})
+	}
+}
+
+// TestUnmarshalInvalidArgument tests that Unmarshal rejects arguments it cannot store into.
+func TestUnmarshalInvalidArgument(t *testing.T) {
+	var nilPtr *int
+	for _, v := range []interface{}{nil, 1, nilPtr} {
+		var invalidErr *InvalidUnmarshalError
+		if err := Unmarshal([]byte(`1`), v); !errors.As(err, &invalidErr) {
+			t.Errorf("Unmarshal(%#v) error = %v, want *InvalidUnmarshalError", v, err)
+		}
+	}
+
+	var n int
+	if err := Unmarshal([]byte(`[1`), &n); !errors.Is(err, token.ErrUnexpectedEOF) {
+		t.Errorf("Unmarshal() error = %v, want token.ErrUnexpectedEOF", err)
+	}
+}
+
+// TestUnmarshalNumbers tests that numbers are stored from their literal text without losing precision.
+func TestUnmarshalNumbers(t *testing.T) {
+	var target struct {
+		ID     int64       `json:"id"`
+		Count  uint64      `json:"count"`
+		Amount Number      `json:"amount"`
+		Big    *big.Int    `json:"big"`
+		Ratio  big.Float   `json:"ratio"`
+		Any    interface{} `json:"any"`
+	}
+	input := `{"id": 9007199254740993, "count": 18446744073709551615, "amount": 1.10,
+		"big": 123456789012345678901234567890, "ratio": 0.5, "any": 12345678901234567890}`
+	if err := Unmarshal([]byte(input), &target); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	if target.ID != 9007199254740993 {
+		t.Errorf("ID = %d, want 9007199254740993", target.ID)
+	}
+	if target.Count != math.MaxUint64 {
+		t.Errorf("Count = %d, want %d", target.Count, uint64(math.MaxUint64))
+	}
+	if target.Amount != "1.10" {
+		t.Errorf("Amount = %s, want 1.10", target.Amount)
+	}
+	if target.Big == nil || target.Big.String() != "123456789012345678901234567890" {
+		t.Errorf("Big = %v, want 123456789012345678901234567890", target.Big)
+	}
+	if f, _ := target.Ratio.Float64(); f != 0.5 {
+		t.Errorf("Ratio = %v, want 0.5", f)
+	}
+	if target.Any != float64(12345678901234567890) {
+		t.Errorf("Any = %#v, want float64", target.Any)
+	}
+
+	var n int64
+	var typeErr *UnmarshalTypeError
+	if err := Unmarshal([]byte(`9223372036854775808`), &n); !errors.As(err, &typeErr) || typeErr.Value != "number 9223372036854775808" {
+		t.Errorf("Unmarshal() error = %v, want an UnmarshalTypeError for the overflow", err)
+	}
+}
+
+// intPtr returns a pointer to n.
+func intPtr(n int) *int {
+	return &n
+}